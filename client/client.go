@@ -0,0 +1,1560 @@
+// Code generated by openapigen from the OpenAPI document. DO NOT EDIT.
+
+// Package client is a typed client for the Rugby Live API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client calls the API at BaseURL. AdminToken is sent as a bearer token,
+// which admin operations require.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	AdminToken string
+}
+
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), HTTPClient: http.DefaultClient}
+}
+
+// APIError is returned for non-2xx responses.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	respBody, err := c.stream(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer respBody.Close()
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(respBody).Decode(out)
+}
+
+// stream sends a request and returns the response body of a 2xx response.
+func (c *Client) stream(ctx context.Context, method, path string, query url.Values, body interface{}) (io.ReadCloser, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.AdminToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.AdminToken)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		var e ErrorResponse
+		data, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(data, &e) != nil || e.Error == "" {
+			e.Error = strings.TrimSpace(string(data))
+		}
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: e.Error}
+	}
+	return resp.Body, nil
+}
+
+type APILeague struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type APIMapping struct {
+	APIID      string    `json:"api_id"`
+	APIName    string    `json:"api_name"`
+	CreatedAt  time.Time `json:"created_at"`
+	EntityID   string    `json:"entity_id"`
+	EntityType string    `json:"entity_type"`
+	ID         int       `json:"id"`
+	IsActive   bool      `json:"is_active"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type APISportsParams struct {
+	Date     string `json:"date"`
+	LeagueID string `json:"league_id"`
+	Season   string `json:"season"`
+}
+
+type CompetitionGroup struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type CompetitionMapping struct {
+	APIMappings      []*APIMapping            `json:"api_mappings,omitempty"`
+	CompetitionGroup RapidapiCompetitionGroup `json:"competition_group"`
+	Error            string                   `json:"error,omitempty"`
+	League           *League                  `json:"league,omitempty"`
+	Matched          bool                     `json:"matched"`
+	Reason           string                   `json:"reason"`
+}
+
+type CompetitionMappingResponse struct {
+	Matched   []CompetitionMapping `json:"matched"`
+	Report    *IngestionReport     `json:"report,omitempty"`
+	Stats     MappingStats         `json:"stats"`
+	Unmatched []CompetitionMapping `json:"unmatched"`
+}
+
+type Conflict struct {
+	Match    Match          `json:"match"`
+	Override *ScoreOverride `json:"override,omitempty"`
+	Reports  []SourceReport `json:"reports"`
+}
+
+type ConflictsResponse struct {
+	Conflicts []Conflict `json:"conflicts"`
+}
+
+type CountriesRefreshResponse struct {
+	Changes      []CountryChange `json:"changes"`
+	Message      string          `json:"message"`
+	TotalChanges int             `json:"total_changes"`
+}
+
+type Country struct {
+	Code       string    `json:"code"`
+	CreatedAt  time.Time `json:"created_at"`
+	Flag       string    `json:"flag"`
+	FlagSource string    `json:"flag_source"`
+	Name       string    `json:"name"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type CountryChange struct {
+	Changes map[string]json.RawMessage `json:"Changes"`
+	Code    string                     `json:"Code"`
+	IsNew   bool                       `json:"IsNew"`
+	Name    string                     `json:"Name"`
+}
+
+type DailyMatches struct {
+	Date     string   `json:"date"`
+	MatchIDs []string `json:"match_ids"`
+}
+
+type ESPNLeague struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type ExportResponse struct {
+	Files []Result `json:"files"`
+}
+
+type FailedTeam struct {
+	CountryID   int             `json:"CountryID"`
+	CountryName string          `json:"CountryName"`
+	Name        string          `json:"Name"`
+	Reason      string          `json:"Reason"`
+	TeamData    json.RawMessage `json:"TeamData"`
+}
+
+type Fixture struct {
+	Away      string `json:"away"`
+	AwayID    int    `json:"away_id"`
+	AwayScore *int   `json:"away_score,omitempty"`
+	CompID    int    `json:"comp_id"`
+	Date      string `json:"date"`
+	Home      string `json:"home"`
+	HomeID    int    `json:"home_id"`
+	HomeScore *int   `json:"home_score,omitempty"`
+	ID        int    `json:"id"`
+	Status    string `json:"status"`
+	Venue     string `json:"venue"`
+}
+
+type GraphQLLocation struct {
+	Column int `json:"column"`
+	Line   int `json:"line"`
+}
+
+type GraphQLQueryError struct {
+	Extensions map[string]json.RawMessage `json:"extensions,omitempty"`
+	Locations  []GraphQLLocation          `json:"locations,omitempty"`
+	Message    string                     `json:"message"`
+	Path       []json.RawMessage          `json:"path,omitempty"`
+}
+
+type GraphQLRequest struct {
+	OperationName string                     `json:"operationName"`
+	Query         string                     `json:"query"`
+	Variables     map[string]json.RawMessage `json:"variables"`
+}
+
+type GraphQLResponse struct {
+	Data       json.RawMessage            `json:"data,omitempty"`
+	Errors     []*GraphQLQueryError       `json:"errors,omitempty"`
+	Extensions map[string]json.RawMessage `json:"extensions,omitempty"`
+}
+
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+type IngestionReport struct {
+	Committed int           `json:"committed"`
+	Failed    []UnitFailure `json:"failed"`
+}
+
+type League struct {
+	AllTime       bool      `json:"all_time"`
+	AllTimeID     string    `json:"all_time_id,omitempty"`
+	AltNames      []string  `json:"alt_names,omitempty"`
+	Country       Country   `json:"country"`
+	CreatedAt     time.Time `json:"created_at"`
+	Format        string    `json:"format"`
+	Gender        string    `json:"gender"`
+	ID            string    `json:"id"`
+	International bool      `json:"international"`
+	LogoSource    string    `json:"logo_source"`
+	LogoURL       string    `json:"logo_url,omitempty"`
+	Name          string    `json:"name"`
+	ParentID      *string   `json:"parent_id,omitempty"`
+	Phases        []string  `json:"phases,omitempty"`
+	Region        string    `json:"region"`
+	RugbyDBID     string    `json:"rugby_db_id"`
+	Seasons       []Season  `json:"seasons,omitempty"`
+	SuccessorID   *string   `json:"successor_id,omitempty"`
+	TeamCountries []Country `json:"team_countries"`
+	Tier          int       `json:"tier"`
+	Tour          *Tour     `json:"tour,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type LeagueChange struct {
+	Changes map[string]json.RawMessage `json:"Changes"`
+	ID      string                     `json:"ID"`
+	IsNew   bool                       `json:"IsNew"`
+	Name    string                     `json:"Name"`
+}
+
+type LeagueIDMapping struct {
+	AllTime           bool               `json:"all_time"`
+	AllTimeID         string             `json:"all_time_id,omitempty"`
+	CompetitionID     string             `json:"competition_id"`
+	Error             string             `json:"error,omitempty"`
+	GroupMappings     []*APIMapping      `json:"group_mappings,omitempty"`
+	Groups            []CompetitionGroup `json:"groups"`
+	LeagueID          string             `json:"league_id"`
+	Name              string             `json:"name"`
+	NewAllTimeLeague  *League            `json:"new_all_time_league,omitempty"`
+	NewAllTimeMapping *APIMapping        `json:"new_all_time_mapping,omitempty"`
+	SeasonID          string             `json:"season_id"`
+	Year              string             `json:"year"`
+}
+
+type LeagueIDsResponse struct {
+	Leagues []LeagueIDMapping `json:"leagues"`
+	Year    string            `json:"year"`
+}
+
+type LeagueLineage struct {
+	Entries  []LineageEntry `json:"entries"`
+	LeagueID string         `json:"league_id"`
+}
+
+type LeagueMappingResponse struct {
+	DataFreshness string                `json:"data_freshness"`
+	Matched       []LeagueMappingResult `json:"matched"`
+	Stats         MappingStats          `json:"stats"`
+	Unmatched     []LeagueMappingResult `json:"unmatched"`
+}
+
+type LeagueMappingResult struct {
+	APILeague   APILeague `json:"api_league"`
+	InternalID  string    `json:"internal_id,omitempty"`
+	Matched     bool      `json:"matched"`
+	MatchedName string    `json:"matched_name,omitempty"`
+	Reason      string    `json:"reason"`
+}
+
+type LeagueMatchesResponse struct {
+	APIParams     APISportsParams  `json:"api_params"`
+	DailyMatches  []*DailyMatches  `json:"daily_matches"`
+	DataFreshness string           `json:"data_freshness"`
+	Date          string           `json:"date"`
+	LeagueID      string           `json:"league_id"`
+	Matches       []ServicesMatch  `json:"matches"`
+	Report        *IngestionReport `json:"report,omitempty"`
+	Season        string           `json:"season"`
+	Timezone      string           `json:"timezone"`
+}
+
+type LeagueNode struct {
+	Children []LeagueNode `json:"children"`
+	League   League       `json:"league"`
+}
+
+type LeagueTree struct {
+	Ancestors []League   `json:"ancestors"`
+	League    LeagueNode `json:"league"`
+}
+
+type LeaguesRefreshResponse struct {
+	Changes      []LeagueChange   `json:"changes"`
+	Message      string           `json:"message"`
+	Report       *IngestionReport `json:"report,omitempty"`
+	TotalChanges int              `json:"total_changes"`
+}
+
+type LineageEntry struct {
+	DisplayName string `json:"display_name"`
+	FromYear    int    `json:"from_year,omitempty"`
+	LeagueID    string `json:"league_id,omitempty"`
+	Name        string `json:"name"`
+	RenamedTo   string `json:"renamed_to,omitempty"`
+	ToYear      int    `json:"to_year,omitempty"`
+}
+
+type MappingStats struct {
+	MatchRate float64 `json:"match_rate"`
+	Matched   int     `json:"matched"`
+	Total     int     `json:"total"`
+	Unmatched int     `json:"unmatched"`
+}
+
+type Match struct {
+	APISportsID   int       `json:"api_sports_id"`
+	AwayScore     int       `json:"away_score"`
+	AwayTeam      *Team     `json:"away_team,omitempty"`
+	AwayTeamID    string    `json:"away_team_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	Date          string    `json:"date"`
+	HomeScore     int       `json:"home_score"`
+	HomeTeam      *Team     `json:"home_team,omitempty"`
+	HomeTeamID    string    `json:"home_team_id"`
+	ID            string    `json:"id"`
+	KickOff       time.Time `json:"kick_off"`
+	League        *League   `json:"league,omitempty"`
+	LeagueID      string    `json:"league_id"`
+	ScoreConflict bool      `json:"score_conflict,omitempty"`
+	ScoreSource   string    `json:"score_source,omitempty"`
+	Season        int       `json:"season"`
+	Status        string    `json:"status"`
+	Time          string    `json:"time"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Venue         string    `json:"venue,omitempty"`
+	VenueTimezone string    `json:"venue_timezone"`
+	Week          string    `json:"week"`
+}
+
+type MatchState struct {
+	AwayScore int    `json:"away_score"`
+	HomeScore int    `json:"home_score"`
+	Status    string `json:"status"`
+}
+
+type MatchesResponse struct {
+	DataFreshness string           `json:"data_freshness"`
+	Matches       []Match          `json:"matches"`
+	Report        *IngestionReport `json:"report,omitempty"`
+}
+
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+type PageLeague struct {
+	Items      []League `json:"items"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+type PageMatch struct {
+	Items      []Match `json:"items"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+type PageSeason struct {
+	Items      []Season `json:"items"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+type PageTeam struct {
+	Items      []Team `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type RapidapiCompetitionGroup struct {
+	Name       string   `json:"name"`
+	RapidAPIID int      `json:"rapid_api_id"`
+	Seasons    []Season `json:"seasons"`
+}
+
+type ReadinessResponse struct {
+	Checks   map[string]string `json:"checks"`
+	Circuits map[string]string `json:"circuits"`
+	Status   string            `json:"status"`
+}
+
+type Result struct {
+	Rows  int    `json:"rows"`
+	Table string `json:"table"`
+	URL   string `json:"url"`
+}
+
+type RugbyDBFixture struct {
+	AwayScore  *int   `json:"away_score,omitempty"`
+	AwayTeam   string `json:"away_team"`
+	AwayTeamID string `json:"away_team_id"`
+	Date       string `json:"date"`
+	HomeScore  *int   `json:"home_score,omitempty"`
+	HomeTeam   string `json:"home_team"`
+	HomeTeamID string `json:"home_team_id"`
+	MatchID    string `json:"match_id"`
+	Venue      string `json:"venue,omitempty"`
+}
+
+type RugbyDBMatchesResult struct {
+	Matches []Match                 `json:"matches"`
+	Seasons []string                `json:"seasons"`
+	Skipped []SkippedRugbyDBFixture `json:"skipped"`
+}
+
+type RugbyDBTeam struct {
+	Country    string `json:"country"`
+	ID         string `json:"id"`
+	InternalID string `json:"internal_id,omitempty"`
+	LogoURL    string `json:"logo_url,omitempty"`
+	Name       string `json:"name"`
+	TeamID     string `json:"team_id"`
+}
+
+type ScoreOverride struct {
+	AwayScore int       `json:"away_score"`
+	CreatedAt time.Time `json:"created_at"`
+	HomeScore int       `json:"home_score"`
+	MatchID   string    `json:"match_id"`
+	Note      string    `json:"note"`
+	Status    string    `json:"status"`
+}
+
+type ScoreOverrideRequest struct {
+	AwayScore int    `json:"away_score"`
+	HomeScore int    `json:"home_score"`
+	Note      string `json:"note,omitempty"`
+	Status    string `json:"status"`
+}
+
+type Season struct {
+	CreatedAt    time.Time `json:"created_at,omitempty"`
+	Current      bool      `json:"current"`
+	EndDate      time.Time `json:"end_date,omitempty"`
+	ID           string    `json:"id"`
+	LeagueID     string    `json:"league_id"`
+	RapidAPIYear int       `json:"rapid_api_year"`
+	StartDate    time.Time `json:"start_date,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at,omitempty"`
+	Year         int       `json:"year"`
+	YearRange    string    `json:"year_range,omitempty"`
+}
+
+type ServerMessage struct {
+	Error    string      `json:"error,omitempty"`
+	Event    string      `json:"event,omitempty"`
+	Match    *Match      `json:"match,omitempty"`
+	Matches  []Match     `json:"matches,omitempty"`
+	Previous *MatchState `json:"previous,omitempty"`
+	Team     *Team       `json:"team,omitempty"`
+	Type     string      `json:"type"`
+}
+
+type ServicesMatch struct {
+	Attendance        int             `json:"attendance,omitempty"`
+	AwayScore         int             `json:"away_score"`
+	AwayTeamID        string          `json:"away_team_id"`
+	Date              string          `json:"date"`
+	HeadToHead        json.RawMessage `json:"head_to_head,omitempty"`
+	HomeScore         int             `json:"home_score"`
+	HomeTeamID        string          `json:"home_team_id"`
+	ID                string          `json:"id"`
+	KickOff           time.Time       `json:"kick_off"`
+	LeagueID          string          `json:"league_id"`
+	Lineups           json.RawMessage `json:"lineups,omitempty"`
+	LiveStats         json.RawMessage `json:"live_stats,omitempty"`
+	Referee           string          `json:"referee,omitempty"`
+	Status            string          `json:"status"`
+	Time              string          `json:"time"`
+	Venue             string          `json:"venue,omitempty"`
+	VenueTimezone     string          `json:"venue_timezone"`
+	WeatherConditions string          `json:"weather_conditions,omitempty"`
+}
+
+type SkippedFixture struct {
+	Fixture  Fixture `json:"fixture"`
+	Reason   string  `json:"reason"`
+	SeasonID string  `json:"season_id"`
+}
+
+type SkippedRugbyDBFixture struct {
+	Fixture  RugbyDBFixture `json:"fixture"`
+	Reason   string         `json:"reason"`
+	SeasonID string         `json:"season_id"`
+}
+
+type SourceReport struct {
+	AwayScore  int       `json:"away_score"`
+	HomeScore  int       `json:"home_score"`
+	MatchID    string    `json:"match_id"`
+	ReportedAt time.Time `json:"reported_at"`
+	Source     string    `json:"source"`
+	Status     string    `json:"status"`
+}
+
+type Stadium struct {
+	Capacity  int       `json:"capacity"`
+	Country   Country   `json:"country"`
+	CreatedAt time.Time `json:"created_at"`
+	ID        string    `json:"id"`
+	Location  string    `json:"location"`
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type StatusChange struct {
+	ChangedAt time.Time `json:"changed_at"`
+	From      string    `json:"from,omitempty"`
+	ID        int64     `json:"id"`
+	MatchID   string    `json:"match_id"`
+	Source    string    `json:"source"`
+	To        string    `json:"to"`
+}
+
+type StatusHistoryResponse struct {
+	History []StatusChange `json:"history"`
+	MatchID string         `json:"match_id"`
+	Status  string         `json:"status"`
+}
+
+type SyncResult struct {
+	Matches []Match          `json:"matches"`
+	Seasons []string         `json:"seasons"`
+	Skipped []SkippedFixture `json:"skipped"`
+}
+
+type Team struct {
+	AlternateNames []string      `json:"alternate_names"`
+	Country        Country       `json:"country"`
+	CreatedAt      time.Time     `json:"created_at"`
+	ID             string        `json:"id"`
+	LogoSource     string        `json:"logo_source"`
+	LogoURL        string        `json:"logo_url"`
+	Name           string        `json:"name"`
+	Stadiums       []TeamStadium `json:"stadiums,omitempty"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+type TeamChange struct {
+	Changes map[string]json.RawMessage `json:"Changes"`
+	ID      string                     `json:"ID"`
+	IsNew   bool                       `json:"IsNew"`
+	Name    string                     `json:"Name"`
+}
+
+type TeamCreateRequest struct {
+	Country string   `json:"country"`
+	Names   []string `json:"names"`
+}
+
+type TeamForm struct {
+	Matches []TeamResult `json:"matches"`
+	Results string       `json:"results"`
+	TeamID  string       `json:"team_id"`
+}
+
+type TeamRecord struct {
+	AverageWinningMargin float64 `json:"average_winning_margin"`
+	Drawn                int     `json:"drawn"`
+	Lost                 int     `json:"lost"`
+	Played               int     `json:"played"`
+	PointsAgainst        int     `json:"points_against"`
+	PointsDifference     int     `json:"points_difference"`
+	PointsFor            int     `json:"points_for"`
+	Results              string  `json:"results"`
+	Won                  int     `json:"won"`
+}
+
+type TeamResult struct {
+	Home          bool      `json:"home"`
+	KickOff       time.Time `json:"kick_off"`
+	LeagueID      string    `json:"league_id"`
+	LeagueName    string    `json:"league_name"`
+	MatchID       string    `json:"match_id"`
+	OpponentID    string    `json:"opponent_id"`
+	OpponentName  string    `json:"opponent_name"`
+	PointsAgainst int       `json:"points_against"`
+	PointsFor     int       `json:"points_for"`
+	Result        string    `json:"result"`
+	SeasonID      string    `json:"season_id"`
+}
+
+type TeamStadium struct {
+	EndDate   time.Time `json:"end_date,omitempty"`
+	IsPrimary bool      `json:"is_primary"`
+	Stadium   Stadium   `json:"stadium"`
+	StartDate time.Time `json:"start_date,omitempty"`
+}
+
+type TeamStats struct {
+	Away     TeamRecord `json:"away"`
+	Home     TeamRecord `json:"home"`
+	Overall  TeamRecord `json:"overall"`
+	SeasonID string     `json:"season_id,omitempty"`
+	TeamID   string     `json:"team_id"`
+}
+
+type TeamsRefreshResponse struct {
+	Changes       []TeamChange     `json:"changes"`
+	FailedTeams   []FailedTeam     `json:"failed_teams"`
+	Message       string           `json:"message"`
+	Report        *IngestionReport `json:"report,omitempty"`
+	TotalChanges  int              `json:"total_changes"`
+	TotalFailures int              `json:"total_failures"`
+}
+
+type Tour struct {
+	HostCountries    []string `json:"host_countries"`
+	Hosts            []string `json:"hosts"`
+	TouristCountries []string `json:"tourist_countries"`
+	Tourists         []string `json:"tourists"`
+}
+
+type UnitFailure struct {
+	Error string `json:"error"`
+	Key   string `json:"key"`
+	Kind  string `json:"kind"`
+	Step  string `json:"step,omitempty"`
+}
+
+type WebhookDeadLetter struct {
+	Attempts       int             `json:"attempts"`
+	CreatedAt      time.Time       `json:"created_at"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	ID             int64           `json:"id"`
+	LastError      string          `json:"last_error"`
+	Payload        json.RawMessage `json:"payload"`
+	ReplayedAt     *time.Time      `json:"replayed_at,omitempty"`
+	SubscriptionID int64           `json:"subscription_id"`
+}
+
+type WebhookDeadLettersResponse struct {
+	DeadLetters []WebhookDeadLetter `json:"dead_letters"`
+}
+
+type WebhookSubscription struct {
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	Events    []string  `json:"events"`
+	ID        int64     `json:"id"`
+	Secret    string    `json:"secret,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+	URL       string    `json:"url"`
+}
+
+type WebhookSubscriptionRequest struct {
+	Events []string `json:"events"`
+	Secret string   `json:"secret,omitempty"`
+	URL    string   `json:"url"`
+}
+
+type WebhookSubscriptionsResponse struct {
+	Subscriptions []WebhookSubscription `json:"subscriptions"`
+}
+
+type WikidataTeam struct {
+	Coach           string   `json:"coach,omitempty"`
+	Competitions    []string `json:"competitions,omitempty"`
+	Country         string   `json:"country,omitempty"`
+	Facebook        string   `json:"facebook,omitempty"`
+	FIFACode        string   `json:"fifa_code,omitempty"`
+	Founded         string   `json:"founded,omitempty"`
+	ID              string   `json:"id"`
+	Instagram       string   `json:"instagram,omitempty"`
+	KitManufacturer string   `json:"kit_manufacturer,omitempty"`
+	Leagues         []string `json:"leagues,omitempty"`
+	LogoURL         string   `json:"logo_url,omitempty"`
+	Name            string   `json:"name"`
+	Nickname        string   `json:"nickname,omitempty"`
+	Players         []string `json:"players,omitempty"`
+	Sponsors        []string `json:"sponsors,omitempty"`
+	Stadium         string   `json:"stadium,omitempty"`
+	Twitter         string   `json:"twitter,omitempty"`
+	Website         string   `json:"website,omitempty"`
+}
+
+// ClearScoreOverride calls DELETE /api/admin/conflicts/{id}/override.
+// Remove a match's override and restore the provider result.
+func (c *Client) ClearScoreOverride(ctx context.Context, id string) (*Match, error) {
+	var out Match
+	if err := c.do(ctx, "DELETE", "/api/admin/conflicts/"+url.PathEscape(id)+"/override", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateWebhookSubscription calls POST /api/admin/webhooks.
+// Subscribe an endpoint to match.kickoff, match.score, match.status, match.final or team.updated events.
+func (c *Client) CreateWebhookSubscription(ctx context.Context, body WebhookSubscriptionRequest) (*WebhookSubscription, error) {
+	var out WebhookSubscription
+	if err := c.do(ctx, "POST", "/api/admin/webhooks", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteWebhookSubscription calls DELETE /api/admin/webhooks/{id}.
+// Remove a webhook subscription and its dead letters.
+func (c *Client) DeleteWebhookSubscription(ctx context.Context, id string) (*WebhookSubscription, error) {
+	var out WebhookSubscription
+	if err := c.do(ctx, "DELETE", "/api/admin/webhooks/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExportTableParams holds the query parameters for ExportTable.
+type ExportTableParams struct {
+	// csv (default), jsonl or parquet
+	Format string
+	// Only rows belonging to this season
+	SeasonID string
+	// Only rows belonging to this league
+	LeagueID string
+}
+
+// ExportTable calls GET /admin/export/{table}.
+// Stream one table as CSV, JSON Lines or Parquet.
+// The caller must close the returned body.
+func (c *Client) ExportTable(ctx context.Context, table string, params ExportTableParams) (io.ReadCloser, error) {
+	query := url.Values{}
+	if params.Format != "" {
+		query.Set("format", params.Format)
+	}
+	if params.SeasonID != "" {
+		query.Set("season_id", params.SeasonID)
+	}
+	if params.LeagueID != "" {
+		query.Set("league_id", params.LeagueID)
+	}
+	return c.stream(ctx, "GET", "/admin/export/"+url.PathEscape(table), query, nil)
+}
+
+// ExportToStorageParams holds the query parameters for ExportToStorage.
+type ExportToStorageParams struct {
+	// Comma-separated table names; defaults to all
+	Tables string
+	// csv (default), jsonl or parquet
+	Format string
+	// Only rows belonging to this season
+	SeasonID string
+	// Only rows belonging to this league
+	LeagueID string
+}
+
+// ExportToStorage calls POST /admin/export.
+// Write tables to the object store.
+func (c *Client) ExportToStorage(ctx context.Context, params ExportToStorageParams) (*ExportResponse, error) {
+	query := url.Values{}
+	if params.Tables != "" {
+		query.Set("tables", params.Tables)
+	}
+	if params.Format != "" {
+		query.Set("format", params.Format)
+	}
+	if params.SeasonID != "" {
+		query.Set("season_id", params.SeasonID)
+	}
+	if params.LeagueID != "" {
+		query.Set("league_id", params.LeagueID)
+	}
+	var out ExportResponse
+	if err := c.do(ctx, "POST", "/admin/export", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// FetchTodaysMatchesParams holds the query parameters for FetchTodaysMatches.
+type FetchTodaysMatchesParams struct {
+	// IANA timezone for dates and times in the response; defaults to UTC
+	TZ string
+}
+
+// FetchTodaysMatches calls GET /matches.
+// Fetch today's matches from API Sports and store them, or the stored ones while API Sports is unavailable.
+func (c *Client) FetchTodaysMatches(ctx context.Context, params FetchTodaysMatchesParams) (*MatchesResponse, error) {
+	query := url.Values{}
+	if params.TZ != "" {
+		query.Set("tz", params.TZ)
+	}
+	var out MatchesResponse
+	if err := c.do(ctx, "GET", "/matches", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetCountries calls GET /countries.
+// List countries.
+func (c *Client) GetCountries(ctx context.Context) ([]Country, error) {
+	var out []Country
+	if err := c.do(ctx, "GET", "/countries", nil, nil, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// GetESPNLeagues calls GET /espn/leagues.
+// Scrape the ESPN league list.
+func (c *Client) GetESPNLeagues(ctx context.Context) ([]ESPNLeague, error) {
+	var out []ESPNLeague
+	if err := c.do(ctx, "GET", "/espn/leagues", nil, nil, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// GetHealth calls GET /healthz.
+// Liveness: 200 while the process is serving.
+func (c *Client) GetHealth(ctx context.Context) (*HealthResponse, error) {
+	var out HealthResponse
+	if err := c.do(ctx, "GET", "/healthz", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetLeagueIDsByYear calls GET /rugbydb/leagues/ids/{year}.
+// List RugbyDB competition IDs mapped for a year.
+func (c *Client) GetLeagueIDsByYear(ctx context.Context, year string) (*LeagueIDsResponse, error) {
+	var out LeagueIDsResponse
+	if err := c.do(ctx, "GET", "/rugbydb/leagues/ids/"+url.PathEscape(year), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetLeagueLineage calls GET /api/leagues/{id}/lineage.
+// The names a league has been played under, with the years each was used.
+func (c *Client) GetLeagueLineage(ctx context.Context, id string) (*LeagueLineage, error) {
+	var out LeagueLineage
+	if err := c.do(ctx, "GET", "/api/leagues/"+url.PathEscape(id)+"/lineage", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetLeagueTree calls GET /api/leagues/{id}/tree.
+// A league with its parent competitions and the competitions played within it.
+func (c *Client) GetLeagueTree(ctx context.Context, id string) (*LeagueTree, error) {
+	var out LeagueTree
+	if err := c.do(ctx, "GET", "/api/leagues/"+url.PathEscape(id)+"/tree", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetMatchStatusHistory calls GET /api/matches/{id}/status-history.
+// A match's status and its recorded status changes.
+func (c *Client) GetMatchStatusHistory(ctx context.Context, id string) (*StatusHistoryResponse, error) {
+	var out StatusHistoryResponse
+	if err := c.do(ctx, "GET", "/api/matches/"+url.PathEscape(id)+"/status-history", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetMatchesByLeagueParams holds the query parameters for GetMatchesByLeague.
+type GetMatchesByLeagueParams struct {
+	LeagueID string
+	Date     string
+	Season   string
+	// API Sports league ID override
+	APILeagueID string
+	// API Sports season override
+	APISeason string
+	// API Sports date override
+	APIDate string
+	// IANA timezone for dates and times in the response; defaults to UTC
+	TZ string
+}
+
+// GetMatchesByLeague calls GET /matches/api-sports/league.
+// Fetch and store a league's matches from API Sports, or the stored ones while API Sports is unavailable.
+func (c *Client) GetMatchesByLeague(ctx context.Context, params GetMatchesByLeagueParams) (*LeagueMatchesResponse, error) {
+	query := url.Values{}
+	if params.LeagueID != "" {
+		query.Set("league_id", params.LeagueID)
+	}
+	if params.Date != "" {
+		query.Set("date", params.Date)
+	}
+	if params.Season != "" {
+		query.Set("season", params.Season)
+	}
+	if params.APILeagueID != "" {
+		query.Set("api_league_id", params.APILeagueID)
+	}
+	if params.APISeason != "" {
+		query.Set("api_season", params.APISeason)
+	}
+	if params.APIDate != "" {
+		query.Set("api_date", params.APIDate)
+	}
+	if params.TZ != "" {
+		query.Set("tz", params.TZ)
+	}
+	var out LeagueMatchesResponse
+	if err := c.do(ctx, "GET", "/matches/api-sports/league", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetMetrics calls GET /metrics.
+// Prometheus metrics for HTTP routes, providers, ingestion and live matches.
+// The caller must close the returned body.
+func (c *Client) GetMetrics(ctx context.Context) (io.ReadCloser, error) {
+	return c.stream(ctx, "GET", "/metrics", nil, nil)
+}
+
+// GetOpenAPI calls GET /openapi.json.
+// This document.
+func (c *Client) GetOpenAPI(ctx context.Context) (map[string]json.RawMessage, error) {
+	var out map[string]json.RawMessage
+	if err := c.do(ctx, "GET", "/openapi.json", nil, nil, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// GetReadiness calls GET /readyz.
+// Readiness: checks the database, its migration version and object storage; 503 when any fails or during shutdown.
+func (c *Client) GetReadiness(ctx context.Context) (*ReadinessResponse, error) {
+	var out ReadinessResponse
+	if err := c.do(ctx, "GET", "/readyz", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetRugbyDBLeaguesParams holds the query parameters for GetRugbyDBLeagues.
+type GetRugbyDBLeaguesParams struct {
+	DryRun *bool
+}
+
+// GetRugbyDBLeagues calls GET /rugbydb/leagues/{year}.
+// Scrape and store RugbyDB competitions for a year.
+func (c *Client) GetRugbyDBLeagues(ctx context.Context, year string, params GetRugbyDBLeaguesParams) ([]League, error) {
+	query := url.Values{}
+	if params.DryRun != nil {
+		query.Set("dry_run", strconv.FormatBool(*params.DryRun))
+	}
+	var out []League
+	if err := c.do(ctx, "GET", "/rugbydb/leagues/"+url.PathEscape(year), query, nil, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// GetRugbyDBTeams calls POST /rugbydb/teams.
+// Scrape and store RugbyDB teams.
+func (c *Client) GetRugbyDBTeams(ctx context.Context, body TeamCreateRequest) ([]RugbyDBTeam, error) {
+	var out []RugbyDBTeam
+	if err := c.do(ctx, "POST", "/rugbydb/teams", nil, body, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// GetRugbyLiveCompetitions calls GET /api/rapidapi/competitions.
+// Match Rugby Live Data competitions to stored leagues.
+func (c *Client) GetRugbyLiveCompetitions(ctx context.Context) (*CompetitionMappingResponse, error) {
+	var out CompetitionMappingResponse
+	if err := c.do(ctx, "GET", "/api/rapidapi/competitions", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetTeamFormParams holds the query parameters for GetTeamForm.
+type GetTeamFormParams struct {
+	// Number of results, 1 to 50; defaults to 5
+	N *int
+}
+
+// GetTeamForm calls GET /api/teams/{id}/form.
+// A team's most recent results as a W/D/L form guide.
+func (c *Client) GetTeamForm(ctx context.Context, id string, params GetTeamFormParams) (*TeamForm, error) {
+	query := url.Values{}
+	if params.N != nil {
+		query.Set("n", strconv.Itoa(*params.N))
+	}
+	var out TeamForm
+	if err := c.do(ctx, "GET", "/api/teams/"+url.PathEscape(id)+"/form", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetTeamStatsParams holds the query parameters for GetTeamStats.
+type GetTeamStatsParams struct {
+	// Season to summarise; defaults to every stored season outside all-time leagues
+	SeasonID string
+}
+
+// GetTeamStats calls GET /api/teams/{id}/stats.
+// A team's record, points and winning margin, overall and split by home and away.
+func (c *Client) GetTeamStats(ctx context.Context, id string, params GetTeamStatsParams) (*TeamStats, error) {
+	query := url.Values{}
+	if params.SeasonID != "" {
+		query.Set("season_id", params.SeasonID)
+	}
+	var out TeamStats
+	if err := c.do(ctx, "GET", "/api/teams/"+url.PathEscape(id)+"/stats", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetWikidataTeams calls GET /wikidata/teams.
+// List rugby union teams from Wikidata.
+func (c *Client) GetWikidataTeams(ctx context.Context) ([]WikidataTeam, error) {
+	var out []WikidataTeam
+	if err := c.do(ctx, "GET", "/wikidata/teams", nil, nil, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// ListConflictsParams holds the query parameters for ListConflicts.
+type ListConflictsParams struct {
+	// Include conflicts already settled by an override
+	IncludeResolved *bool
+}
+
+// ListConflicts calls GET /api/admin/conflicts.
+// List matches whose providers disagree on the result.
+func (c *Client) ListConflicts(ctx context.Context, params ListConflictsParams) (*ConflictsResponse, error) {
+	query := url.Values{}
+	if params.IncludeResolved != nil {
+		query.Set("include_resolved", strconv.FormatBool(*params.IncludeResolved))
+	}
+	var out ConflictsResponse
+	if err := c.do(ctx, "GET", "/api/admin/conflicts", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListLeaguesParams holds the query parameters for ListLeagues.
+type ListLeaguesParams struct {
+	// Page size, at most 200; defaults to 50
+	Limit *int
+	// next_cursor from the previous page
+	Cursor string
+	// One of name, tier, created_at; prefix with - for descending
+	Sort string
+	// Country code
+	Country       string
+	Gender        string
+	Tier          *int
+	International *bool
+	AllTime       *bool
+	ParentID      string
+	// Country code of a touring side, selecting tours
+	Tourist string
+	// Country code of a tour's hosts
+	Host string
+	// Case-insensitive substring of the name or any alternate name
+	Q string
+}
+
+// ListLeagues calls GET /api/leagues.
+// List leagues.
+func (c *Client) ListLeagues(ctx context.Context, params ListLeaguesParams) (*PageLeague, error) {
+	query := url.Values{}
+	if params.Limit != nil {
+		query.Set("limit", strconv.Itoa(*params.Limit))
+	}
+	if params.Cursor != "" {
+		query.Set("cursor", params.Cursor)
+	}
+	if params.Sort != "" {
+		query.Set("sort", params.Sort)
+	}
+	if params.Country != "" {
+		query.Set("country", params.Country)
+	}
+	if params.Gender != "" {
+		query.Set("gender", params.Gender)
+	}
+	if params.Tier != nil {
+		query.Set("tier", strconv.Itoa(*params.Tier))
+	}
+	if params.International != nil {
+		query.Set("international", strconv.FormatBool(*params.International))
+	}
+	if params.AllTime != nil {
+		query.Set("all_time", strconv.FormatBool(*params.AllTime))
+	}
+	if params.ParentID != "" {
+		query.Set("parent_id", params.ParentID)
+	}
+	if params.Tourist != "" {
+		query.Set("tourist", params.Tourist)
+	}
+	if params.Host != "" {
+		query.Set("host", params.Host)
+	}
+	if params.Q != "" {
+		query.Set("q", params.Q)
+	}
+	var out PageLeague
+	if err := c.do(ctx, "GET", "/api/leagues", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListMatchesParams holds the query parameters for ListMatches.
+type ListMatchesParams struct {
+	// Page size, at most 200; defaults to 50
+	Limit *int
+	// next_cursor from the previous page
+	Cursor string
+	// One of kick_off; prefix with - for descending
+	Sort string
+	// Season or league ID stored on the match
+	LeagueID string
+	// Home or away team
+	TeamID string
+	// scheduled, first_half, half_time, second_half, extra_time, finished, postponed, abandoned or cancelled
+	Status string
+	// Calendar day as YYYY-MM-DD in tz
+	Date string
+	// Earliest kick-off, RFC 3339
+	From string
+	// Kick-off before, RFC 3339
+	To string
+	// IANA timezone for dates and times in the response; defaults to UTC
+	TZ string
+}
+
+// ListMatches calls GET /api/matches.
+// List stored matches, today's in tz unless date, from or to is given.
+func (c *Client) ListMatches(ctx context.Context, params ListMatchesParams) (*PageMatch, error) {
+	query := url.Values{}
+	if params.Limit != nil {
+		query.Set("limit", strconv.Itoa(*params.Limit))
+	}
+	if params.Cursor != "" {
+		query.Set("cursor", params.Cursor)
+	}
+	if params.Sort != "" {
+		query.Set("sort", params.Sort)
+	}
+	if params.LeagueID != "" {
+		query.Set("league_id", params.LeagueID)
+	}
+	if params.TeamID != "" {
+		query.Set("team_id", params.TeamID)
+	}
+	if params.Status != "" {
+		query.Set("status", params.Status)
+	}
+	if params.Date != "" {
+		query.Set("date", params.Date)
+	}
+	if params.From != "" {
+		query.Set("from", params.From)
+	}
+	if params.To != "" {
+		query.Set("to", params.To)
+	}
+	if params.TZ != "" {
+		query.Set("tz", params.TZ)
+	}
+	var out PageMatch
+	if err := c.do(ctx, "GET", "/api/matches", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListSeasonsParams holds the query parameters for ListSeasons.
+type ListSeasonsParams struct {
+	// Page size, at most 200; defaults to 50
+	Limit *int
+	// next_cursor from the previous page
+	Cursor string
+	// One of year, created_at; prefix with - for descending
+	Sort     string
+	LeagueID string
+	Year     *int
+	Current  *bool
+}
+
+// ListSeasons calls GET /api/seasons.
+// List seasons.
+func (c *Client) ListSeasons(ctx context.Context, params ListSeasonsParams) (*PageSeason, error) {
+	query := url.Values{}
+	if params.Limit != nil {
+		query.Set("limit", strconv.Itoa(*params.Limit))
+	}
+	if params.Cursor != "" {
+		query.Set("cursor", params.Cursor)
+	}
+	if params.Sort != "" {
+		query.Set("sort", params.Sort)
+	}
+	if params.LeagueID != "" {
+		query.Set("league_id", params.LeagueID)
+	}
+	if params.Year != nil {
+		query.Set("year", strconv.Itoa(*params.Year))
+	}
+	if params.Current != nil {
+		query.Set("current", strconv.FormatBool(*params.Current))
+	}
+	var out PageSeason
+	if err := c.do(ctx, "GET", "/api/seasons", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListTeamsParams holds the query parameters for ListTeams.
+type ListTeamsParams struct {
+	// Page size, at most 200; defaults to 50
+	Limit *int
+	// next_cursor from the previous page
+	Cursor string
+	// One of name, created_at; prefix with - for descending
+	Sort string
+	// Country code
+	Country string
+	// Case-insensitive substring of the name or any alternate name
+	Q string
+}
+
+// ListTeams calls GET /api/teams.
+// List teams.
+func (c *Client) ListTeams(ctx context.Context, params ListTeamsParams) (*PageTeam, error) {
+	query := url.Values{}
+	if params.Limit != nil {
+		query.Set("limit", strconv.Itoa(*params.Limit))
+	}
+	if params.Cursor != "" {
+		query.Set("cursor", params.Cursor)
+	}
+	if params.Sort != "" {
+		query.Set("sort", params.Sort)
+	}
+	if params.Country != "" {
+		query.Set("country", params.Country)
+	}
+	if params.Q != "" {
+		query.Set("q", params.Q)
+	}
+	var out PageTeam
+	if err := c.do(ctx, "GET", "/api/teams", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListWebhookDeadLettersParams holds the query parameters for ListWebhookDeadLetters.
+type ListWebhookDeadLettersParams struct {
+	// Include dead letters already replayed successfully
+	IncludeReplayed *bool
+}
+
+// ListWebhookDeadLetters calls GET /api/admin/webhooks/dead-letters.
+// List webhook deliveries that failed every retry.
+func (c *Client) ListWebhookDeadLetters(ctx context.Context, params ListWebhookDeadLettersParams) (*WebhookDeadLettersResponse, error) {
+	query := url.Values{}
+	if params.IncludeReplayed != nil {
+		query.Set("include_replayed", strconv.FormatBool(*params.IncludeReplayed))
+	}
+	var out WebhookDeadLettersResponse
+	if err := c.do(ctx, "GET", "/api/admin/webhooks/dead-letters", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListWebhookSubscriptions calls GET /api/admin/webhooks.
+// List webhook subscriptions, without their secrets.
+func (c *Client) ListWebhookSubscriptions(ctx context.Context) (*WebhookSubscriptionsResponse, error) {
+	var out WebhookSubscriptionsResponse
+	if err := c.do(ctx, "GET", "/api/admin/webhooks", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MapAPISportsLeagues calls GET /leagues/map-api-sports.
+// Match API Sports leagues to stored leagues, or list the stored mappings while API Sports is unavailable.
+func (c *Client) MapAPISportsLeagues(ctx context.Context) (*LeagueMappingResponse, error) {
+	var out LeagueMappingResponse
+	if err := c.do(ctx, "GET", "/leagues/map-api-sports", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// OverrideScore calls PUT /api/admin/conflicts/{id}/override.
+// Set a match's result by hand.
+func (c *Client) OverrideScore(ctx context.Context, id string, body ScoreOverrideRequest) (*Match, error) {
+	var out Match
+	if err := c.do(ctx, "PUT", "/api/admin/conflicts/"+url.PathEscape(id)+"/override", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PostGraphQL calls POST /graphql.
+// Run a GraphQL query of at most 16 KiB, nested at most 10 fields deep.
+func (c *Client) PostGraphQL(ctx context.Context, body GraphQLRequest) (*GraphQLResponse, error) {
+	var out GraphQLResponse
+	if err := c.do(ctx, "POST", "/graphql", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// QueryGraphQLParams holds the query parameters for QueryGraphQL.
+type QueryGraphQLParams struct {
+	Query         string
+	OperationName string
+	// JSON-encoded variables
+	Variables string
+}
+
+// QueryGraphQL calls GET /graphql.
+// Run a GraphQL query of at most 16 KiB, nested at most 10 fields deep.
+func (c *Client) QueryGraphQL(ctx context.Context, params QueryGraphQLParams) (*GraphQLResponse, error) {
+	query := url.Values{}
+	if params.Query != "" {
+		query.Set("query", params.Query)
+	}
+	if params.OperationName != "" {
+		query.Set("operationName", params.OperationName)
+	}
+	if params.Variables != "" {
+		query.Set("variables", params.Variables)
+	}
+	var out GraphQLResponse
+	if err := c.do(ctx, "GET", "/graphql", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RefreshCountriesParams holds the query parameters for RefreshCountries.
+type RefreshCountriesParams struct {
+	UpdateFlags *bool
+}
+
+// RefreshCountries calls POST /countries/refresh.
+// Refresh countries from API Sports.
+func (c *Client) RefreshCountries(ctx context.Context, params RefreshCountriesParams) (*CountriesRefreshResponse, error) {
+	query := url.Values{}
+	if params.UpdateFlags != nil {
+		query.Set("update_flags", strconv.FormatBool(*params.UpdateFlags))
+	}
+	var out CountriesRefreshResponse
+	if err := c.do(ctx, "POST", "/countries/refresh", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RefreshLeaguesParams holds the query parameters for RefreshLeagues.
+type RefreshLeaguesParams struct {
+	UpdateImages *bool
+}
+
+// RefreshLeagues calls POST /leagues/refresh.
+// Refresh leagues from API Sports.
+func (c *Client) RefreshLeagues(ctx context.Context, params RefreshLeaguesParams) (*LeaguesRefreshResponse, error) {
+	query := url.Values{}
+	if params.UpdateImages != nil {
+		query.Set("update_images", strconv.FormatBool(*params.UpdateImages))
+	}
+	var out LeaguesRefreshResponse
+	if err := c.do(ctx, "POST", "/leagues/refresh", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RefreshTeamsParams holds the query parameters for RefreshTeams.
+type RefreshTeamsParams struct {
+	UpdateImages *bool
+	// API Sports country ID
+	Country string
+	// API Sports league ID; requires season
+	League string
+	Season *int
+}
+
+// RefreshTeams calls POST /teams/refresh.
+// Refresh teams from API Sports.
+func (c *Client) RefreshTeams(ctx context.Context, params RefreshTeamsParams) (*TeamsRefreshResponse, error) {
+	query := url.Values{}
+	if params.UpdateImages != nil {
+		query.Set("update_images", strconv.FormatBool(*params.UpdateImages))
+	}
+	if params.Country != "" {
+		query.Set("country", params.Country)
+	}
+	if params.League != "" {
+		query.Set("league", params.League)
+	}
+	if params.Season != nil {
+		query.Set("season", strconv.Itoa(*params.Season))
+	}
+	var out TeamsRefreshResponse
+	if err := c.do(ctx, "POST", "/teams/refresh", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ReplayWebhookDeadLetter calls POST /api/admin/webhooks/dead-letters/{id}/replay.
+// Send a dead-lettered delivery again.
+func (c *Client) ReplayWebhookDeadLetter(ctx context.Context, id string) (*WebhookDeadLetter, error) {
+	var out WebhookDeadLetter
+	if err := c.do(ctx, "POST", "/api/admin/webhooks/dead-letters/"+url.PathEscape(id)+"/replay", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ScrapeRugbyDBMatchesParams holds the query parameters for ScrapeRugbyDBMatches.
+type ScrapeRugbyDBMatchesParams struct {
+	// Only seasons starting in this year
+	Year *int
+	// Only this season
+	SeasonID string
+	DryRun   *bool
+}
+
+// ScrapeRugbyDBMatches calls POST /rugbydb/matches.
+// Scrape fixtures and results for seasons mapped to RugbyDB competitions.
+func (c *Client) ScrapeRugbyDBMatches(ctx context.Context, params ScrapeRugbyDBMatchesParams) (*RugbyDBMatchesResult, error) {
+	query := url.Values{}
+	if params.Year != nil {
+		query.Set("year", strconv.Itoa(*params.Year))
+	}
+	if params.SeasonID != "" {
+		query.Set("season_id", params.SeasonID)
+	}
+	if params.DryRun != nil {
+		query.Set("dry_run", strconv.FormatBool(*params.DryRun))
+	}
+	var out RugbyDBMatchesResult
+	if err := c.do(ctx, "POST", "/rugbydb/matches", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SearchWikidataTeamsParams holds the query parameters for SearchWikidataTeams.
+type SearchWikidataTeamsParams struct {
+	Name string
+}
+
+// SearchWikidataTeams calls GET /wikidata/teams/search.
+// Search Wikidata for a team by name.
+func (c *Client) SearchWikidataTeams(ctx context.Context, params SearchWikidataTeamsParams) (*WikidataTeam, error) {
+	query := url.Values{}
+	if params.Name != "" {
+		query.Set("name", params.Name)
+	}
+	var out WikidataTeam
+	if err := c.do(ctx, "GET", "/wikidata/teams/search", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SyncRugbyLiveMatchesParams holds the query parameters for SyncRugbyLiveMatches.
+type SyncRugbyLiveMatchesParams struct {
+	// Poll live scores instead of full fixture lists
+	Live *bool
+}
+
+// SyncRugbyLiveMatches calls POST /api/rapidapi/matches.
+// Merge fixtures, results or live scores for active Rugby Live Data seasons into matches.
+func (c *Client) SyncRugbyLiveMatches(ctx context.Context, params SyncRugbyLiveMatchesParams) (*SyncResult, error) {
+	query := url.Values{}
+	if params.Live != nil {
+		query.Set("live", strconv.FormatBool(*params.Live))
+	}
+	var out SyncResult
+	if err := c.do(ctx, "POST", "/api/rapidapi/matches", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateTeamImages calls POST /teams/update-images.
+// Re-upload team logos to storage.
+func (c *Client) UpdateTeamImages(ctx context.Context) (*MessageResponse, error) {
+	var out MessageResponse
+	if err := c.do(ctx, "POST", "/teams/update-images", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,3 @@
+package client
+
+//go:generate go run ../cmd/openapigen -spec ../openapi.json -client client.go
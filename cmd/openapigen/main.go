@@ -0,0 +1,41 @@
+// Command openapigen writes the OpenAPI document and the generated Go client.
+//
+//	go run ./cmd/openapigen -spec openapi.json -client client/client.go
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"rugby-live-api/openapi"
+)
+
+func main() {
+	specPath := flag.String("spec", "", "write the OpenAPI document to this file")
+	clientPath := flag.String("client", "", "write the generated Go client to this file")
+	pkg := flag.String("package", "client", "package name for the generated client")
+	flag.Parse()
+
+	doc := openapi.Spec()
+
+	if *specPath != "" {
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to encode document: %v", err)
+		}
+		if err := os.WriteFile(*specPath, append(data, '\n'), 0644); err != nil {
+			log.Fatalf("Failed to write document: %v", err)
+		}
+	}
+
+	if *clientPath != "" {
+		src, err := openapi.GenerateClient(doc, *pkg)
+		if err != nil {
+			log.Fatalf("Failed to generate client: %v", err)
+		}
+		if err := os.WriteFile(*clientPath, src, 0644); err != nil {
+			log.Fatalf("Failed to write client: %v", err)
+		}
+	}
+}
@@ -1,31 +1,546 @@
+// Package config loads the service's settings. Defaults are overridden by an
+// optional YAML file, then by the environment, which .env may populate, and
+// the result is validated before anything starts.
 package config
 
 import (
-	"database/sql"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
-	_ "github.com/lib/pq" // PostgreSQL driver
+	"gopkg.in/yaml.v3"
 )
 
-func LoadConfig() error {
-	return godotenv.Load()
+// DefaultFile is read when CONFIG_FILE is unset and it exists.
+const DefaultFile = "config.yaml"
+
+// Storage backends.
+const (
+	StorageSupabase = "supabase"
+	StorageNone     = "none"
+)
+
+type Config struct {
+	Server    Server    `yaml:"server"`
+	Database  Database  `yaml:"database"`
+	Storage   Storage   `yaml:"storage"`
+	Providers Providers `yaml:"providers"`
+	Log       Log       `yaml:"log"`
+	Score     Score     `yaml:"score"`
 }
 
-func InitDB() (*sql.DB, error) {
-	dbURL := os.Getenv("DATABASE_URL")
-	db, err := sql.Open("postgres", dbURL)
-	if err != nil {
-		return nil, err
+type Server struct {
+	Addr string `yaml:"addr"`
+	// ShutdownTimeout bounds how long shutdown waits for in-flight requests
+	// and webhook deliveries.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// AdminToken is the bearer token admin routes require. While it is
+	// empty they refuse every request.
+	AdminToken string `yaml:"admin_token"`
+}
+
+type Database struct {
+	URL             string        `yaml:"url"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"` // Zero keeps connections open indefinitely
+	QueryTimeout    time.Duration `yaml:"query_timeout"`     // Deadline for each store operation
+}
+
+// Storage is where team logos and exports are written. Backend "none" turns
+// uploads off.
+type Storage struct {
+	Backend        string `yaml:"backend"`
+	URL            string `yaml:"url"` // Project URL; a trailing /storage/v1/s3 is dropped
+	ServiceRoleKey string `yaml:"service_role_key"`
+	Bucket         string `yaml:"bucket"`
+}
+
+type Providers struct {
+	APISports Provider `yaml:"api_sports"`
+	RapidAPI  Provider `yaml:"rapid_api"`
+	RugbyDB   Provider `yaml:"rugbydb"`
+	Wikidata  Provider `yaml:"wikidata"`
+	ESPN      Provider `yaml:"espn"`
+}
+
+// Provider configures one upstream data source. A disabled provider's
+// endpoints fail without making requests.
+type Provider struct {
+	Enabled bool          `yaml:"enabled"`
+	BaseURL string        `yaml:"base_url"`
+	APIKey  string        `yaml:"api_key"`
+	Timeout time.Duration `yaml:"timeout"`
+	// Concurrency caps the requests in flight to each of the provider's
+	// hosts, and RateLimit the requests started per second. A zero RateLimit
+	// leaves the rate unlimited.
+	Concurrency int     `yaml:"concurrency"`
+	RateLimit   float64 `yaml:"rate_limit"`
+	// Retries is how many times a failed request is retried, within
+	// RetryBudget of its first attempt. ESPN, scraped through colly, is not
+	// retried.
+	Retries     int           `yaml:"retries"`
+	RetryBudget time.Duration `yaml:"retry_budget"`
+	// After BreakerThreshold failures in a row, requests to the provider's
+	// host fail at once for BreakerCooldown before one is tried again.
+	BreakerThreshold int           `yaml:"breaker_threshold"`
+	BreakerCooldown  time.Duration `yaml:"breaker_cooldown"`
+	// QueryURL is Wikidata's SPARQL endpoint, which is served from its own
+	// host.
+	QueryURL string `yaml:"query_url"`
+}
+
+// ErrProviderDisabled is returned, wrapped, by calls to a provider turned off
+// in the configuration.
+var ErrProviderDisabled = errors.New("provider is disabled")
+
+// Require returns an error wrapping ErrProviderDisabled, naming the provider
+// as name, unless p is enabled.
+func (p Provider) Require(name string) error {
+	if !p.Enabled {
+		return fmt.Errorf("%s: %w", name, ErrProviderDisabled)
+	}
+	return nil
+}
+
+type Log struct {
+	Format string `yaml:"format"` // json or text; defaults to json under GIN_MODE=release
+	Level  string `yaml:"level"`
+}
+
+// Score configures how reports from several sources are reconciled. An
+// empty SourcePriority keeps the reconcile package's default order.
+type Score struct {
+	SourcePriority []string      `yaml:"source_priority"`
+	Freshness      time.Duration `yaml:"freshness"`
+}
+
+// Default returns the settings used where nothing overrides them.
+func Default() *Config {
+	return &Config{
+		Server: Server{Addr: ":8080", ShutdownTimeout: 30 * time.Second},
+		Database: Database{
+			MaxOpenConns: 25,
+			MaxIdleConns: 25,
+			QueryTimeout: 30 * time.Second,
+		},
+		Storage: Storage{Backend: StorageSupabase, Bucket: "rugbylive-api"},
+		Providers: Providers{
+			APISports: Provider{
+				Enabled:          true,
+				BaseURL:          "https://v1.rugby.api-sports.io",
+				Timeout:          30 * time.Second,
+				Concurrency:      8,
+				RateLimit:        10,
+				Retries:          3,
+				RetryBudget:      time.Minute,
+				BreakerThreshold: 5,
+				BreakerCooldown:  30 * time.Second,
+			},
+			RapidAPI: Provider{
+				Enabled:          true,
+				BaseURL:          "https://rugby-live-data.p.rapidapi.com",
+				Timeout:          30 * time.Second,
+				Concurrency:      4,
+				RateLimit:        5,
+				Retries:          3,
+				RetryBudget:      time.Minute,
+				BreakerThreshold: 5,
+				BreakerCooldown:  30 * time.Second,
+			},
+			// RugbyDB is scraped, so it gets a gentle rate.
+			RugbyDB: Provider{
+				Enabled:          true,
+				BaseURL:          "https://www.rugbydatabase.co.nz",
+				Timeout:          30 * time.Second,
+				Concurrency:      4,
+				RateLimit:        4,
+				Retries:          3,
+				RetryBudget:      time.Minute,
+				BreakerThreshold: 5,
+				BreakerCooldown:  30 * time.Second,
+			},
+			Wikidata: Provider{
+				Enabled:          true,
+				BaseURL:          "https://www.wikidata.org",
+				QueryURL:         "https://query.wikidata.org/sparql",
+				Timeout:          30 * time.Second,
+				Concurrency:      4,
+				Retries:          3,
+				RetryBudget:      time.Minute,
+				BreakerThreshold: 5,
+				BreakerCooldown:  30 * time.Second,
+			},
+			ESPN: Provider{
+				Enabled:          true,
+				BaseURL:          "https://www.espn.com",
+				Timeout:          30 * time.Second,
+				Concurrency:      2,
+				BreakerThreshold: 5,
+				BreakerCooldown:  30 * time.Second,
+			},
+		},
+		Log:   Log{Level: "info"},
+		Score: Score{Freshness: 10 * time.Minute},
+	}
+}
+
+// MinAdminTokenLength keeps admin tokens long enough not to be guessed.
+const MinAdminTokenLength = 32
+
+// Part is a group of settings that only some commands use. The database,
+// logging and score settings are used by every command and always validated.
+type Part int
+
+const (
+	PartServer    Part = 1 << iota // Listen address and shutdown
+	PartStorage                    // Object storage for logos and exports
+	PartProviders                  // Upstream data sources
+	PartAll       = PartServer | PartStorage | PartProviders
+)
+
+// Load reads .env if present, then the YAML file named by CONFIG_FILE or
+// DefaultFile, then the environment, and validates the result for a command
+// using parts.
+func Load(parts Part) (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read .env: %v", err)
 	}
 
-	if err := db.Ping(); err != nil {
+	cfg := Default()
+	path, required := os.Getenv("CONFIG_FILE"), true
+	if path == "" {
+		path, required = DefaultFile, false
+	}
+	if err := cfg.readFile(path, required); err != nil {
+		return nil, err
+	}
+	if err := cfg.readEnv(); err != nil {
+		return nil, err
+	}
+	cfg.normalise()
+	if err := cfg.Validate(parts); err != nil {
 		return nil, err
 	}
+	return cfg, nil
+}
+
+func (c *Config) readFile(path string, required bool) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %v", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	return nil
+}
+
+type envVar struct {
+	name string
+	set  func(string) error
+}
+
+// readEnv applies every environment variable that is set.
+func (c *Config) readEnv() error {
+	vars := []envVar{
+		{"LISTEN_ADDR", setString(&c.Server.Addr)},
+		{"SHUTDOWN_TIMEOUT", setDuration(&c.Server.ShutdownTimeout)},
+		{"ADMIN_TOKEN", setString(&c.Server.AdminToken)},
+		{"DATABASE_URL", setString(&c.Database.URL)},
+		{"DB_MAX_OPEN_CONNS", setInt(&c.Database.MaxOpenConns)},
+		{"DB_MAX_IDLE_CONNS", setInt(&c.Database.MaxIdleConns)},
+		{"DB_CONN_MAX_LIFETIME", setDuration(&c.Database.ConnMaxLifetime)},
+		{"DB_QUERY_TIMEOUT", setDuration(&c.Database.QueryTimeout)},
+		{"STORAGE_BACKEND", setString(&c.Storage.Backend)},
+		{"SUPABASE_URL", setString(&c.Storage.URL)},
+		{"SUPABASE_SERVICE_ROLE_KEY", setString(&c.Storage.ServiceRoleKey)},
+		{"STORAGE_BUCKET", setString(&c.Storage.Bucket)},
+		{"WIKIDATA_QUERY_URL", setString(&c.Providers.Wikidata.QueryURL)},
+		{"LOG_FORMAT", setString(&c.Log.Format)},
+		{"LOG_LEVEL", setString(&c.Log.Level)},
+		{"SCORE_SOURCE_PRIORITY", setList(&c.Score.SourcePriority)},
+		{"SCORE_FRESHNESS", setDuration(&c.Score.Freshness)},
+	}
+	for prefix, p := range map[string]*Provider{
+		"API_SPORTS_": &c.Providers.APISports,
+		"RAPID_API_":  &c.Providers.RapidAPI,
+		"RUGBYDB_":    &c.Providers.RugbyDB,
+		"WIKIDATA_":   &c.Providers.Wikidata,
+		"ESPN_":       &c.Providers.ESPN,
+	} {
+		vars = append(vars,
+			envVar{prefix + "ENABLED", setBool(&p.Enabled)},
+			envVar{prefix + "BASE_URL", setString(&p.BaseURL)},
+			envVar{prefix + "KEY", setString(&p.APIKey)},
+			envVar{prefix + "TIMEOUT", setDuration(&p.Timeout)},
+			envVar{prefix + "CONCURRENCY", setInt(&p.Concurrency)},
+			envVar{prefix + "RATE_LIMIT", setFloat(&p.RateLimit)},
+			envVar{prefix + "RETRIES", setInt(&p.Retries)},
+			envVar{prefix + "RETRY_BUDGET", setDuration(&p.RetryBudget)},
+			envVar{prefix + "BREAKER_THRESHOLD", setInt(&p.BreakerThreshold)},
+			envVar{prefix + "BREAKER_COOLDOWN", setDuration(&p.BreakerCooldown)},
+		)
+	}
+
+	var errs []error
+	for _, v := range vars {
+		if value, ok := os.LookupEnv(v.name); ok {
+			if err := v.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %v", v.name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
 
-	// Set connection pool settings
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(25)
+func setString(dst *string) func(string) error {
+	return func(v string) error {
+		*dst = v
+		return nil
+	}
+}
 
-	return db, nil
+func setInt(dst *int) func(string) error {
+	return func(v string) error {
+		n, err := strconv.Atoi(v)
+		*dst = n
+		return err
+	}
+}
+
+func setFloat(dst *float64) func(string) error {
+	return func(v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		*dst = f
+		return err
+	}
+}
+
+func setBool(dst *bool) func(string) error {
+	return func(v string) error {
+		b, err := strconv.ParseBool(v)
+		*dst = b
+		return err
+	}
+}
+
+func setDuration(dst *time.Duration) func(string) error {
+	return func(v string) error {
+		d, err := time.ParseDuration(v)
+		*dst = d
+		return err
+	}
+}
+
+// setList reads a comma-separated list, dropping empty entries.
+func setList(dst *[]string) func(string) error {
+	return func(v string) error {
+		*dst = nil
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*dst = append(*dst, item)
+			}
+		}
+		return nil
+	}
+}
+
+func (c *Config) normalise() {
+	c.Storage.URL = strings.TrimSuffix(strings.TrimSuffix(c.Storage.URL, "/"), "/storage/v1/s3")
+	for _, p := range c.providers() {
+		p.BaseURL = strings.TrimSuffix(p.BaseURL, "/")
+	}
+	c.Log.Format = strings.ToLower(c.Log.Format)
+	if c.Log.Format == "" {
+		c.Log.Format = "text"
+		if os.Getenv("GIN_MODE") == "release" {
+			c.Log.Format = "json"
+		}
+	}
+}
+
+// providers lists each provider with the name its settings are keyed by.
+func (c *Config) providers() []namedProvider {
+	return []namedProvider{
+		{"api_sports", &c.Providers.APISports},
+		{"rapid_api", &c.Providers.RapidAPI},
+		{"rugbydb", &c.Providers.RugbyDB},
+		{"wikidata", &c.Providers.Wikidata},
+		{"espn", &c.Providers.ESPN},
+	}
+}
+
+type namedProvider struct {
+	name string
+	*Provider
+}
+
+// Validate reports every setting that is missing or out of range, skipping
+// the parts a command doesn't use, so a migration doesn't need provider keys.
+func (c *Config) Validate(parts Part) error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if parts&PartServer != 0 {
+		c.validateServer(fail)
+	}
+
+	if c.Database.URL == "" {
+		fail("database.url is required (DATABASE_URL)")
+	}
+	if c.Database.MaxOpenConns < 1 {
+		fail("database.max_open_conns must be at least 1")
+	}
+	if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		fail("database.max_idle_conns must be between 0 and max_open_conns")
+	}
+	if c.Database.ConnMaxLifetime < 0 {
+		fail("database.conn_max_lifetime must not be negative")
+	}
+	if c.Database.QueryTimeout <= 0 {
+		fail("database.query_timeout must be positive")
+	}
+
+	if parts&PartStorage != 0 {
+		c.validateStorage(fail)
+	}
+	if parts&PartProviders != 0 {
+		c.validateProviders(fail)
+	}
+
+	if c.Log.Format != "json" && c.Log.Format != "text" {
+		fail("log.format must be json or text, not %q", c.Log.Format)
+	}
+	if _, err := c.LogLevel(); err != nil {
+		fail("log.level: %v", err)
+	}
+
+	if c.Score.Freshness < 0 {
+		fail("score.freshness must not be negative")
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Config) validateServer(fail func(string, ...interface{})) {
+	if c.Server.Addr == "" {
+		fail("server.addr is required (LISTEN_ADDR)")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		fail("server.shutdown_timeout must be positive")
+	}
+	if c.Server.AdminToken != "" && len(c.Server.AdminToken) < MinAdminTokenLength {
+		fail("server.admin_token must be at least %d characters (ADMIN_TOKEN)", MinAdminTokenLength)
+	}
+}
+
+func (c *Config) validateStorage(fail func(string, ...interface{})) {
+	switch c.Storage.Backend {
+	case StorageNone:
+	case StorageSupabase:
+		if err := checkURL(c.Storage.URL); err != nil {
+			fail("storage.url (SUPABASE_URL): %v", err)
+		}
+		if c.Storage.ServiceRoleKey == "" {
+			fail("storage.service_role_key is required (SUPABASE_SERVICE_ROLE_KEY)")
+		}
+		if c.Storage.Bucket == "" {
+			fail("storage.bucket is required")
+		}
+	default:
+		fail("storage.backend must be %s or %s, not %q", StorageSupabase, StorageNone, c.Storage.Backend)
+	}
+}
+
+func (c *Config) validateProviders(fail func(string, ...interface{})) {
+	for _, p := range c.providers() {
+		if !p.Enabled {
+			continue
+		}
+		if err := checkURL(p.BaseURL); err != nil {
+			fail("providers.%s.base_url: %v", p.name, err)
+		}
+		if p.Timeout <= 0 {
+			fail("providers.%s.timeout must be positive", p.name)
+		}
+		if p.Concurrency < 1 {
+			fail("providers.%s.concurrency must be at least 1", p.name)
+		}
+		if p.RateLimit < 0 {
+			fail("providers.%s.rate_limit must not be negative", p.name)
+		}
+		if p.Retries < 0 {
+			fail("providers.%s.retries must not be negative", p.name)
+		}
+		if p.Retries > 0 && p.RetryBudget <= 0 {
+			fail("providers.%s.retry_budget must be positive while retries is set", p.name)
+		}
+		if p.BreakerThreshold < 1 {
+			fail("providers.%s.breaker_threshold must be at least 1", p.name)
+		}
+		if p.BreakerCooldown <= 0 {
+			fail("providers.%s.breaker_cooldown must be positive", p.name)
+		}
+	}
+	if c.Providers.APISports.Enabled && c.Providers.APISports.APIKey == "" {
+		fail("providers.api_sports.api_key is required while it is enabled (API_SPORTS_KEY)")
+	}
+	if c.Providers.RapidAPI.Enabled && c.Providers.RapidAPI.APIKey == "" {
+		fail("providers.rapid_api.api_key is required while it is enabled (RAPID_API_KEY)")
+	}
+	if c.Providers.Wikidata.Enabled {
+		if err := checkURL(c.Providers.Wikidata.QueryURL); err != nil {
+			fail("providers.wikidata.query_url: %v", err)
+		}
+	}
+}
+
+// LogLevel parses Log.Level.
+func (c *Config) LogLevel() (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(c.Log.Level))
+	return level, err
+}
+
+// Secrets returns the credentials set, for redaction from logs.
+func (c *Config) Secrets() []string {
+	secrets := []string{c.Storage.ServiceRoleKey, c.Server.AdminToken}
+	for _, p := range c.providers() {
+		secrets = append(secrets, p.APIKey)
+	}
+	if u, err := url.Parse(c.Database.URL); err == nil && u.User != nil {
+		if password, ok := u.User.Password(); ok {
+			secrets = append(secrets, password)
+		}
+	}
+	return secrets
+}
+
+func checkURL(raw string) error {
+	if raw == "" {
+		return errors.New("required")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an absolute http(s) URL", raw)
+	}
+	return nil
 }
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateParts(t *testing.T) {
+	// Default leaves storage and provider keys unset, as a database-only
+	// deployment would.
+	cfg := Default()
+	cfg.Database.URL = "postgres://localhost/rugby"
+	cfg.normalise()
+
+	tests := []struct {
+		parts Part
+		want  []string // Settings reported; none means valid
+	}{
+		{0, nil},
+		{PartServer, nil},
+		{PartStorage, []string{"storage.service_role_key"}},
+		{PartProviders, []string{"providers.api_sports.api_key", "providers.rapid_api.api_key"}},
+		{PartAll, []string{"storage.service_role_key", "providers.api_sports.api_key", "providers.rapid_api.api_key"}},
+	}
+	for _, tt := range tests {
+		err := cfg.Validate(tt.parts)
+		if len(tt.want) == 0 {
+			if err != nil {
+				t.Errorf("Validate(%b) = %v, want nil", tt.parts, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("Validate(%b) = nil, want errors for %v", tt.parts, tt.want)
+			continue
+		}
+		for _, setting := range tt.want {
+			if !strings.Contains(err.Error(), setting) {
+				t.Errorf("Validate(%b) = %v, want an error for %s", tt.parts, err, setting)
+			}
+		}
+	}
+
+	cfg.Server.AdminToken = "too short"
+	if err := cfg.Validate(PartServer); err == nil || !strings.Contains(err.Error(), "server.admin_token") {
+		t.Errorf("Validate(PartServer) with a short admin token = %v, want a server.admin_token error", err)
+	}
+	cfg.Server.AdminToken = ""
+
+	cfg.Database.URL = ""
+	if err := cfg.Validate(0); err == nil || !strings.Contains(err.Error(), "database.url") {
+		t.Errorf("Validate(0) without a database URL = %v, want a database.url error", err)
+	}
+}
@@ -0,0 +1,394 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"rugby-live-api/models"
+
+	"github.com/lib/pq"
+)
+
+// Batch readers take a set of keys and return every matching row in one query.
+// They back the GraphQL dataloaders, so callers should not rely on ordering.
+
+const leagueColumns = `
+        l.id, l.name, l.country_code, l.tier, l.format, l.phases,
+        l.alt_names, l.logo_url, l.team_countries, l.gender, l.logo_source,
+        l.international, l.parent_league_id, l.successor_league_id,
+        l.all_time, l.all_time_league_id, l.created_at, l.updated_at,
+        l.tourists, l.tourist_countries, l.hosts, l.host_countries`
+
+func scanLeague(rows *sql.Rows) (models.League, error) {
+	var league models.League
+	var countryCode, format, logoURL, gender, logoSource sql.NullString
+	var parentID, successorID, allTimeID sql.NullString
+	var tier sql.NullInt64
+	var allTime, international sql.NullBool
+	var teamCountries []string
+	var tour models.Tour
+
+	err := rows.Scan(
+		&league.ID,
+		&league.Name,
+		&countryCode,
+		&tier,
+		&format,
+		pq.Array(&league.Phases),
+		pq.Array(&league.AltNames),
+		&logoURL,
+		pq.Array(&teamCountries),
+		&gender,
+		&logoSource,
+		&international,
+		&parentID,
+		&successorID,
+		&allTime,
+		&allTimeID,
+		&league.CreatedAt,
+		&league.UpdatedAt,
+		pq.Array(&tour.Tourists),
+		pq.Array(&tour.TouristCountries),
+		pq.Array(&tour.Hosts),
+		pq.Array(&tour.HostCountries),
+	)
+	if err != nil {
+		return league, err
+	}
+
+	league.Country.Code = countryCode.String
+	league.Tier = int(tier.Int64)
+	league.Format = format.String
+	league.LogoURL = logoURL.String
+	league.Gender = gender.String
+	league.LogoSource = logoSource.String
+	league.International = international.Bool
+	league.AllTime = allTime.Bool
+	league.AllTimeID = allTimeID.String
+	if parentID.Valid {
+		league.ParentID = &parentID.String
+	}
+	if successorID.Valid {
+		league.SuccessorID = &successorID.String
+	}
+	for _, code := range teamCountries {
+		league.TeamCountries = append(league.TeamCountries, models.Country{Code: code})
+	}
+	if len(tour.Tourists) > 0 {
+		league.Tour = &tour
+	}
+	return league, nil
+}
+
+func (s *Store) queryLeagues(ctx context.Context, where string, args ...interface{}) ([]models.League, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.q.QueryContext(ctx, `SELECT `+leagueColumns+` FROM leagues l WHERE `+where+` ORDER BY l.name`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var leagues []models.League
+	for rows.Next() {
+		league, err := scanLeague(rows)
+		if err != nil {
+			return nil, err
+		}
+		leagues = append(leagues, league)
+	}
+	return leagues, rows.Err()
+}
+
+func (s *Store) GetLeaguesByIDs(ctx context.Context, ids []string) ([]models.League, error) {
+	return s.queryLeagues(ctx, `l.id = ANY($1)`, pq.Array(ids))
+}
+
+func (s *Store) GetLeaguesByParentIDs(ctx context.Context, parentIDs []string) ([]models.League, error) {
+	return s.queryLeagues(ctx, `l.parent_league_id = ANY($1)`, pq.Array(parentIDs))
+}
+
+// GetLeaguesBySuccessorIDs returns the leagues that were succeeded by any of
+// successorIDs.
+func (s *Store) GetLeaguesBySuccessorIDs(ctx context.Context, successorIDs []string) ([]models.League, error) {
+	return s.queryLeagues(ctx, `l.successor_league_id = ANY($1)`, pq.Array(successorIDs))
+}
+
+func (s *Store) GetLeaguesByCountryCodes(ctx context.Context, codes []string) ([]models.League, error) {
+	return s.queryLeagues(ctx, `l.country_code = ANY($1)`, pq.Array(codes))
+}
+
+func (s *Store) GetCountriesByCodes(ctx context.Context, codes []string) ([]models.Country, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.q.QueryContext(ctx, `
+        SELECT code, name, flag, created_at, updated_at
+        FROM countries
+        WHERE code = ANY($1)`, pq.Array(codes))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var countries []models.Country
+	for rows.Next() {
+		var c models.Country
+		var flag sql.NullString
+		if err := rows.Scan(&c.Code, &c.Name, &flag, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		c.Flag = flag.String
+		countries = append(countries, c)
+	}
+	return countries, rows.Err()
+}
+
+const seasonColumns = `
+        s.id, s.league_id, s.year, COALESCE(s.year_range, ''), COALESCE(s.current, false),
+        s.start_date, s.end_date, s.created_at, s.updated_at`
+
+func scanSeason(rows *sql.Rows) (models.Season, error) {
+	var season models.Season
+	var startDate, endDate sql.NullTime
+	err := rows.Scan(
+		&season.ID,
+		&season.LeagueID,
+		&season.Year,
+		&season.YearRange,
+		&season.Current,
+		&startDate,
+		&endDate,
+		&season.CreatedAt,
+		&season.UpdatedAt,
+	)
+	season.StartDate = startDate.Time
+	season.EndDate = endDate.Time
+	return season, err
+}
+
+func (s *Store) querySeasons(ctx context.Context, where string, args ...interface{}) ([]models.Season, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.q.QueryContext(ctx, `SELECT `+seasonColumns+` FROM seasons s WHERE `+where+` ORDER BY s.year DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var seasons []models.Season
+	for rows.Next() {
+		season, err := scanSeason(rows)
+		if err != nil {
+			return nil, err
+		}
+		seasons = append(seasons, season)
+	}
+	return seasons, rows.Err()
+}
+
+func (s *Store) GetSeasonsByIDs(ctx context.Context, ids []string) ([]models.Season, error) {
+	return s.querySeasons(ctx, `s.id = ANY($1)`, pq.Array(ids))
+}
+
+func (s *Store) GetSeasonsByLeagueIDs(ctx context.Context, leagueIDs []string) ([]models.Season, error) {
+	return s.querySeasons(ctx, `s.league_id = ANY($1)`, pq.Array(leagueIDs))
+}
+
+const teamColumns = `
+        t.id, t.name, t.logo_url, t.logo_source, t.country_code, t.alternate_names,
+        t.created_at, t.updated_at`
+
+func scanTeam(rows *sql.Rows) (models.Team, error) {
+	var team models.Team
+	var logoURL, logoSource sql.NullString
+	err := rows.Scan(
+		&team.ID,
+		&team.Name,
+		&logoURL,
+		&logoSource,
+		&team.Country.Code,
+		pq.Array(&team.AltNames),
+		&team.CreatedAt,
+		&team.UpdatedAt,
+	)
+	team.LogoURL = logoURL.String
+	team.LogoSource = logoSource.String
+	return team, err
+}
+
+func (s *Store) queryTeams(ctx context.Context, where string, args ...interface{}) ([]models.Team, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.q.QueryContext(ctx, `SELECT `+teamColumns+` FROM teams t WHERE `+where+` ORDER BY t.name`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var teams []models.Team
+	for rows.Next() {
+		team, err := scanTeam(rows)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, team)
+	}
+	return teams, rows.Err()
+}
+
+func (s *Store) GetTeamsByIDs(ctx context.Context, ids []string) ([]models.Team, error) {
+	return s.queryTeams(ctx, `t.id = ANY($1)`, pq.Array(ids))
+}
+
+func (s *Store) GetTeamsByCountryCodes(ctx context.Context, codes []string) ([]models.Team, error) {
+	return s.queryTeams(ctx, `t.country_code = ANY($1)`, pq.Array(codes))
+}
+
+// GetStadiumsByTeamIDs returns each team's stadiums keyed by team ID.
+func (s *Store) GetStadiumsByTeamIDs(ctx context.Context, teamIDs []string) (map[string][]models.TeamStadium, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.q.QueryContext(ctx, `
+        SELECT ts.team_id, ts.is_primary, ts.start_date, ts.end_date,
+               st.id, st.name, st.capacity, st.location, st.country_code, st.created_at, st.updated_at
+        FROM team_stadiums ts
+        JOIN stadiums st ON st.id = ts.stadium_id
+        WHERE ts.team_id = ANY($1)
+        ORDER BY ts.is_primary DESC, st.name`, pq.Array(teamIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stadiums := make(map[string][]models.TeamStadium)
+	for rows.Next() {
+		var teamID string
+		var ts models.TeamStadium
+		var startDate, endDate sql.NullTime
+		var capacity sql.NullInt64
+		var location, countryCode sql.NullString
+		if err := rows.Scan(
+			&teamID,
+			&ts.IsPrimary,
+			&startDate,
+			&endDate,
+			&ts.Stadium.ID,
+			&ts.Stadium.Name,
+			&capacity,
+			&location,
+			&countryCode,
+			&ts.Stadium.CreatedAt,
+			&ts.Stadium.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		ts.StartDate = startDate.Time
+		ts.EndDate = endDate.Time
+		ts.Stadium.Capacity = int(capacity.Int64)
+		ts.Stadium.Location = location.String
+		ts.Stadium.Country.Code = countryCode.String
+		stadiums[teamID] = append(stadiums[teamID], ts)
+	}
+	return stadiums, rows.Err()
+}
+
+const matchColumns = `
+        m.id, m.home_team_id, m.away_team_id, m.league_id, m.home_score, m.away_score,
+        m.status, m.kick_off, m.date, m.time, m.venue_timezone, m.venue,
+        m.score_source, m.score_conflict, m.created_at, m.updated_at`
+
+func scanMatch(rows *sql.Rows) (models.Match, error) {
+	var m models.Match
+	var venue, scoreSource sql.NullString
+	err := rows.Scan(
+		&m.ID,
+		&m.HomeTeamID,
+		&m.AwayTeamID,
+		&m.LeagueID,
+		&m.HomeScore,
+		&m.AwayScore,
+		&m.Status,
+		&m.KickOff,
+		&m.Date,
+		&m.Time,
+		&m.VenueTimezone,
+		&venue,
+		&scoreSource,
+		&m.ScoreConflict,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+	)
+	m.Venue = venue.String
+	m.ScoreSource = scoreSource.String
+	m.KickOff = m.KickOff.UTC()
+	return m, err
+}
+
+func (s *Store) queryMatches(ctx context.Context, where string, args ...interface{}) ([]models.Match, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.q.QueryContext(ctx, `SELECT `+matchColumns+` FROM matches m WHERE `+where+` ORDER BY m.kick_off, m.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []models.Match
+	for rows.Next() {
+		m, err := scanMatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+func (s *Store) GetMatchesByIDs(ctx context.Context, ids []string) ([]models.Match, error) {
+	return s.queryMatches(ctx, `m.id = ANY($1)`, pq.Array(ids))
+}
+
+// GetMatchesBySeasonIDs returns matches whose league_id column holds one of
+// the given season IDs.
+func (s *Store) GetMatchesBySeasonIDs(ctx context.Context, seasonIDs []string) ([]models.Match, error) {
+	return s.queryMatches(ctx, `m.league_id = ANY($1)`, pq.Array(seasonIDs))
+}
+
+func (s *Store) GetAPIMappingsByEntityIDs(ctx context.Context, entityType string, entityIDs []string) ([]models.APIMapping, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.q.QueryContext(ctx, `
+        SELECT id, entity_id, api_name, api_id, entity_type, COALESCE(is_active, false), created_at, updated_at
+        FROM api_mappings
+        WHERE entity_type = $1 AND entity_id = ANY($2)
+        ORDER BY api_name, api_id`, entityType, pq.Array(entityIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mappings []models.APIMapping
+	for rows.Next() {
+		var m models.APIMapping
+		if err := rows.Scan(
+			&m.ID,
+			&m.EntityID,
+			&m.APIName,
+			&m.APIID,
+			&m.EntityType,
+			&m.IsActive,
+			&m.CreatedAt,
+			&m.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, m)
+	}
+	return mappings, rows.Err()
+}
@@ -1,8 +1,12 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
+	"rugby-live-api/config"
+	"rugby-live-api/events"
 	"rugby-live-api/models"
 	"time"
 
@@ -11,16 +15,76 @@ import (
 )
 
 type Store struct {
-	DB *sqlx.DB
+	DB  *sqlx.DB
+	q   Querier
+	log *slog.Logger
+
+	// queryTimeout bounds each operation on top of the caller's context.
+	queryTimeout time.Duration
+
+	// pending holds events raised inside a transaction until it commits, and
+	// upserts the rows it has written, counted once it commits.
+	pending *[]events.Event
+	upserts map[string]int
 }
 
-func NewStore(db *sqlx.DB) *Store {
+// NewStore connects to the database cfg names, sizing the connection pool as
+// it says.
+func NewStore(cfg config.Database, logger *slog.Logger) (*Store, error) {
+	db, err := sqlx.Open("postgres", cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.QueryTimeout)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	return &Store{
-		DB: db,
+		DB:           db,
+		q:            db,
+		log:          logger,
+		queryTimeout: cfg.QueryTimeout,
+	}, nil
+}
+
+// Close closes the connection pool.
+func (s *Store) Close() error {
+	return s.DB.Close()
+}
+
+// Logger returns the logger the store was created with, for packages that
+// work through a store.
+func (s *Store) Logger() *slog.Logger {
+	return s.log
+}
+
+// withTimeout bounds one store operation by the query timeout. Operations
+// read their rows fully before returning, so the caller cancels on return.
+func (s *Store) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
 	}
+	return context.WithTimeout(ctx, s.queryTimeout)
+}
+
+// Ping checks the database can be reached.
+func (s *Store) Ping(ctx context.Context) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	return s.DB.PingContext(ctx)
 }
 
-func (s *Store) UpsertCountry(country *models.Country) error {
+func (s *Store) UpsertCountry(ctx context.Context, country *models.Country) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         INSERT INTO countries (code, name, flag, created_at, updated_at)
         VALUES ($1, $2, $3, $4, $4)
@@ -31,24 +95,30 @@ func (s *Store) UpsertCountry(country *models.Country) error {
             updated_at = EXCLUDED.updated_at
         RETURNING code`
 
-	return s.DB.QueryRow(
+	err := s.q.QueryRowContext(ctx,
 		query,
 		country.Code,
 		country.Name,
 		country.Flag,
 		time.Now(),
 	).Scan(&country.Code)
+	return s.upserted("country", 1, err)
 }
 
-func (s *Store) UpsertLeague(league *models.League) error {
+func (s *Store) UpsertLeague(ctx context.Context, league *models.League) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         INSERT INTO leagues (
             id, name, country_code, tier, format, phases, 
             alt_names, logo_url, team_countries, gender, 
-            logo_source, international, parent_league_id
+            logo_source, international, parent_league_id, successor_league_id,
+            tourists, tourist_countries, hosts, host_countries
         )
         VALUES (
-            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14,
+            $15, $16, $17, $18
         )
         ON CONFLICT (id) DO UPDATE SET
             name = EXCLUDED.name,
@@ -63,15 +133,25 @@ func (s *Store) UpsertLeague(league *models.League) error {
             logo_source = EXCLUDED.logo_source,
             international = EXCLUDED.international,
             parent_league_id = EXCLUDED.parent_league_id,
+            successor_league_id = COALESCE(EXCLUDED.successor_league_id, leagues.successor_league_id),
+            tourists = EXCLUDED.tourists,
+            tourist_countries = EXCLUDED.tourist_countries,
+            hosts = EXCLUDED.hosts,
+            host_countries = EXCLUDED.host_countries,
             updated_at = NOW()`
 
+	var tour models.Tour
+	if league.Tour != nil {
+		tour = *league.Tour
+	}
+
 	// Extract country codes from TeamCountries
 	countryCodes := make([]string, len(league.TeamCountries))
 	for i, country := range league.TeamCountries {
 		countryCodes[i] = country.Code
 	}
 
-	_, err := s.DB.Exec(query,
+	_, err := s.q.ExecContext(ctx, query,
 		league.ID,
 		league.Name,
 		league.Country.Code,
@@ -85,11 +165,19 @@ func (s *Store) UpsertLeague(league *models.League) error {
 		league.LogoSource,
 		league.International,
 		league.ParentID,
+		league.SuccessorID,
+		pq.Array(nonNil(tour.Tourists)),
+		pq.Array(nonNil(tour.TouristCountries)),
+		pq.Array(nonNil(tour.Hosts)),
+		pq.Array(nonNil(tour.HostCountries)),
 	)
-	return err
+	return s.upserted("league", 1, err)
 }
 
-func (s *Store) UpsertSeason(season *models.Season) error {
+func (s *Store) UpsertSeason(ctx context.Context, season *models.Season) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         INSERT INTO seasons (
             id, league_id, year, year_range, start_date, end_date
@@ -102,7 +190,7 @@ func (s *Store) UpsertSeason(season *models.Season) error {
             start_date = EXCLUDED.start_date,
             end_date = EXCLUDED.end_date
     `
-	_, err := s.DB.Exec(query,
+	_, err := s.q.ExecContext(ctx, query,
 		season.ID,
 		season.LeagueID,
 		season.Year,
@@ -110,12 +198,15 @@ func (s *Store) UpsertSeason(season *models.Season) error {
 		season.StartDate,
 		season.EndDate,
 	)
-	return err
+	return s.upserted("season", 1, err)
 }
 
-func (s *Store) UpsertTeam(team *models.Team) error {
+func (s *Store) UpsertTeam(ctx context.Context, team *models.Team) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	// First ensure the team exists
-	if err := s.UpsertCountry(&team.Country); err != nil {
+	if err := s.UpsertCountry(ctx, &team.Country); err != nil {
 		return fmt.Errorf("failed to upsert team country: %v", err)
 	}
 	// Convert string slice to Postgres array
@@ -126,7 +217,12 @@ func (s *Store) UpsertTeam(team *models.Team) error {
 		altNames = pq.Array([]string{})
 	}
 
+	// previous is read before the upsert runs, so the row returned says
+	// whether anything a consumer would see has changed.
 	query := `
+        WITH previous AS (
+            SELECT name, logo_url, logo_source, alternate_names FROM teams WHERE id = $1
+        )
         INSERT INTO teams (id, name, country_code, logo_url, logo_source, alternate_names, created_at, updated_at)
         VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
         ON CONFLICT (id)
@@ -136,9 +232,16 @@ func (s *Store) UpsertTeam(team *models.Team) error {
             logo_source = EXCLUDED.logo_source,
             alternate_names = EXCLUDED.alternate_names,
             updated_at = EXCLUDED.updated_at
-        RETURNING id`
-
-	return s.DB.QueryRow(
+        RETURNING id, NOT EXISTS (
+            SELECT 1 FROM previous
+            WHERE previous.name = $2
+              AND previous.logo_url IS NOT DISTINCT FROM $4
+              AND previous.logo_source IS NOT DISTINCT FROM $5
+              AND previous.alternate_names IS NOT DISTINCT FROM $6
+        )`
+
+	var changed bool
+	if err := s.q.QueryRowContext(ctx,
 		query,
 		team.ID,
 		team.Name,
@@ -147,25 +250,52 @@ func (s *Store) UpsertTeam(team *models.Team) error {
 		team.LogoSource,
 		altNames,
 		time.Now(),
-	).Scan(&team.ID)
+	).Scan(&team.ID, &changed); err != nil {
+		return err
+	}
+	if changed {
+		s.Publish(events.New(events.TeamUpdated, *team))
+	}
+	return s.upserted("team", 1, nil)
 }
 
-func (s *Store) UpsertMatch(match *models.Match) error {
+// UpsertMatch writes match, including its reconciled ScoreSource and
+// ScoreConflict.
+func (s *Store) UpsertMatch(ctx context.Context, match *models.Match) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	// Kick-offs are stored in UTC; Date and Time are derived from the UTC instant
+	kickOff := match.KickOff.UTC()
+	venueTimezone := match.VenueTimezone
+	if venueTimezone == "" {
+		venueTimezone = "UTC"
+	}
+
 	query := `
         INSERT INTO matches (
             id, home_team_id, away_team_id, league_id,
             home_score, away_score, status, kick_off,
-            date, time, created_at, updated_at
+            date, time, venue_timezone, venue, score_source, score_conflict,
+            created_at, updated_at
         ) VALUES (
-            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, now(), now()
+            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, ''), NULLIF($13, ''), $14,
+            now(), now()
         )
         ON CONFLICT (id) DO UPDATE SET
             home_score = EXCLUDED.home_score,
             away_score = EXCLUDED.away_score,
             status = EXCLUDED.status,
+            kick_off = EXCLUDED.kick_off,
+            date = EXCLUDED.date,
+            time = EXCLUDED.time,
+            venue_timezone = EXCLUDED.venue_timezone,
+            venue = COALESCE(EXCLUDED.venue, matches.venue),
+            score_source = EXCLUDED.score_source,
+            score_conflict = EXCLUDED.score_conflict,
             updated_at = EXCLUDED.updated_at`
 
-	_, err := s.DB.Exec(
+	_, err := s.q.ExecContext(ctx,
 		query,
 		match.ID,
 		match.HomeTeamID,
@@ -174,14 +304,36 @@ func (s *Store) UpsertMatch(match *models.Match) error {
 		match.HomeScore,
 		match.AwayScore,
 		match.Status,
-		match.KickOff,
-		match.Date,
-		match.Time,
+		kickOff,
+		kickOff.Format("2006-01-02"),
+		kickOff.Format("15:04"),
+		venueTimezone,
+		match.Venue,
+		match.ScoreSource,
+		match.ScoreConflict,
 	)
-	return err
+	return s.upserted("match", 1, err)
 }
 
-func (s *Store) UpsertMatchAPIMapping(mapping *models.MatchAPIMapping) error {
+// GetMatchesBetween returns matches kicking off in [from, to), ordered by kick-off.
+func (s *Store) GetMatchesBetween(ctx context.Context, from, to time.Time) ([]models.Match, error) {
+	return s.queryMatches(ctx, `m.kick_off >= $1 AND m.kick_off < $2`, from.UTC(), to.UTC())
+}
+
+// GetMatchesAround returns matches kicking off in [from, to) whose
+// league_id column holds one of leagueIDs or that involve one of teamIDs.
+func (s *Store) GetMatchesAround(ctx context.Context, leagueIDs, teamIDs []string, from, to time.Time) ([]models.Match, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	return s.queryMatches(ctx, `(m.league_id = ANY($1) OR m.home_team_id = ANY($2) OR m.away_team_id = ANY($2))
+        AND m.kick_off >= $3 AND m.kick_off < $4`, pq.Array(leagueIDs), pq.Array(teamIDs), from.UTC(), to.UTC())
+}
+
+func (s *Store) UpsertMatchAPIMapping(ctx context.Context, mapping *models.MatchAPIMapping) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         INSERT INTO api_mappings (entity_id, api_name, api_id, entity_type, created_at, updated_at)
         VALUES ($1, $2, $3, $4, $5, $5)
@@ -191,7 +343,7 @@ func (s *Store) UpsertMatchAPIMapping(mapping *models.MatchAPIMapping) error {
             updated_at = EXCLUDED.updated_at
         RETURNING id`
 
-	return s.DB.QueryRow(
+	err := s.q.QueryRowContext(ctx,
 		query,
 		mapping.MatchID,
 		mapping.APIName,
@@ -199,9 +351,13 @@ func (s *Store) UpsertMatchAPIMapping(mapping *models.MatchAPIMapping) error {
 		"match",
 		time.Now(),
 	).Scan(&mapping.ID)
+	return s.upserted("api_mapping", 1, err)
 }
 
-func (s *Store) UpsertAPIMapping(mapping *models.APIMapping) error {
+func (s *Store) UpsertAPIMapping(ctx context.Context, mapping *models.APIMapping) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         INSERT INTO api_mappings (api_name, api_id, entity_type, entity_id, created_at, updated_at)
         VALUES ($1, $2, $3, $4, $5, $5)
@@ -210,7 +366,7 @@ func (s *Store) UpsertAPIMapping(mapping *models.APIMapping) error {
             entity_id = EXCLUDED.entity_id,
             updated_at = EXCLUDED.updated_at`
 
-	_, err := s.DB.Exec(
+	_, err := s.q.ExecContext(ctx,
 		query,
 		mapping.APIName,
 		mapping.APIID,
@@ -218,16 +374,19 @@ func (s *Store) UpsertAPIMapping(mapping *models.APIMapping) error {
 		mapping.EntityID,
 		time.Now(),
 	)
-	return err
+	return s.upserted("api_mapping", 1, err)
 }
 
-func (s *Store) GetCountries() ([]models.Country, error) {
+func (s *Store) GetCountries(ctx context.Context) ([]models.Country, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT code, name, flag, created_at, updated_at 
         FROM countries 
         ORDER BY name`
 
-	rows, err := s.DB.Query(query)
+	rows, err := s.q.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -244,13 +403,16 @@ func (s *Store) GetCountries() ([]models.Country, error) {
 	return countries, nil
 }
 
-func (s *Store) GetAPIMappingsByEntityType(entityType string) ([]models.APIMapping, error) {
+func (s *Store) GetAPIMappingsByEntityType(ctx context.Context, entityType string) ([]models.APIMapping, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT entity_id, api_name, api_id, entity_type, created_at, updated_at
         FROM api_mappings
         WHERE entity_type = $1`
 
-	rows, err := s.DB.Query(query, entityType)
+	rows, err := s.q.QueryContext(ctx, query, entityType)
 	if err != nil {
 		return nil, err
 	}
@@ -267,7 +429,10 @@ func (s *Store) GetAPIMappingsByEntityType(entityType string) ([]models.APIMappi
 	return mappings, nil
 }
 
-func (s *Store) UpsertStadium(stadium *models.Stadium) error {
+func (s *Store) UpsertStadium(ctx context.Context, stadium *models.Stadium) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         INSERT INTO stadiums (
             id,
@@ -288,7 +453,7 @@ func (s *Store) UpsertStadium(stadium *models.Stadium) error {
             updated_at = EXCLUDED.updated_at
         RETURNING id`
 
-	return s.DB.QueryRow(
+	err := s.q.QueryRowContext(ctx,
 		query,
 		stadium.ID,
 		stadium.Name,
@@ -297,16 +462,20 @@ func (s *Store) UpsertStadium(stadium *models.Stadium) error {
 		stadium.Country.Code,
 		time.Now(),
 	).Scan(&stadium.ID)
+	return s.upserted("stadium", 1, err)
 }
 
-func (s *Store) GetCountryByCode(code string) (*models.Country, error) {
+func (s *Store) GetCountryByCode(ctx context.Context, code string) (*models.Country, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT code, name, flag, created_at, updated_at 
         FROM countries 
         WHERE code = $1`
 
 	var country models.Country
-	err := s.DB.QueryRow(query, code).Scan(
+	err := s.q.QueryRowContext(ctx, query, code).Scan(
 		&country.Code,
 		&country.Name,
 		&country.Flag,
@@ -322,12 +491,16 @@ func (s *Store) GetCountryByCode(code string) (*models.Country, error) {
 	return &country, nil
 }
 
-func (s *Store) GetLeagueByID(id string) (*models.League, error) {
+func (s *Store) GetLeagueByID(ctx context.Context, id string) (*models.League, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT l.id, l.name, l.country_code, l.tier, l.format, l.phases, 
                l.alt_names, l.logo_url, l.gender, l.logo_source, 
                l.international, l.parent_league_id, l.all_time, 
-               l.all_time_league_id, c.name as country_name, c.flag as country_flag
+               l.all_time_league_id, c.name as country_name, c.flag as country_flag,
+               l.tourists, l.tourist_countries, l.hosts, l.host_countries
         FROM leagues l
         LEFT JOIN countries c ON c.code = l.country_code
         WHERE l.id = $1`
@@ -338,8 +511,9 @@ func (s *Store) GetLeagueByID(id string) (*models.League, error) {
 	var countryFlag sql.NullString
 	var parentID sql.NullString
 	var allTimeID sql.NullString
+	var tour models.Tour
 
-	err := s.DB.QueryRow(query, id).Scan(
+	err := s.q.QueryRowContext(ctx, query, id).Scan(
 		&league.ID,
 		&league.Name,
 		&countryCode,
@@ -356,6 +530,10 @@ func (s *Store) GetLeagueByID(id string) (*models.League, error) {
 		&allTimeID,
 		&countryName,
 		&countryFlag,
+		pq.Array(&tour.Tourists),
+		pq.Array(&tour.TouristCountries),
+		pq.Array(&tour.Hosts),
+		pq.Array(&tour.HostCountries),
 	)
 
 	if err != nil {
@@ -378,17 +556,24 @@ func (s *Store) GetLeagueByID(id string) (*models.League, error) {
 		league.AllTimeID = allTimeID.String
 	}
 
+	if len(tour.Tourists) > 0 {
+		league.Tour = &tour
+	}
+
 	return &league, nil
 }
 
-func (s *Store) GetTeamByID(id string) (*models.Team, error) {
+func (s *Store) GetTeamByID(ctx context.Context, id string) (*models.Team, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT id, name, logo_url, logo_source, country_code, created_at, updated_at, alternate_names 
         FROM teams 
         WHERE id = $1`
 
 	var team models.Team
-	err := s.DB.QueryRow(query, id).Scan(
+	err := s.q.QueryRowContext(ctx, query, id).Scan(
 		&team.ID,
 		&team.Name,
 		&team.LogoURL,
@@ -406,14 +591,17 @@ func (s *Store) GetTeamByID(id string) (*models.Team, error) {
 	return &team, nil
 }
 
-func (s *Store) GetAPIMappingByAPIID(apiName string, apiID string, entityType string) (*models.APIMapping, error) {
+func (s *Store) GetAPIMappingByAPIID(ctx context.Context, apiName string, apiID string, entityType string) (*models.APIMapping, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT entity_id, api_name, api_id, entity_type, created_at, updated_at
         FROM api_mappings
         WHERE api_name = $1 AND api_id = $2 AND entity_type = $3`
 
 	var mapping models.APIMapping
-	err := s.DB.QueryRow(query, apiName, apiID, entityType).Scan(
+	err := s.q.QueryRowContext(ctx, query, apiName, apiID, entityType).Scan(
 		&mapping.EntityID,
 		&mapping.APIName,
 		&mapping.APIID,
@@ -427,7 +615,10 @@ func (s *Store) GetAPIMappingByAPIID(apiName string, apiID string, entityType st
 	return &mapping, nil
 }
 
-func (s *Store) UpsertTeamStadium(teamID string, stadium *models.TeamStadium) error {
+func (s *Store) UpsertTeamStadium(ctx context.Context, teamID string, stadium *models.TeamStadium) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         INSERT INTO team_stadiums (
             team_id,
@@ -446,7 +637,7 @@ func (s *Store) UpsertTeamStadium(teamID string, stadium *models.TeamStadium) er
             end_date = EXCLUDED.end_date,
             updated_at = EXCLUDED.updated_at`
 
-	_, err := s.DB.Exec(
+	_, err := s.q.ExecContext(ctx,
 		query,
 		teamID,
 		stadium.Stadium.ID,
@@ -455,10 +646,13 @@ func (s *Store) UpsertTeamStadium(teamID string, stadium *models.TeamStadium) er
 		stadium.EndDate,
 		time.Now(),
 	)
-	return err
+	return s.upserted("team_stadium", 1, err)
 }
 
-func (s *Store) GetAllTeams() ([]*models.Team, error) {
+func (s *Store) GetAllTeams(ctx context.Context) ([]*models.Team, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT t.id, t.name, t.logo_url, t.logo_source, t.created_at, t.updated_at,
         c.code as country_code, c.name as country_name, c.flag as country_flag
@@ -466,7 +660,7 @@ func (s *Store) GetAllTeams() ([]*models.Team, error) {
         JOIN countries c ON t.country_code = c.code
         ORDER BY t.name`
 
-	rows, err := s.DB.Query(query)
+	rows, err := s.q.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -494,11 +688,31 @@ func (s *Store) GetAllTeams() ([]*models.Team, error) {
 		team.LogoSource = logoSource.String
 		teams = append(teams, team)
 	}
-	fmt.Printf("Found %d teams\n", len(teams))
+	s.log.Debug("Loaded teams", "count", len(teams))
 	return teams, nil
 }
 
-func (s *Store) GetAPIMappingsByType(apiName string, entityType string) ([]models.APIMapping, error) {
+// GetTeamNames returns every team with its alternate names, for resolving
+// team names from files and feeds.
+func (s *Store) GetTeamNames(ctx context.Context) ([]models.Team, error) {
+	return s.queryTeams(ctx, `TRUE`)
+}
+
+// FindTeamsByName returns teams whose name or one of whose alternate names
+// equals name, ignoring case.
+func (s *Store) FindTeamsByName(ctx context.Context, name string) ([]models.Team, error) {
+	return s.queryTeams(ctx, `lower(t.name) = lower($1) OR lower($1) = ANY(SELECT lower(a) FROM unnest(t.alternate_names) a)`, name)
+}
+
+// GetLeagueNames returns every league with its alternate names.
+func (s *Store) GetLeagueNames(ctx context.Context) ([]models.League, error) {
+	return s.queryLeagues(ctx, `TRUE`)
+}
+
+func (s *Store) GetAPIMappingsByType(ctx context.Context, apiName string, entityType string) ([]models.APIMapping, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT 
 			id,
@@ -506,13 +720,14 @@ func (s *Store) GetAPIMappingsByType(apiName string, entityType string) ([]model
 			api_name,
 			api_id,
 			entity_type,
+			COALESCE(is_active, false),
 			created_at,
 			updated_at
 		FROM api_mappings 
 		WHERE api_name = $1 AND entity_type = $2
 	`
 	var mappings []models.APIMapping
-	rows, err := s.DB.Query(query, apiName, entityType)
+	rows, err := s.q.QueryContext(ctx, query, apiName, entityType)
 	if err != nil {
 		return nil, err
 	}
@@ -526,6 +741,7 @@ func (s *Store) GetAPIMappingsByType(apiName string, entityType string) ([]model
 			&m.APIName,
 			&m.APIID,
 			&m.EntityType,
+			&m.IsActive,
 			&m.CreatedAt,
 			&m.UpdatedAt,
 		)
@@ -537,7 +753,10 @@ func (s *Store) GetAPIMappingsByType(apiName string, entityType string) ([]model
 	return mappings, err
 }
 
-func (s *Store) GetTeamsByCountryCode(countryCode string) ([]*models.Team, error) {
+func (s *Store) GetTeamsByCountryCode(ctx context.Context, countryCode string) ([]*models.Team, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT t.id, t.name, t.logo_url, t.logo_source, t.created_at, t.updated_at,
         c.code as country_code, c.name as country_name, c.flag as country_flag
@@ -546,7 +765,7 @@ func (s *Store) GetTeamsByCountryCode(countryCode string) ([]*models.Team, error
         WHERE t.country_code = $1
         ORDER BY t.name`
 
-	rows, err := s.DB.Query(query, countryCode)
+	rows, err := s.q.QueryContext(ctx, query, countryCode)
 	if err != nil {
 		return nil, err
 	}
@@ -577,9 +796,12 @@ func (s *Store) GetTeamsByCountryCode(countryCode string) ([]*models.Team, error
 	return teams, nil
 }
 
-func (s *Store) GetCountryByName(name string) (*models.Country, error) {
+func (s *Store) GetCountryByName(ctx context.Context, name string) (*models.Country, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	var country models.Country
-	err := s.DB.QueryRow("SELECT code, name, flag FROM countries WHERE name = $1", name).Scan(
+	err := s.q.QueryRowContext(ctx, "SELECT code, name, flag FROM countries WHERE name = $1", name).Scan(
 		&country.Code,
 		&country.Name,
 		&country.Flag,
@@ -590,14 +812,17 @@ func (s *Store) GetCountryByName(name string) (*models.Country, error) {
 	return &country, nil
 }
 
-func (s *Store) GetSeasonByID(id string) (*models.Season, error) {
+func (s *Store) GetSeasonByID(ctx context.Context, id string) (*models.Season, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT id, league_id, year, current, start_date, end_date, created_at, updated_at
         FROM seasons 
         WHERE id = $1`
 
 	var season models.Season
-	err := s.DB.QueryRow(query, id).Scan(
+	err := s.q.QueryRowContext(ctx, query, id).Scan(
 		&season.ID,
 		&season.LeagueID,
 		&season.Year,
@@ -613,7 +838,10 @@ func (s *Store) GetSeasonByID(id string) (*models.Season, error) {
 	return &season, nil
 }
 
-func (s *Store) GetLeagueByName(name string) (*models.League, error) {
+func (s *Store) GetLeagueByName(ctx context.Context, name string) (*models.League, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT l.id, l.name, l.country_code, l.tier, l.format, l.phases, 
                l.alt_names, l.logo_url, l.logo_source, l.international, 
@@ -622,7 +850,7 @@ func (s *Store) GetLeagueByName(name string) (*models.League, error) {
         WHERE l.name = $1`
 
 	var league models.League
-	err := s.DB.QueryRow(query, name).Scan(
+	err := s.q.QueryRowContext(ctx, query, name).Scan(
 		&league.ID,
 		&league.Name,
 		&league.Country.Code,
@@ -643,7 +871,10 @@ func (s *Store) GetLeagueByName(name string) (*models.League, error) {
 	return &league, nil
 }
 
-func (s *Store) UpdateCurrentSeason(leagueID string) error {
+func (s *Store) UpdateCurrentSeason(ctx context.Context, leagueID string) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         WITH latest_season AS (
             SELECT id, year
@@ -659,11 +890,14 @@ func (s *Store) UpdateCurrentSeason(leagueID string) error {
         END
         WHERE league_id = $1
     `
-	_, err := s.DB.Exec(query, leagueID)
+	_, err := s.q.ExecContext(ctx, query, leagueID)
 	return err
 }
 
-func (s *Store) GetLeagueTransition(name string, year int) (*models.LeagueTransition, error) {
+func (s *Store) GetLeagueTransition(ctx context.Context, name string, year int) (*models.LeagueTransition, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT successor_id, transition_year, display_name 
         FROM league_transitions 
@@ -672,21 +906,51 @@ func (s *Store) GetLeagueTransition(name string, year int) (*models.LeagueTransi
         LIMIT 1`
 
 	var transition models.LeagueTransition
-	err := s.DB.QueryRow(query, name, year).Scan(&transition.SuccessorID, &transition.Year, &transition.DisplayName)
+	err := s.q.QueryRowContext(ctx, query, name, year).Scan(&transition.SuccessorID, &transition.Year, &transition.DisplayName)
 	if err != nil {
 		return nil, err
 	}
 	return &transition, nil
 }
 
-func (s *Store) GetAPIMappingByEntityID(apiName string, entityID string, entityType string) (*models.APIMapping, error) {
+// GetLeagueTransitionsBySuccessorIDs returns the renames into any of
+// successorIDs, earliest first.
+func (s *Store) GetLeagueTransitionsBySuccessorIDs(ctx context.Context, successorIDs []string) ([]models.LeagueTransition, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.q.QueryContext(ctx, `
+        SELECT old_name, successor_id, transition_year, display_name, COALESCE(renamed_to, '')
+        FROM league_transitions
+        WHERE successor_id = ANY($1)
+        ORDER BY transition_year, old_name`, pq.Array(successorIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transitions []models.LeagueTransition
+	for rows.Next() {
+		var t models.LeagueTransition
+		if err := rows.Scan(&t.OldName, &t.SuccessorID, &t.Year, &t.DisplayName, &t.RenamedTo); err != nil {
+			return nil, err
+		}
+		transitions = append(transitions, t)
+	}
+	return transitions, rows.Err()
+}
+
+func (s *Store) GetAPIMappingByEntityID(ctx context.Context, apiName string, entityID string, entityType string) (*models.APIMapping, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT entity_id, api_name, api_id, entity_type, created_at, updated_at
         FROM api_mappings
         WHERE api_name = $1 AND entity_id = $2 AND entity_type = $3`
 
 	var mapping models.APIMapping
-	err := s.DB.QueryRow(query, apiName, entityID, entityType).Scan(
+	err := s.q.QueryRowContext(ctx, query, apiName, entityID, entityType).Scan(
 		&mapping.EntityID,
 		&mapping.APIName,
 		&mapping.APIID,
@@ -700,14 +964,17 @@ func (s *Store) GetAPIMappingByEntityID(apiName string, entityID string, entityT
 	return &mapping, nil
 }
 
-func (s *Store) GetSeasonByLeagueAndYear(leagueID string, year string) (*models.Season, error) {
+func (s *Store) GetSeasonByLeagueAndYear(ctx context.Context, leagueID string, year string) (*models.Season, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT id, league_id, year, current, start_date, end_date, created_at, updated_at
         FROM seasons 
         WHERE league_id = $1 AND year = $2`
 
 	var season models.Season
-	err := s.DB.QueryRow(query, leagueID, year).Scan(
+	err := s.q.QueryRowContext(ctx, query, leagueID, year).Scan(
 		&season.ID,
 		&season.LeagueID,
 		&season.Year,
@@ -723,22 +990,31 @@ func (s *Store) GetSeasonByLeagueAndYear(leagueID string, year string) (*models.
 	return &season, nil
 }
 
-func (s *Store) UpsertDailyMatches(date string, matchIDs []string) error {
+// UpsertDailyMatches merges matchIDs into the index for date, a UTC calendar day.
+func (s *Store) UpsertDailyMatches(ctx context.Context, date string, matchIDs []string) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         INSERT INTO daily_matches (date, match_ids, updated_at)
         VALUES ($1, $2, now())
         ON CONFLICT (date) 
         DO UPDATE SET 
-            match_ids = array_cat(daily_matches.match_ids, EXCLUDED.match_ids),
+            match_ids = ARRAY(
+                SELECT DISTINCT unnest(array_cat(daily_matches.match_ids, EXCLUDED.match_ids))
+            ),
             updated_at = now()`
 
-	_, err := s.DB.Exec(query, date, pq.Array(matchIDs))
+	_, err := s.q.ExecContext(ctx, query, date, pq.Array(matchIDs))
 	return err
 }
 
-func (s *Store) GetSeasonByYear(leagueID string, year int) (*models.Season, error) {
+func (s *Store) GetSeasonByYear(ctx context.Context, leagueID string, year int) (*models.Season, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	var season models.Season
-	err := s.DB.Get(&season, `
+	err := s.q.GetContext(ctx, &season, `
         SELECT * FROM seasons 
         WHERE league_id = $1 AND year = $2
     `, leagueID, year)
@@ -748,18 +1024,36 @@ func (s *Store) GetSeasonByYear(leagueID string, year int) (*models.Season, erro
 	return &season, nil
 }
 
-func (s *Store) UpsertRapidAPIMapping(mapping *models.APIMapping) error {
+// UpsertRapidAPIMapping records a rugby-live-data mapping. IsActive decides
+// whether the competition is polled for fixtures, so it is refreshed on every
+// upsert.
+func (s *Store) UpsertRapidAPIMapping(ctx context.Context, mapping *models.APIMapping) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query := `
-        INSERT INTO api_mappings (api_name, api_id, entity_id, entity_type, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, NOW(), NOW())
+        INSERT INTO api_mappings (api_name, api_id, entity_id, entity_type, is_active, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
         ON CONFLICT (api_name, api_id, entity_id, entity_type)
-        DO NOTHING
+        DO UPDATE SET
+            is_active = EXCLUDED.is_active,
+            updated_at = NOW()
     `
-	_, err := s.DB.Exec(query,
+	_, err := s.q.ExecContext(ctx, query,
 		mapping.APIName,
 		mapping.APIID,
 		mapping.EntityID,
 		mapping.EntityType,
+		mapping.IsActive,
 	)
-	return err
+	return s.upserted("api_mapping", 1, err)
+}
+
+// nonNil returns values, or an empty slice for nil, which pq.Array would
+// write as NULL.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
 }
@@ -0,0 +1,317 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"rugby-live-api/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// ErrInvalidListOptions is returned (wrapped) for an unknown sort key or a
+// cursor that cannot be decoded or was issued for another sort, so handlers
+// can answer 400 instead of 500.
+var ErrInvalidListOptions = errors.New("invalid list options")
+
+// Page is the envelope returned by every list query. NextCursor is empty on
+// the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ListOptions are the paging, sorting and search options shared by every
+// list query. Sort names a sort key, prefixed with "-" for descending order.
+type ListOptions struct {
+	Limit  int
+	Cursor string
+	Sort   string
+	Search string
+}
+
+type TeamFilter struct {
+	ListOptions
+	CountryCode string
+}
+
+type LeagueFilter struct {
+	ListOptions
+	CountryCode   string
+	Gender        string
+	Tier          *int
+	International *bool
+	AllTime       *bool
+	ParentID      string
+	Tourist       string // Country code of a touring side
+	Host          string // Country code of a host
+}
+
+type SeasonFilter struct {
+	ListOptions
+	LeagueID string
+	Year     *int
+	Current  *bool
+}
+
+// MatchFilter selects matches. LeagueID matches the matches.league_id column,
+// which holds a season ID for scraped fixtures and a league ID for API Sports
+// ones.
+type MatchFilter struct {
+	ListOptions
+	LeagueID string
+	TeamID   string
+	Status   models.MatchStatus
+	From     time.Time
+	To       time.Time
+}
+
+// cursor is the keyset position after the last row of a page. Sort is the
+// sort the page was listed with; the value only means anything under it.
+type cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor decodes a cursor issued for sortName.
+func decodeCursor(s, sortName string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidListOptions)
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidListOptions)
+	}
+	if c.Sort != sortName {
+		return nil, fmt.Errorf("%w: cursor was issued for sort %q, not %q", ErrInvalidListOptions, c.Sort, sortName)
+	}
+	return &c, nil
+}
+
+// sortKey describes a column a list can be ordered by. cast is the SQL type
+// the cursor value is compared as, and value renders a row's sort value.
+type sortKey[T any] struct {
+	column string
+	cast   string
+	value  func(T) string
+}
+
+// listQuery accumulates WHERE conditions; "?" in a condition is replaced by
+// the next positional parameter.
+type listQuery struct {
+	where []string
+	args  []interface{}
+}
+
+func (q *listQuery) add(cond string, args ...interface{}) {
+	for _, arg := range args {
+		q.args = append(q.args, arg)
+		cond = strings.Replace(cond, "?", "$"+strconv.Itoa(len(q.args)), 1)
+	}
+	q.where = append(q.where, cond)
+}
+
+func (q *listQuery) search(expr, term string) {
+	if term = strings.TrimSpace(term); term != "" {
+		q.add(expr+" ILIKE ?", "%"+escapeLike(strings.ToLower(term))+"%")
+	}
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// listPage runs a keyset-paginated query. from is the FROM clause including
+// the table alias, and idColumn the alias-qualified primary key.
+func listPage[T any](
+	ctx context.Context,
+	s *Store,
+	columns, from, idColumn string,
+	q *listQuery,
+	opts ListOptions,
+	keys map[string]sortKey[T],
+	defaultSort string,
+	scan func(*sql.Rows) (T, error),
+	id func(T) string,
+) (Page[T], error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	page := Page[T]{Items: []T{}}
+
+	sortName := opts.Sort
+	if sortName == "" {
+		sortName = defaultSort
+	}
+	desc := strings.HasPrefix(sortName, "-")
+	key, ok := keys[strings.TrimPrefix(sortName, "-")]
+	if !ok {
+		return page, fmt.Errorf("%w: unknown sort %q", ErrInvalidListOptions, sortName)
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	direction, compare := "ASC", ">"
+	if desc {
+		direction, compare = "DESC", "<"
+	}
+
+	if opts.Cursor != "" {
+		after, err := decodeCursor(opts.Cursor, sortName)
+		if err != nil {
+			return page, err
+		}
+		q.add(fmt.Sprintf("(%s, %s) %s (?::%s, ?)", key.column, idColumn, compare, key.cast), after.Value, after.ID)
+	}
+
+	query := `SELECT ` + columns + ` FROM ` + from
+	if len(q.where) > 0 {
+		query += ` WHERE ` + strings.Join(q.where, " AND ")
+	}
+	query += fmt.Sprintf(` ORDER BY %s %s, %s %s LIMIT %d`, key.column, direction, idColumn, direction, limit+1)
+
+	rows, err := s.q.QueryContext(ctx, query, q.args...)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return page, err
+		}
+		page.Items = append(page.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return page, err
+	}
+
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = encodeCursor(cursor{Sort: sortName, Value: key.value(last), ID: id(last)})
+	}
+	return page, nil
+}
+
+var teamSortKeys = map[string]sortKey[models.Team]{
+	"name":       {column: "t.name", cast: "text", value: func(t models.Team) string { return t.Name }},
+	"created_at": {column: "t.created_at", cast: "timestamptz", value: func(t models.Team) string { return t.CreatedAt.Format(time.RFC3339Nano) }},
+}
+
+// ListTeams returns a page of teams, searching name and alternate names.
+func (s *Store) ListTeams(ctx context.Context, f TeamFilter) (Page[models.Team], error) {
+	q := &listQuery{}
+	if f.CountryCode != "" {
+		q.add("t.country_code = ?", f.CountryCode)
+	}
+	q.search("search_names(t.name, t.alternate_names)", f.Search)
+	return listPage(ctx, s, teamColumns, "teams t", "t.id", q, f.ListOptions, teamSortKeys, "name", scanTeam,
+		func(t models.Team) string { return t.ID })
+}
+
+var leagueSortKeys = map[string]sortKey[models.League]{
+	"name":       {column: "l.name", cast: "text", value: func(l models.League) string { return l.Name }},
+	"tier":       {column: "COALESCE(l.tier, 0)", cast: "int", value: func(l models.League) string { return strconv.Itoa(l.Tier) }},
+	"created_at": {column: "l.created_at", cast: "timestamptz", value: func(l models.League) string { return l.CreatedAt.Format(time.RFC3339Nano) }},
+}
+
+// ListLeagues returns a page of leagues, searching name and alt names.
+func (s *Store) ListLeagues(ctx context.Context, f LeagueFilter) (Page[models.League], error) {
+	q := &listQuery{}
+	if f.CountryCode != "" {
+		q.add("l.country_code = ?", f.CountryCode)
+	}
+	if f.Gender != "" {
+		q.add("l.gender = ?", f.Gender)
+	}
+	if f.Tier != nil {
+		q.add("l.tier = ?", *f.Tier)
+	}
+	if f.International != nil {
+		q.add("COALESCE(l.international, false) = ?", *f.International)
+	}
+	if f.AllTime != nil {
+		q.add("l.all_time = ?", *f.AllTime)
+	}
+	if f.ParentID != "" {
+		q.add("l.parent_league_id = ?", f.ParentID)
+	}
+	if f.Tourist != "" {
+		q.add("l.tourist_countries @> ARRAY[?::text]", f.Tourist)
+	}
+	if f.Host != "" {
+		q.add("l.host_countries @> ARRAY[?::text]", f.Host)
+	}
+	q.search("search_names(l.name, l.alt_names)", f.Search)
+	return listPage(ctx, s, leagueColumns, "leagues l", "l.id", q, f.ListOptions, leagueSortKeys, "name", scanLeague,
+		func(l models.League) string { return l.ID })
+}
+
+var seasonSortKeys = map[string]sortKey[models.Season]{
+	"year":       {column: "s.year", cast: "int", value: func(season models.Season) string { return strconv.Itoa(season.Year) }},
+	"created_at": {column: "s.created_at", cast: "timestamptz", value: func(season models.Season) string { return season.CreatedAt.Format(time.RFC3339Nano) }},
+}
+
+func (s *Store) ListSeasons(ctx context.Context, f SeasonFilter) (Page[models.Season], error) {
+	q := &listQuery{}
+	if f.LeagueID != "" {
+		q.add("s.league_id = ?", f.LeagueID)
+	}
+	if f.Year != nil {
+		q.add("s.year = ?", *f.Year)
+	}
+	if f.Current != nil {
+		q.add("COALESCE(s.current, false) = ?", *f.Current)
+	}
+	return listPage(ctx, s, seasonColumns, "seasons s", "s.id", q, f.ListOptions, seasonSortKeys, "-year", scanSeason,
+		func(season models.Season) string { return season.ID })
+}
+
+var matchSortKeys = map[string]sortKey[models.Match]{
+	"kick_off": {column: "m.kick_off", cast: "timestamptz", value: func(m models.Match) string { return m.KickOff.Format(time.RFC3339Nano) }},
+}
+
+func (s *Store) ListMatches(ctx context.Context, f MatchFilter) (Page[models.Match], error) {
+	q := &listQuery{}
+	if f.LeagueID != "" {
+		q.add("m.league_id = ?", f.LeagueID)
+	}
+	if f.TeamID != "" {
+		q.add("(m.home_team_id = ? OR m.away_team_id = ?)", f.TeamID, f.TeamID)
+	}
+	if f.Status != "" {
+		q.add("m.status = ?", f.Status)
+	}
+	if !f.From.IsZero() {
+		q.add("m.kick_off >= ?", f.From.UTC())
+	}
+	if !f.To.IsZero() {
+		q.add("m.kick_off < ?", f.To.UTC())
+	}
+	return listPage(ctx, s, matchColumns, "matches m", "m.id", q, f.ListOptions, matchSortKeys, "kick_off", scanMatch,
+		func(m models.Match) string { return m.ID })
+}
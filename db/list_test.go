@@ -0,0 +1,42 @@
+package db
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []cursor{
+		{Sort: "name", Value: "Leinster", ID: "team-1"},
+		{Sort: "-kick_off", Value: "2025-03-01T15:00:00Z", ID: "match-1"},
+		{Sort: "tier", Value: "0", ID: ""},
+	}
+	for _, want := range tests {
+		got, err := decodeCursor(encodeCursor(want), want.Sort)
+		if err != nil {
+			t.Errorf("decodeCursor(encodeCursor(%+v)) failed: %v", want, err)
+			continue
+		}
+		if *got != want {
+			t.Errorf("decodeCursor(encodeCursor(%+v)) = %+v", want, *got)
+		}
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	tests := []struct {
+		name, cursor, sort string
+	}{
+		{"not base64", "!!!", "name"},
+		{"not JSON", base64.RawURLEncoding.EncodeToString([]byte("name:x")), "name"},
+		{"other sort", encodeCursor(cursor{Sort: "name", Value: "Leinster", ID: "team-1"}), "created_at"},
+		{"other direction", encodeCursor(cursor{Sort: "kick_off", Value: "2025-03-01T15:00:00Z", ID: "m"}), "-kick_off"},
+		{"no sort", base64.RawURLEncoding.EncodeToString([]byte(`{"v":"1","id":"x"}`)), "year"},
+	}
+	for _, tt := range tests {
+		if _, err := decodeCursor(tt.cursor, tt.sort); !errors.Is(err, ErrInvalidListOptions) {
+			t.Errorf("%s: decodeCursor error = %v, want ErrInvalidListOptions", tt.name, err)
+		}
+	}
+}
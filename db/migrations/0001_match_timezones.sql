@@ -0,0 +1,16 @@
+-- Kick-offs are stored as UTC instants with the venue timezone alongside.
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'matches'
+          AND column_name = 'kick_off'
+          AND data_type = 'timestamp without time zone'
+    ) THEN
+        ALTER TABLE matches ALTER COLUMN kick_off TYPE TIMESTAMPTZ USING kick_off AT TIME ZONE 'UTC';
+    END IF;
+END $$;
+
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS venue_timezone TEXT NOT NULL DEFAULT 'UTC';
+
+CREATE INDEX IF NOT EXISTS matches_kick_off_idx ON matches (kick_off);
@@ -0,0 +1,10 @@
+-- Columns read by the GraphQL schema that older databases may lack.
+ALTER TABLE leagues ADD COLUMN IF NOT EXISTS successor_league_id TEXT;
+ALTER TABLE leagues ADD COLUMN IF NOT EXISTS all_time BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE leagues ADD COLUMN IF NOT EXISTS all_time_league_id TEXT;
+ALTER TABLE api_mappings ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS leagues_parent_league_id_idx ON leagues (parent_league_id);
+CREATE INDEX IF NOT EXISTS seasons_league_id_idx ON seasons (league_id);
+CREATE INDEX IF NOT EXISTS matches_league_id_idx ON matches (league_id);
+CREATE INDEX IF NOT EXISTS api_mappings_entity_idx ON api_mappings (entity_type, entity_id);
@@ -0,0 +1,18 @@
+-- Trigram indexes backing name search on the list endpoints. Search matches a
+-- row's name and alternate names together, through an IMMUTABLE wrapper since
+-- array_to_string is only STABLE and cannot be indexed directly.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE OR REPLACE FUNCTION search_names(name TEXT, alt_names TEXT[])
+RETURNS TEXT
+LANGUAGE sql IMMUTABLE PARALLEL SAFE
+AS $$ SELECT lower(name || ' ' || array_to_string(COALESCE(alt_names, '{}'::TEXT[]), ' ')) $$;
+
+CREATE INDEX IF NOT EXISTS leagues_search_idx ON leagues USING gin (search_names(name, alt_names) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS teams_search_idx ON teams USING gin (search_names(name, alternate_names) gin_trgm_ops);
+
+-- Keyset pagination orders by (sort column, id).
+CREATE INDEX IF NOT EXISTS leagues_name_id_idx ON leagues (name, id);
+CREATE INDEX IF NOT EXISTS teams_name_id_idx ON teams (name, id);
+CREATE INDEX IF NOT EXISTS seasons_year_id_idx ON seasons (year, id);
+CREATE INDEX IF NOT EXISTS matches_kick_off_id_idx ON matches (kick_off, id);
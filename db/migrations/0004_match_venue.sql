@@ -0,0 +1,2 @@
+-- Venue as named by the source, for fixtures scraped without a stadium ID.
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS venue TEXT;
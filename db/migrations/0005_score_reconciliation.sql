@@ -0,0 +1,25 @@
+-- Each provider's latest report for a match. The matches row holds whichever
+-- report won reconciliation, or a manual override.
+CREATE TABLE IF NOT EXISTS match_source_reports (
+    match_id TEXT NOT NULL REFERENCES matches (id) ON DELETE CASCADE,
+    source TEXT NOT NULL,
+    home_score INTEGER NOT NULL,
+    away_score INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    reported_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (match_id, source)
+);
+
+CREATE TABLE IF NOT EXISTS match_score_overrides (
+    match_id TEXT PRIMARY KEY REFERENCES matches (id) ON DELETE CASCADE,
+    home_score INTEGER NOT NULL,
+    away_score INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS score_source TEXT;
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS score_conflict BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS matches_score_conflict_idx ON matches (id) WHERE score_conflict;
@@ -0,0 +1,58 @@
+-- Statuses were free text: API-Sports' long description, or "upcoming",
+-- "live" and "finished". Map every stored value onto the enumerated set in
+-- models.MatchStatus; anything unrecognised is treated as scheduled.
+CREATE FUNCTION pg_temp.normalise_match_status(status TEXT) RETURNS TEXT AS $$
+    SELECT CASE lower(trim(status))
+        WHEN 'first_half' THEN 'first_half'
+        WHEN 'first half' THEN 'first_half'
+        WHEN '1h' THEN 'first_half'
+        WHEN 'in play' THEN 'first_half'
+        WHEN 'live' THEN 'first_half'
+        WHEN 'half_time' THEN 'half_time'
+        WHEN 'half time' THEN 'half_time'
+        WHEN 'halftime' THEN 'half_time'
+        WHEN 'ht' THEN 'half_time'
+        WHEN 'second_half' THEN 'second_half'
+        WHEN 'second half' THEN 'second_half'
+        WHEN '2h' THEN 'second_half'
+        WHEN 'extra_time' THEN 'extra_time'
+        WHEN 'extra time' THEN 'extra_time'
+        WHEN 'break time' THEN 'extra_time'
+        WHEN 'penalties time' THEN 'extra_time'
+        WHEN 'finished' THEN 'finished'
+        WHEN 'match finished' THEN 'finished'
+        WHEN 'after extra time' THEN 'finished'
+        WHEN 'awarded' THEN 'finished'
+        WHEN 'ft' THEN 'finished'
+        WHEN 'postponed' THEN 'postponed'
+        WHEN 'abandoned' THEN 'abandoned'
+        WHEN 'interrupted' THEN 'abandoned'
+        WHEN 'cancelled' THEN 'cancelled'
+        WHEN 'canceled' THEN 'cancelled'
+        ELSE 'scheduled'
+    END
+$$ LANGUAGE SQL IMMUTABLE;
+
+UPDATE matches SET status = pg_temp.normalise_match_status(status);
+UPDATE match_source_reports SET status = pg_temp.normalise_match_status(status);
+UPDATE match_score_overrides SET status = pg_temp.normalise_match_status(status);
+
+ALTER TABLE matches DROP CONSTRAINT IF EXISTS matches_status_check;
+ALTER TABLE matches ADD CONSTRAINT matches_status_check CHECK (status IN (
+    'scheduled', 'first_half', 'half_time', 'second_half', 'extra_time',
+    'finished', 'postponed', 'abandoned', 'cancelled'
+));
+
+-- Every status change a match goes through, recorded as reconciliation
+-- applies it. from_status is NULL for the status a match was first stored
+-- with.
+CREATE TABLE IF NOT EXISTS match_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    match_id TEXT NOT NULL REFERENCES matches (id) ON DELETE CASCADE,
+    from_status TEXT,
+    to_status TEXT NOT NULL,
+    source TEXT NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS match_status_history_match_idx ON match_status_history (match_id, changed_at);
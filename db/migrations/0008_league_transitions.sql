@@ -0,0 +1,37 @@
+-- Renames read by GetLeagueTransition and the league lineage endpoint. Older
+-- databases may lack the table; display_name is what to show for seasons
+-- before transition_year.
+CREATE TABLE IF NOT EXISTS league_transitions (
+    old_name TEXT NOT NULL,
+    successor_id TEXT NOT NULL,
+    transition_year INTEGER NOT NULL,
+    display_name TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS league_transitions_successor_id_idx ON league_transitions (successor_id);
+CREATE INDEX IF NOT EXISTS league_transitions_old_name_idx ON league_transitions (old_name);
+
+-- The renames in rugbydb.LeagueSuccessors, plus the URC's. League IDs are
+-- built from a country code on import, so the URC is looked up by name.
+INSERT INTO league_transitions (old_name, successor_id, transition_year, display_name)
+SELECT v.old_name, v.successor_id, v.transition_year, v.display_name
+FROM (VALUES
+    ('Tri Nations', 'WLD-THE-RUGBY-CHAMPIONSHIP', 2012, 'Tri Nations'),
+    ('November Internationals', 'WLD-AUTUMN-NATIONS-SERIES', 2020, 'November Internationals')
+) AS v (old_name, successor_id, transition_year, display_name)
+WHERE NOT EXISTS (
+    SELECT 1 FROM league_transitions t
+    WHERE t.old_name = v.old_name AND t.successor_id = v.successor_id
+);
+
+INSERT INTO league_transitions (old_name, successor_id, transition_year, display_name)
+SELECT v.old_name, l.id, v.transition_year, v.display_name
+FROM (VALUES
+    ('Pro12', 2017, 'Pro12'),
+    ('Pro14', 2021, 'Pro14')
+) AS v (old_name, transition_year, display_name)
+JOIN leagues l ON l.name = 'United Rugby Championship'
+WHERE NOT EXISTS (
+    SELECT 1 FROM league_transitions t
+    WHERE t.old_name = v.old_name AND t.successor_id = l.id
+);
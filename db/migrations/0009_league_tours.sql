@@ -0,0 +1,9 @@
+-- Tours name who is touring and where, read by the tourist and host filters
+-- of the league list. Empty for leagues that aren't tours.
+ALTER TABLE leagues ADD COLUMN IF NOT EXISTS tourists TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE leagues ADD COLUMN IF NOT EXISTS tourist_countries TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE leagues ADD COLUMN IF NOT EXISTS hosts TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE leagues ADD COLUMN IF NOT EXISTS host_countries TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS leagues_tourist_countries_idx ON leagues USING GIN (tourist_countries);
+CREATE INDEX IF NOT EXISTS leagues_host_countries_idx ON leagues USING GIN (host_countries);
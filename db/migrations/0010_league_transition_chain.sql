@@ -0,0 +1,14 @@
+-- renamed_to is the name a competition took when it dropped old_name, for
+-- renames into a name that has no league of its own. NULL means it was
+-- renamed straight to the successor league.
+ALTER TABLE league_transitions ADD COLUMN IF NOT EXISTS renamed_to TEXT;
+
+-- The Pro12 became the Pro14, not the URC: chain it through the Pro14 so the
+-- lineage reads Pro12 → Pro14 → URC.
+UPDATE league_transitions t
+SET renamed_to = 'Pro14'
+FROM leagues l
+WHERE l.name = 'United Rugby Championship'
+    AND t.successor_id = l.id
+    AND t.old_name = 'Pro12'
+    AND t.renamed_to IS NULL;
@@ -0,0 +1,208 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"rugby-live-api/models"
+	"slices"
+
+	"github.com/lib/pq"
+)
+
+// UpsertSourceReport replaces a provider's report for a match.
+func (s *Store) UpsertSourceReport(ctx context.Context, report *models.SourceReport) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+        INSERT INTO match_source_reports (match_id, source, home_score, away_score, status, reported_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (match_id, source) DO UPDATE SET
+            home_score = EXCLUDED.home_score,
+            away_score = EXCLUDED.away_score,
+            status = EXCLUDED.status,
+            reported_at = EXCLUDED.reported_at`
+
+	_, err := s.q.ExecContext(ctx, query,
+		report.MatchID,
+		report.Source,
+		report.HomeScore,
+		report.AwayScore,
+		report.Status,
+		report.ReportedAt,
+	)
+	return err
+}
+
+// GetSourceReportsByMatchIDs returns every provider report for the given
+// matches, keyed by match ID.
+func (s *Store) GetSourceReportsByMatchIDs(ctx context.Context, matchIDs []string) (map[string][]models.SourceReport, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.q.QueryContext(ctx, `
+        SELECT match_id, source, home_score, away_score, status, reported_at
+        FROM match_source_reports
+        WHERE match_id = ANY($1)
+        ORDER BY match_id, source`, pq.Array(matchIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := make(map[string][]models.SourceReport)
+	for rows.Next() {
+		var r models.SourceReport
+		if err := rows.Scan(&r.MatchID, &r.Source, &r.HomeScore, &r.AwayScore, &r.Status, &r.ReportedAt); err != nil {
+			return nil, err
+		}
+		reports[r.MatchID] = append(reports[r.MatchID], r)
+	}
+	return reports, rows.Err()
+}
+
+func (s *Store) UpsertScoreOverride(ctx context.Context, override *models.ScoreOverride) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+        INSERT INTO match_score_overrides (match_id, home_score, away_score, status, note, created_at)
+        VALUES ($1, $2, $3, $4, $5, now())
+        ON CONFLICT (match_id) DO UPDATE SET
+            home_score = EXCLUDED.home_score,
+            away_score = EXCLUDED.away_score,
+            status = EXCLUDED.status,
+            note = EXCLUDED.note,
+            created_at = EXCLUDED.created_at
+        RETURNING created_at`
+
+	return s.q.QueryRowContext(ctx, query,
+		override.MatchID,
+		override.HomeScore,
+		override.AwayScore,
+		override.Status,
+		override.Note,
+	).Scan(&override.CreatedAt)
+}
+
+// DeleteScoreOverride removes a match's override and reports whether it had one.
+func (s *Store) DeleteScoreOverride(ctx context.Context, matchID string) (bool, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	res, err := s.q.ExecContext(ctx, `DELETE FROM match_score_overrides WHERE match_id = $1`, matchID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetScoreOverridesByMatchIDs returns the overrides for the given matches,
+// keyed by match ID.
+func (s *Store) GetScoreOverridesByMatchIDs(ctx context.Context, matchIDs []string) (map[string]models.ScoreOverride, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.q.QueryContext(ctx, `
+        SELECT match_id, home_score, away_score, status, note, created_at
+        FROM match_score_overrides
+        WHERE match_id = ANY($1)`, pq.Array(matchIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overrides := make(map[string]models.ScoreOverride)
+	for rows.Next() {
+		var o models.ScoreOverride
+		if err := rows.Scan(&o.MatchID, &o.HomeScore, &o.AwayScore, &o.Status, &o.Note, &o.CreatedAt); err != nil {
+			return nil, err
+		}
+		overrides[o.MatchID] = o
+	}
+	return overrides, rows.Err()
+}
+
+// LockMatch holds off other writers to a match until the caller's transaction
+// ends, and returns the match as stored, or nil if it is new. An advisory
+// lock on the ID covers matches not yet inserted, which a row lock can't;
+// the row is also locked FOR UPDATE against writers that skip the advisory
+// lock. Outside a transaction the locks are released as soon as it returns.
+func (s *Store) LockMatch(ctx context.Context, matchID string) (*models.Match, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := s.q.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('match:' || $1))`, matchID); err != nil {
+		return nil, err
+	}
+	rows, err := s.q.QueryContext(ctx, `SELECT `+matchColumns+` FROM matches m WHERE m.id = $1 FOR UPDATE`, matchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	m, err := scanMatch(rows)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// LockMatches locks matches as LockMatch does and returns those already
+// stored, keyed by ID. Locks are taken in ID order, so callers locking
+// overlapping sets wait on each other rather than deadlock.
+func (s *Store) LockMatches(ctx context.Context, matchIDs []string) (map[string]models.Match, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	ids := slices.Compact(slices.Sorted(slices.Values(matchIDs)))
+	if _, err := s.q.ExecContext(ctx, `
+        SELECT pg_advisory_xact_lock(hashtext('match:' || id))
+        FROM unnest($1::text[]) WITH ORDINALITY AS ids (id, n)
+        ORDER BY n`, pq.Array(ids)); err != nil {
+		return nil, err
+	}
+	rows, err := s.q.QueryContext(ctx, `SELECT `+matchColumns+` FROM matches m WHERE m.id = ANY($1) ORDER BY m.id FOR UPDATE`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stored := make(map[string]models.Match)
+	for rows.Next() {
+		m, err := scanMatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		stored[m.ID] = m
+	}
+	return stored, rows.Err()
+}
+
+// SetMatchResult writes a reconciled score and status to a match along with
+// the source that supplied it and whether providers disagree.
+func (s *Store) SetMatchResult(ctx context.Context, matchID string, homeScore, awayScore int, status models.MatchStatus, source string, conflict bool) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	_, err := s.q.ExecContext(ctx, `
+        UPDATE matches SET
+            home_score = $2,
+            away_score = $3,
+            status = $4,
+            score_source = $5,
+            score_conflict = $6,
+            updated_at = now()
+        WHERE id = $1`,
+		matchID, homeScore, awayScore, status, sql.NullString{String: source, Valid: source != ""}, conflict)
+	return err
+}
+
+// GetConflictedMatches returns matches whose providers disagree. Matches
+// settled by an override are left out unless includeResolved is set.
+func (s *Store) GetConflictedMatches(ctx context.Context, includeResolved bool) ([]models.Match, error) {
+	return s.queryMatches(ctx, `m.score_conflict AND ($1 OR m.score_source IS DISTINCT FROM 'override')`, includeResolved)
+}
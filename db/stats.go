@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"rugby-live-api/models"
+)
+
+// GetTeamResults returns a team's finished matches, newest first, at most
+// limit of them when limit is positive. Matches are found through the season
+// held in matches.league_id. With no seasonID, seasons of all-time leagues
+// are left out: they re-list fixtures already stored under the competition's
+// own seasons, so counting both would count those results twice.
+func (s *Store) GetTeamResults(ctx context.Context, teamID, seasonID string, limit int) ([]models.TeamResult, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.q.QueryContext(ctx, `
+        SELECT m.id, m.kick_off, s.id, l.id, l.name,
+               m.home_team_id = $1,
+               CASE WHEN m.home_team_id = $1 THEN m.away_team_id ELSE m.home_team_id END,
+               COALESCE(o.name, ''),
+               CASE WHEN m.home_team_id = $1 THEN m.home_score ELSE m.away_score END,
+               CASE WHEN m.home_team_id = $1 THEN m.away_score ELSE m.home_score END
+        FROM matches m
+        JOIN seasons s ON s.id = m.league_id
+        JOIN leagues l ON l.id = s.league_id
+        LEFT JOIN teams o ON o.id = CASE WHEN m.home_team_id = $1 THEN m.away_team_id ELSE m.home_team_id END
+        WHERE m.status = $2
+          AND (m.home_team_id = $1 OR m.away_team_id = $1)
+          AND (s.id = $3 OR ($3 = '' AND NOT COALESCE(l.all_time, false)))
+        ORDER BY m.kick_off DESC, m.id
+        LIMIT $4`,
+		teamID, models.StatusFinished, seasonID, sql.NullInt64{Int64: int64(limit), Valid: limit > 0})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []models.TeamResult{}
+	for rows.Next() {
+		var r models.TeamResult
+		if err := rows.Scan(&r.MatchID, &r.KickOff, &r.SeasonID, &r.LeagueID, &r.LeagueName, &r.Home,
+			&r.OpponentID, &r.OpponentName, &r.PointsFor, &r.PointsAgainst); err != nil {
+			return nil, err
+		}
+		switch {
+		case r.PointsFor > r.PointsAgainst:
+			r.Result = "W"
+		case r.PointsFor < r.PointsAgainst:
+			r.Result = "L"
+		default:
+			r.Result = "D"
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
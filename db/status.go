@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"rugby-live-api/models"
+
+	"github.com/lib/pq"
+)
+
+// InsertStatusChange appends to a match's status history, filling in the
+// change's ID and time.
+func (s *Store) InsertStatusChange(ctx context.Context, change *models.StatusChange) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	return s.q.QueryRowContext(ctx, `
+        INSERT INTO match_status_history (match_id, from_status, to_status, source)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, changed_at`,
+		change.MatchID,
+		sql.NullString{String: string(change.From), Valid: change.From != ""},
+		change.To,
+		change.Source,
+	).Scan(&change.ID, &change.ChangedAt)
+}
+
+// GetStatusHistory returns a match's status changes, oldest first.
+func (s *Store) GetStatusHistory(ctx context.Context, matchID string) ([]models.StatusChange, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.q.QueryContext(ctx, `
+        SELECT id, match_id, COALESCE(from_status, ''), to_status, source, changed_at
+        FROM match_status_history
+        WHERE match_id = $1
+        ORDER BY changed_at, id`, matchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []models.StatusChange{}
+	for rows.Next() {
+		var c models.StatusChange
+		if err := rows.Scan(&c.ID, &c.MatchID, &c.From, &c.To, &c.Source, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, c)
+	}
+	return history, rows.Err()
+}
+
+// CountMatchesByStatus returns the number of stored matches in each of
+// statuses. Statuses no match is in are left out.
+func (s *Store) CountMatchesByStatus(ctx context.Context, statuses []models.MatchStatus) (map[models.MatchStatus]int, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	values := make([]string, len(statuses))
+	for i, status := range statuses {
+		values[i] = string(status)
+	}
+	rows, err := s.q.QueryContext(ctx, `
+        SELECT status, COUNT(*)
+        FROM matches
+        WHERE status = ANY($1)
+        GROUP BY status`, pq.Array(values))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[models.MatchStatus]int)
+	for rows.Next() {
+		var status models.MatchStatus
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, err
+		}
+		counts[status] = n
+	}
+	return counts, rows.Err()
+}
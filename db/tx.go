@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"rugby-live-api/events"
+	"rugby-live-api/metrics"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Querier is the part of *sqlx.DB and *sqlx.Tx the store's queries use, so
+// the same methods run inside or outside a transaction.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+// WithTx runs fn against a store bound to a new transaction. The transaction
+// commits when fn returns nil and rolls back otherwise. A store that is
+// already in a transaction runs fn in that transaction. Events published
+// through the transaction's store go out after it commits and are dropped
+// if it rolls back, as they are when ctx is cancelled before commit.
+func (s *Store) WithTx(ctx context.Context, fn func(tx *Store) error) error {
+	if _, ok := s.q.(*sqlx.Tx); ok {
+		return fn(s)
+	}
+
+	tx, err := s.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	var pending []events.Event
+	upserts := make(map[string]int)
+	if err := fn(&Store{DB: s.DB, q: tx, log: s.log, queryTimeout: s.queryTimeout, pending: &pending, upserts: upserts}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, e := range pending {
+		events.Publish(e)
+	}
+	for entity, n := range upserts {
+		metrics.RowsUpserted(entity, n)
+	}
+	return nil
+}
+
+// Publish sends e to event subscribers, holding it back until commit when
+// the store is in a transaction.
+func (s *Store) Publish(e events.Event) {
+	if s.pending != nil {
+		*s.pending = append(*s.pending, e)
+		return
+	}
+	events.Publish(e)
+}
+
+// upserted records n rows of entity written when err is nil, holding the
+// count back until commit when the store is in a transaction. It returns err.
+func (s *Store) upserted(entity string, n int, err error) error {
+	if err != nil || n == 0 {
+		return err
+	}
+	if s.upserts != nil {
+		s.upserts[entity] += n
+		return nil
+	}
+	metrics.RowsUpserted(entity, n)
+	return nil
+}
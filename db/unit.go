@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Kinds of unit of work recorded in an IngestionReport.
+const (
+	UnitMatch  = "match"
+	UnitLeague = "league"
+	UnitTeam   = "team"
+)
+
+// UnitFailure describes a unit of work that rolled back. Key is the
+// provider's ID for the unit's root entity, and Step the write that failed.
+type UnitFailure struct {
+	Kind  string `json:"kind"`
+	Key   string `json:"key"`
+	Step  string `json:"step,omitempty"`
+	Error string `json:"error"`
+}
+
+// IngestionReport counts the units of work an ingestion run committed and
+// describes each one that rolled back. It is safe for concurrent use.
+type IngestionReport struct {
+	Committed int           `json:"committed"`
+	Failed    []UnitFailure `json:"failed"`
+
+	mu sync.Mutex
+}
+
+// NewIngestionReport returns an empty report.
+func NewIngestionReport() *IngestionReport {
+	return &IngestionReport{Failed: []UnitFailure{}}
+}
+
+// StepError annotates a unit of work's error with the step that failed.
+type StepError struct {
+	Step string
+	Err  error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Step, e.Err)
+}
+
+func (e *StepError) Unwrap() error { return e.Err }
+
+// Step returns err annotated with step, or nil when err is nil.
+func Step(step string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &StepError{Step: step, Err: err}
+}
+
+// Unit runs fn as one unit of work: a transaction writing one graph of rows,
+// such as a match with its league and teams, whole or not at all. The
+// outcome is recorded in report under kind and key, and fn's error returned.
+func (s *Store) Unit(ctx context.Context, report *IngestionReport, kind, key string, fn func(tx *Store) error) error {
+	err := s.WithTx(ctx, fn)
+	report.record(kind, key, err)
+	if err != nil {
+		s.log.WarnContext(ctx, "Unit of work rolled back", "kind", kind, "unit_id", key, "error", err)
+	}
+	return err
+}
+
+func (r *IngestionReport) record(kind, key string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err == nil {
+		r.Committed++
+		return
+	}
+	failure := UnitFailure{Kind: kind, Key: key, Error: err.Error()}
+	var step *StepError
+	if errors.As(err, &step) {
+		failure.Step = step.Step
+		failure.Error = step.Err.Error()
+	}
+	r.Failed = append(r.Failed, failure)
+}
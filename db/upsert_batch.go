@@ -0,0 +1,357 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"rugby-live-api/events"
+	"rugby-live-api/models"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Batch upserts write many rows per statement with a multi-row
+// INSERT ... ON CONFLICT, for backfills where one round trip per row would
+// dominate. Each matches its single-row counterpart row for row. Rows
+// sharing a key are written once, taking the last; a statement can't touch
+// a row twice. The query timeout applies to each statement, not the batch.
+
+// batchRows caps the rows per statement, keeping the widest batch well
+// under Postgres's 65535 bind parameters.
+const batchRows = 1000
+
+// UpsertTeams upserts teams as UpsertTeam would, upserting each distinct
+// country once and publishing a team.updated event for each team added or
+// changed.
+func (s *Store) UpsertTeams(ctx context.Context, teams []*models.Team) error {
+	teams = lastByKey(teams, func(t *models.Team) string { return t.ID })
+	countries := make([]*models.Country, len(teams))
+	for i, team := range teams {
+		countries[i] = &team.Country
+	}
+	for _, country := range lastByKey(countries, func(c *models.Country) string { return c.Code }) {
+		if err := s.UpsertCountry(ctx, country); err != nil {
+			return fmt.Errorf("failed to upsert team country %s: %v", country.Code, err)
+		}
+	}
+
+	for _, chunk := range chunks(teams, batchRows) {
+		if err := s.upsertTeamChunk(ctx, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) upsertTeamChunk(ctx context.Context, teams []*models.Team) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	// previous is read before the upsert runs, so each row returned says
+	// whether anything a consumer would see has changed.
+	query := fmt.Sprintf(`
+        WITH previous AS (
+            SELECT id, name, logo_url, logo_source, alternate_names FROM teams WHERE id = ANY($2)
+        )
+        INSERT INTO teams (id, name, country_code, logo_url, logo_source, alternate_names, created_at, updated_at)
+        VALUES %s
+        ON CONFLICT (id)
+        DO UPDATE SET
+            name = EXCLUDED.name,
+            logo_url = EXCLUDED.logo_url,
+            logo_source = EXCLUDED.logo_source,
+            alternate_names = EXCLUDED.alternate_names,
+            updated_at = EXCLUDED.updated_at
+        RETURNING teams.id, NOT EXISTS (
+            SELECT 1 FROM previous
+            WHERE previous.id = teams.id
+              AND previous.name = teams.name
+              AND previous.logo_url IS NOT DISTINCT FROM teams.logo_url
+              AND previous.logo_source IS NOT DISTINCT FROM teams.logo_source
+              AND previous.alternate_names IS NOT DISTINCT FROM teams.alternate_names
+        )`, valuesList("(?, ?, ?, ?, ?, ?, $1, $1)", len(teams), 2))
+
+	ids := make([]string, len(teams))
+	byID := make(map[string]*models.Team, len(teams))
+	for i, team := range teams {
+		ids[i] = team.ID
+		byID[team.ID] = team
+	}
+	args := make([]interface{}, 0, 2+len(teams)*6)
+	args = append(args, time.Now(), pq.Array(ids))
+	for _, team := range teams {
+		altNames := team.AltNames
+		if altNames == nil {
+			altNames = []string{}
+		}
+		args = append(args, team.ID, team.Name, team.Country.Code, team.LogoURL, team.LogoSource, pq.Array(altNames))
+	}
+
+	rows, err := s.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var changed []*models.Team
+	for rows.Next() {
+		var id string
+		var isChanged bool
+		if err := rows.Scan(&id, &isChanged); err != nil {
+			return err
+		}
+		if isChanged {
+			changed = append(changed, byID[id])
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, team := range changed {
+		s.Publish(events.New(events.TeamUpdated, *team))
+	}
+	return s.upserted("team", len(teams), nil)
+}
+
+// UpsertMatches upserts matches as UpsertMatch would. It writes rows only:
+// reconcile.RecordAll stores the matching reports and status history.
+func (s *Store) UpsertMatches(ctx context.Context, matches []*models.Match) error {
+	matches = lastByKey(matches, func(m *models.Match) string { return m.ID })
+	for _, chunk := range chunks(matches, batchRows) {
+		if err := s.upsertMatchChunk(ctx, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) upsertMatchChunk(ctx context.Context, matches []*models.Match) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	query := fmt.Sprintf(`
+        INSERT INTO matches (
+            id, home_team_id, away_team_id, league_id,
+            home_score, away_score, status, kick_off,
+            date, time, venue_timezone, venue, score_source, score_conflict,
+            created_at, updated_at
+        ) VALUES %s
+        ON CONFLICT (id) DO UPDATE SET
+            home_score = EXCLUDED.home_score,
+            away_score = EXCLUDED.away_score,
+            status = EXCLUDED.status,
+            kick_off = EXCLUDED.kick_off,
+            date = EXCLUDED.date,
+            time = EXCLUDED.time,
+            venue_timezone = EXCLUDED.venue_timezone,
+            venue = COALESCE(EXCLUDED.venue, matches.venue),
+            score_source = EXCLUDED.score_source,
+            score_conflict = EXCLUDED.score_conflict,
+            updated_at = EXCLUDED.updated_at`,
+		valuesList("(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), ?, now(), now())", len(matches), 0))
+
+	args := make([]interface{}, 0, len(matches)*14)
+	for _, match := range matches {
+		// Kick-offs are stored in UTC; Date and Time are derived from the UTC instant
+		kickOff := match.KickOff.UTC()
+		venueTimezone := match.VenueTimezone
+		if venueTimezone == "" {
+			venueTimezone = "UTC"
+		}
+		args = append(args,
+			match.ID,
+			match.HomeTeamID,
+			match.AwayTeamID,
+			match.LeagueID,
+			match.HomeScore,
+			match.AwayScore,
+			match.Status,
+			kickOff,
+			kickOff.Format("2006-01-02"),
+			kickOff.Format("15:04"),
+			venueTimezone,
+			match.Venue,
+			match.ScoreSource,
+			match.ScoreConflict,
+		)
+	}
+
+	_, err := s.q.ExecContext(ctx, query, args...)
+	return s.upserted("match", len(matches), err)
+}
+
+// UpsertSourceReports replaces providers' reports as UpsertSourceReport
+// would.
+func (s *Store) UpsertSourceReports(ctx context.Context, reports []*models.SourceReport) error {
+	reports = lastByKey(reports, func(r *models.SourceReport) string { return r.MatchID + "\x00" + r.Source })
+	for _, chunk := range chunks(reports, batchRows) {
+		if err := s.upsertSourceReportChunk(ctx, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) upsertSourceReportChunk(ctx context.Context, reports []*models.SourceReport) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	query := fmt.Sprintf(`
+        INSERT INTO match_source_reports (match_id, source, home_score, away_score, status, reported_at)
+        VALUES %s
+        ON CONFLICT (match_id, source) DO UPDATE SET
+            home_score = EXCLUDED.home_score,
+            away_score = EXCLUDED.away_score,
+            status = EXCLUDED.status,
+            reported_at = EXCLUDED.reported_at`,
+		valuesList("(?, ?, ?, ?, ?, ?)", len(reports), 0))
+
+	args := make([]interface{}, 0, len(reports)*6)
+	for _, report := range reports {
+		args = append(args, report.MatchID, report.Source, report.HomeScore, report.AwayScore, report.Status, report.ReportedAt)
+	}
+
+	_, err := s.q.ExecContext(ctx, query, args...)
+	return err
+}
+
+// InsertStatusChanges appends to matches' status histories as
+// InsertStatusChange would, filling in each change's ID and time. It takes
+// at most one change per match, as a status moves once per write; rows are
+// matched back to changes by match ID.
+func (s *Store) InsertStatusChanges(ctx context.Context, changes []*models.StatusChange) error {
+	for _, chunk := range chunks(changes, batchRows) {
+		if err := s.insertStatusChangeChunk(ctx, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) insertStatusChangeChunk(ctx context.Context, changes []*models.StatusChange) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	query := fmt.Sprintf(`
+        INSERT INTO match_status_history (match_id, from_status, to_status, source)
+        VALUES %s
+        RETURNING match_id, id, changed_at`,
+		valuesList("(?, NULLIF(?, ''), ?, ?)", len(changes), 0))
+
+	byMatch := make(map[string]*models.StatusChange, len(changes))
+	args := make([]interface{}, 0, len(changes)*4)
+	for _, change := range changes {
+		if _, ok := byMatch[change.MatchID]; ok {
+			return fmt.Errorf("more than one status change for match %s", change.MatchID)
+		}
+		byMatch[change.MatchID] = change
+		args = append(args, change.MatchID, change.From, change.To, change.Source)
+	}
+
+	rows, err := s.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var matchID string
+		var id int64
+		var changedAt time.Time
+		if err := rows.Scan(&matchID, &id, &changedAt); err != nil {
+			return err
+		}
+		if change := byMatch[matchID]; change != nil {
+			change.ID, change.ChangedAt = id, changedAt
+		}
+	}
+	return rows.Err()
+}
+
+// UpsertAPIMappings upserts mappings as UpsertAPIMapping would.
+func (s *Store) UpsertAPIMappings(ctx context.Context, mappings []*models.APIMapping) error {
+	mappings = lastByKey(mappings, func(m *models.APIMapping) string {
+		return m.APIName + "\x00" + m.APIID + "\x00" + m.EntityType
+	})
+	for _, chunk := range chunks(mappings, batchRows) {
+		if err := s.upsertAPIMappingChunk(ctx, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) upsertAPIMappingChunk(ctx context.Context, mappings []*models.APIMapping) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	query := fmt.Sprintf(`
+        INSERT INTO api_mappings (api_name, api_id, entity_type, entity_id, created_at, updated_at)
+        VALUES %s
+        ON CONFLICT (api_name, api_id, entity_type)
+        DO UPDATE SET
+            entity_id = EXCLUDED.entity_id,
+            updated_at = EXCLUDED.updated_at`,
+		valuesList("(?, ?, ?, ?, $1, $1)", len(mappings), 1))
+
+	args := make([]interface{}, 0, 1+len(mappings)*4)
+	args = append(args, time.Now())
+	for _, mapping := range mappings {
+		args = append(args, mapping.APIName, mapping.APIID, mapping.EntityType, mapping.EntityID)
+	}
+
+	_, err := s.q.ExecContext(ctx, query, args...)
+	return s.upserted("api_mapping", len(mappings), err)
+}
+
+// valuesList repeats row, a VALUES tuple with ? for each per-row parameter,
+// n times, numbering the parameters from offset+1.
+func valuesList(row string, n, offset int) string {
+	var b strings.Builder
+	param := offset
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		for _, r := range row {
+			if r != '?' {
+				b.WriteRune(r)
+				continue
+			}
+			param++
+			fmt.Fprintf(&b, "$%d", param)
+		}
+	}
+	return b.String()
+}
+
+// lastByKey drops every item whose key appears again later in items.
+func lastByKey[T any](items []T, key func(T) string) []T {
+	last := make(map[string]int, len(items))
+	for i, item := range items {
+		last[key(item)] = i
+	}
+	if len(last) == len(items) {
+		return items
+	}
+	kept := make([]T, 0, len(last))
+	for i, item := range items {
+		if last[key(item)] == i {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// chunks splits items into slices of at most size.
+func chunks[T any](items []T, size int) [][]T {
+	var out [][]T
+	for len(items) > size {
+		out = append(out, items[:size])
+		items = items[size:]
+	}
+	if len(items) > 0 {
+		out = append(out, items)
+	}
+	return out
+}
@@ -0,0 +1,229 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"rugby-live-api/config"
+	"rugby-live-api/models"
+	"testing"
+	"time"
+)
+
+func TestValuesList(t *testing.T) {
+	tests := []struct {
+		row       string
+		n, offset int
+		want      string
+	}{
+		{"(?, ?)", 1, 0, "($1, $2)"},
+		{"(?, ?, $1)", 3, 1, "($2, $3, $1), ($4, $5, $1), ($6, $7, $1)"},
+		{"(?, NULLIF(?, ''), now())", 2, 0, "($1, NULLIF($2, ''), now()), ($3, NULLIF($4, ''), now())"},
+		{"(?)", 0, 0, ""},
+	}
+	for _, tt := range tests {
+		if got := valuesList(tt.row, tt.n, tt.offset); got != tt.want {
+			t.Errorf("valuesList(%q, %d, %d) = %q, want %q", tt.row, tt.n, tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestLastByKey(t *testing.T) {
+	first := func(s string) string { return s[:1] }
+	tests := []struct {
+		in, want []string
+	}{
+		{nil, nil},
+		{[]string{"a1", "b1"}, []string{"a1", "b1"}},
+		{[]string{"a1", "b1", "a2", "c1", "b2"}, []string{"a2", "c1", "b2"}},
+		{[]string{"a1", "a2", "a3"}, []string{"a3"}},
+	}
+	for _, tt := range tests {
+		if got := lastByKey(tt.in, first); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lastByKey(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestChunks(t *testing.T) {
+	tests := []struct {
+		n, size int
+		want    []int
+	}{
+		{0, 3, nil},
+		{2, 3, []int{2}},
+		{3, 3, []int{3}},
+		{7, 3, []int{3, 3, 1}},
+	}
+	for _, tt := range tests {
+		var got []int
+		for _, chunk := range chunks(make([]int, tt.n), tt.size) {
+			got = append(got, len(chunk))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("chunks of %d by %d have sizes %v, want %v", tt.n, tt.size, got, tt.want)
+		}
+	}
+}
+
+// The benchmarks compare writing benchRows rows one at a time with writing
+// them in a batch. They need a migrated database at TEST_DATABASE_URL:
+//
+//	TEST_DATABASE_URL=postgres://localhost/rugby_test?sslmode=disable go test -run - -bench Upsert ./db
+//
+// Each runs in a transaction that is rolled back, leaving the database as
+// it was. With no commit per row, the single-row figures understate what a
+// backfill pays.
+
+const benchRows = 1000
+
+var errRollback = errors.New("rolled back")
+
+func benchStore(b *testing.B) *Store {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		b.Skip("TEST_DATABASE_URL not set")
+	}
+	store, err := NewStore(config.Database{
+		URL:          url,
+		MaxOpenConns: 2,
+		MaxIdleConns: 2,
+		QueryTimeout: time.Minute,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { store.Close() })
+	return store
+}
+
+// benchRollback runs fn in a transaction and rolls it back, reporting the
+// rows written per second. fn writes benchRows rows per iteration.
+func benchRollback(b *testing.B, store *Store, fn func(ctx context.Context, tx *Store, i int) error) {
+	ctx := context.Background()
+	err := store.WithTx(ctx, func(tx *Store) error {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := fn(ctx, tx, i); err != nil {
+				return err
+			}
+		}
+		b.StopTimer()
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		b.Fatal(err)
+	}
+	b.ReportMetric(float64(b.N*benchRows)/b.Elapsed().Seconds(), "rows/s")
+}
+
+func benchTeams(method string, i int) []*models.Team {
+	country := models.Country{Code: "ZZ", Name: "Benchmark"}
+	teams := make([]*models.Team, benchRows)
+	for j := range teams {
+		teams[j] = &models.Team{ID: fmt.Sprintf("bench-%s-%d-%d", method, i, j), Name: fmt.Sprintf("Team %d", j), Country: country}
+	}
+	return teams
+}
+
+func BenchmarkUpsertTeams(b *testing.B) {
+	store := benchStore(b)
+	b.Run("single", func(b *testing.B) {
+		benchRollback(b, store, func(ctx context.Context, tx *Store, i int) error {
+			for _, team := range benchTeams("single", i) {
+				if err := tx.UpsertTeam(ctx, team); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	b.Run("batch", func(b *testing.B) {
+		benchRollback(b, store, func(ctx context.Context, tx *Store, i int) error {
+			return tx.UpsertTeams(ctx, benchTeams("batch", i))
+		})
+	})
+}
+
+func benchMappings(method string, i int) []*models.APIMapping {
+	mappings := make([]*models.APIMapping, benchRows)
+	for j := range mappings {
+		id := fmt.Sprintf("bench-%s-%d-%d", method, i, j)
+		mappings[j] = &models.APIMapping{EntityID: id, APIName: "bench", APIID: id, EntityType: "match"}
+	}
+	return mappings
+}
+
+func BenchmarkUpsertAPIMappings(b *testing.B) {
+	store := benchStore(b)
+	b.Run("single", func(b *testing.B) {
+		benchRollback(b, store, func(ctx context.Context, tx *Store, i int) error {
+			for _, mapping := range benchMappings("single", i) {
+				if err := tx.UpsertAPIMapping(ctx, mapping); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	b.Run("batch", func(b *testing.B) {
+		benchRollback(b, store, func(ctx context.Context, tx *Store, i int) error {
+			return tx.UpsertAPIMappings(ctx, benchMappings("batch", i))
+		})
+	})
+}
+
+// benchMatches returns benchRows finished matches between two teams it
+// upserts through tx, as a match's teams must exist.
+func benchMatches(ctx context.Context, b *testing.B, tx *Store, method string, i int) []*models.Match {
+	country := models.Country{Code: "ZZ", Name: "Benchmark"}
+	home := &models.Team{ID: "bench-home", Name: "Bench Home", Country: country}
+	away := &models.Team{ID: "bench-away", Name: "Bench Away", Country: country}
+	if err := tx.UpsertTeams(ctx, []*models.Team{home, away}); err != nil {
+		b.Fatal(err)
+	}
+	kickOff := time.Date(2015, time.January, 1, 15, 0, 0, 0, time.UTC)
+	matches := make([]*models.Match, benchRows)
+	for j := range matches {
+		matches[j] = &models.Match{
+			ID:         fmt.Sprintf("bench-%s-%d-%d", method, i, j),
+			HomeTeamID: home.ID,
+			AwayTeamID: away.ID,
+			LeagueID:   "bench",
+			HomeScore:  j % 40,
+			AwayScore:  j % 30,
+			Status:     models.StatusFinished,
+			KickOff:    kickOff.AddDate(0, 0, j),
+		}
+	}
+	return matches
+}
+
+func BenchmarkUpsertMatches(b *testing.B) {
+	store := benchStore(b)
+	b.Run("single", func(b *testing.B) {
+		benchRollback(b, store, func(ctx context.Context, tx *Store, i int) error {
+			b.StopTimer()
+			matches := benchMatches(ctx, b, tx, "single", i)
+			b.StartTimer()
+			for _, match := range matches {
+				if err := tx.UpsertMatch(ctx, match); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	b.Run("batch", func(b *testing.B) {
+		benchRollback(b, store, func(ctx context.Context, tx *Store, i int) error {
+			b.StopTimer()
+			matches := benchMatches(ctx, b, tx, "batch", i)
+			b.StartTimer()
+			return tx.UpsertMatches(ctx, matches)
+		})
+	})
+}
@@ -0,0 +1,184 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"rugby-live-api/models"
+
+	"github.com/lib/pq"
+)
+
+const webhookSubscriptionColumns = `id, url, secret, events, active, created_at, updated_at`
+
+func scanWebhookSubscription(row interface{ Scan(...interface{}) error }) (models.WebhookSubscription, error) {
+	var sub models.WebhookSubscription
+	err := row.Scan(&sub.ID, &sub.URL, &sub.Secret, pq.Array(&sub.Events), &sub.Active, &sub.CreatedAt, &sub.UpdatedAt)
+	return sub, err
+}
+
+func (s *Store) CreateWebhookSubscription(ctx context.Context, sub *models.WebhookSubscription) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	return s.q.QueryRowContext(ctx, `
+        INSERT INTO webhook_subscriptions (url, secret, events, active)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, created_at, updated_at`,
+		sub.URL, sub.Secret, pq.Array(sub.Events), sub.Active,
+	).Scan(&sub.ID, &sub.CreatedAt, &sub.UpdatedAt)
+}
+
+// GetWebhookSubscription returns nil when there is no subscription with id.
+func (s *Store) GetWebhookSubscription(ctx context.Context, id int64) (*models.WebhookSubscription, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	sub, err := scanWebhookSubscription(s.q.QueryRowContext(ctx,
+		`SELECT `+webhookSubscriptionColumns+` FROM webhook_subscriptions WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (s *Store) ListWebhookSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	return s.queryWebhookSubscriptions(ctx, `TRUE`)
+}
+
+// GetWebhookSubscriptionsForEvent returns the active subscriptions that
+// listen for eventType.
+func (s *Store) GetWebhookSubscriptionsForEvent(ctx context.Context, eventType string) ([]models.WebhookSubscription, error) {
+	return s.queryWebhookSubscriptions(ctx, `active AND $1 = ANY(events)`, eventType)
+}
+
+func (s *Store) queryWebhookSubscriptions(ctx context.Context, where string, args ...interface{}) ([]models.WebhookSubscription, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.q.QueryContext(ctx, `SELECT `+webhookSubscriptionColumns+` FROM webhook_subscriptions WHERE `+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []models.WebhookSubscription{}
+	for rows.Next() {
+		sub, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// DeleteWebhookSubscription removes a subscription and its dead letters,
+// returning what was removed or nil when there was no such subscription.
+func (s *Store) DeleteWebhookSubscription(ctx context.Context, id int64) (*models.WebhookSubscription, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	sub, err := scanWebhookSubscription(s.q.QueryRowContext(ctx,
+		`DELETE FROM webhook_subscriptions WHERE id = $1 RETURNING `+webhookSubscriptionColumns, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (s *Store) InsertWebhookDeadLetter(ctx context.Context, letter *models.WebhookDeadLetter) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	return s.q.QueryRowContext(ctx, `
+        INSERT INTO webhook_dead_letters (subscription_id, event_id, event_type, payload, attempts, last_error)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, created_at`,
+		letter.SubscriptionID, letter.EventID, letter.EventType, []byte(letter.Payload), letter.Attempts, letter.LastError,
+	).Scan(&letter.ID, &letter.CreatedAt)
+}
+
+const webhookDeadLetterColumns = `id, subscription_id, event_id, event_type, payload, attempts, last_error, created_at, replayed_at`
+
+func scanWebhookDeadLetter(row interface{ Scan(...interface{}) error }) (models.WebhookDeadLetter, error) {
+	var l models.WebhookDeadLetter
+	var payload []byte
+	var replayedAt sql.NullTime
+	err := row.Scan(&l.ID, &l.SubscriptionID, &l.EventID, &l.EventType, &payload, &l.Attempts, &l.LastError, &l.CreatedAt, &replayedAt)
+	l.Payload = payload
+	if replayedAt.Valid {
+		l.ReplayedAt = &replayedAt.Time
+	}
+	return l, err
+}
+
+// GetWebhookDeadLetter returns nil when there is no dead letter with id.
+func (s *Store) GetWebhookDeadLetter(ctx context.Context, id int64) (*models.WebhookDeadLetter, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	letter, err := scanWebhookDeadLetter(s.q.QueryRowContext(ctx,
+		`SELECT `+webhookDeadLetterColumns+` FROM webhook_dead_letters WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &letter, nil
+}
+
+// ListWebhookDeadLetters returns dead letters newest first, leaving out
+// those already replayed unless includeReplayed is set.
+func (s *Store) ListWebhookDeadLetters(ctx context.Context, includeReplayed bool) ([]models.WebhookDeadLetter, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.q.QueryContext(ctx, `
+        SELECT `+webhookDeadLetterColumns+`
+        FROM webhook_dead_letters
+        WHERE $1 OR replayed_at IS NULL
+        ORDER BY created_at DESC, id DESC`, includeReplayed)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	letters := []models.WebhookDeadLetter{}
+	for rows.Next() {
+		letter, err := scanWebhookDeadLetter(rows)
+		if err != nil {
+			return nil, err
+		}
+		letters = append(letters, letter)
+	}
+	return letters, rows.Err()
+}
+
+// RecordWebhookReplay counts a replay attempt against a dead letter, marking
+// it replayed when lastError is empty.
+func (s *Store) RecordWebhookReplay(ctx context.Context, letter *models.WebhookDeadLetter, lastError string) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var replayedAt sql.NullTime
+	err := s.q.QueryRowContext(ctx, `
+        UPDATE webhook_dead_letters SET
+            attempts = attempts + 1,
+            last_error = CASE WHEN $2 = '' THEN last_error ELSE $2 END,
+            replayed_at = CASE WHEN $2 = '' THEN now() ELSE replayed_at END
+        WHERE id = $1
+        RETURNING attempts, last_error, replayed_at`,
+		letter.ID, lastError,
+	).Scan(&letter.Attempts, &letter.LastError, &replayedAt)
+	if replayedAt.Valid {
+		letter.ReplayedAt = &replayedAt.Time
+	}
+	return err
+}
@@ -0,0 +1,121 @@
+// Package events carries changes to stored data to whoever wants to hear
+// about them, such as webhook deliveries. The store publishes events once the
+// write that raised them has committed.
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"rugby-live-api/models"
+	"sync"
+	"time"
+)
+
+// Event types.
+const (
+	MatchKickoff = "match.kickoff" // A match moved into play
+	MatchScore   = "match.score"   // A stored match's score changed
+	MatchStatus  = "match.status"  // A match's status changed
+	MatchFinal   = "match.final"   // A match finished
+	TeamUpdated  = "team.updated"  // A team was added or its details changed
+)
+
+// Types lists every event type.
+var Types = []string{MatchKickoff, MatchScore, MatchStatus, MatchFinal, TeamUpdated}
+
+// Valid reports whether eventType is one of Types.
+func Valid(eventType string) bool {
+	for _, t := range Types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type Event struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+func New(eventType string, data interface{}) Event {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return Event{
+		ID:         hex.EncodeToString(id),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+// MatchState is a match's score and status at one point in time.
+type MatchState struct {
+	HomeScore int                `json:"home_score"`
+	AwayScore int                `json:"away_score"`
+	Status    models.MatchStatus `json:"status"`
+}
+
+// MatchChange is the data of every match event. Previous is nil for a match
+// stored for the first time.
+type MatchChange struct {
+	Match    models.Match `json:"match"`
+	Previous *MatchState  `json:"previous,omitempty"`
+}
+
+// MatchEvents returns the events raised by a match moving from previous,
+// nil for a new match, to what match now shows. New matches only raise
+// match.kickoff, when first seen in play, so backfilling past seasons does
+// not announce every old result.
+func MatchEvents(previous *MatchState, match models.Match) []Event {
+	change := MatchChange{Match: match, Previous: previous}
+	if previous == nil {
+		if match.Status.InPlay() {
+			return []Event{New(MatchKickoff, change)}
+		}
+		return nil
+	}
+	from := *previous
+
+	var raised []Event
+	if from.HomeScore != match.HomeScore || from.AwayScore != match.AwayScore {
+		raised = append(raised, New(MatchScore, change))
+	}
+	if from.Status != match.Status {
+		raised = append(raised, New(MatchStatus, change))
+		if match.Status.InPlay() && !from.Status.InPlay() {
+			raised = append(raised, New(MatchKickoff, change))
+		}
+		if match.Status == models.StatusFinished {
+			raised = append(raised, New(MatchFinal, change))
+		}
+	}
+	return raised
+}
+
+// Handler receives published events. It runs on the publisher's goroutine,
+// so it must hand slow work off rather than block.
+type Handler func(Event)
+
+var (
+	mu       sync.RWMutex
+	handlers []Handler
+)
+
+// Subscribe adds h to the handlers every event is passed to.
+func Subscribe(h Handler) {
+	mu.Lock()
+	defer mu.Unlock()
+	handlers = append(handlers, h)
+}
+
+// Publish passes e to every subscribed handler.
+func Publish(e Event) {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, h := range handlers {
+		h(e)
+	}
+}
@@ -0,0 +1,87 @@
+package graph
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"rugby-live-api/db"
+	"rugby-live-api/handlers"
+
+	"github.com/gin-gonic/gin"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// Request is a GraphQL request as sent in a POST body.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Limits on what one anonymous request can make the server do. maxDepth
+// bounds field nesting, so league → seasons → matches → teams can't recurse
+// without end. maxParallelism bounds the resolvers run at once; graphql-go's
+// default of 10 would split a list's fields into loader batches of about 10
+// keys, while 50 keeps batches large without letting one query take every
+// database connection. maxQueryBytes bounds the query and variables.
+const (
+	maxDepth       = 10
+	maxParallelism = 50
+	maxQueryBytes  = 16 << 10
+)
+
+// NewHandler parses the schema and returns a Gin handler serving GraphQL over
+// GET (query string) and POST (JSON body). It panics if the schema does not
+// match the resolvers, which is a programming error caught at startup.
+func NewHandler(store *db.Store) gin.HandlerFunc {
+	schema := graphql.MustParseSchema(schemaSDL, &Resolver{store: store}, graphql.UseFieldResolvers(),
+		graphql.MaxDepth(maxDepth), graphql.MaxParallelism(maxParallelism))
+
+	return func(c *gin.Context) {
+		var req Request
+		if c.Request.Method == http.MethodGet {
+			req.Query = c.Query("query")
+			req.OperationName = c.Query("operationName")
+			vars := c.Query("variables")
+			if len(req.Query)+len(vars) > maxQueryBytes {
+				tooLarge(c)
+				return
+			}
+			if vars != "" {
+				if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+					c.JSON(http.StatusBadRequest, handlers.ErrorResponse{Error: "Invalid variables: " + err.Error()})
+					return
+				}
+			}
+		} else {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxQueryBytes)
+			if err := c.ShouldBindJSON(&req); err != nil {
+				var maxBytes *http.MaxBytesError
+				if errors.As(err, &maxBytes) {
+					tooLarge(c)
+					return
+				}
+				c.JSON(http.StatusBadRequest, handlers.ErrorResponse{Error: "Invalid request: " + err.Error()})
+				return
+			}
+		}
+
+		if req.Query == "" {
+			c.JSON(http.StatusBadRequest, handlers.ErrorResponse{Error: "query is required"})
+			return
+		}
+
+		ctx := WithLoaders(c.Request.Context(), NewLoaders(store))
+		resp := schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+func tooLarge(c *gin.Context) {
+	c.JSON(http.StatusRequestEntityTooLarge, handlers.ErrorResponse{Error: fmt.Sprintf("query and variables must be under %d bytes", maxQueryBytes)})
+}
@@ -0,0 +1,64 @@
+package graph
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandlerLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// No store: every request here is refused before a resolver runs.
+	router.Any("/graphql", NewHandler(nil))
+
+	deep := "{ league(id: 1) { " + strings.Repeat("parent { ", 10) + "id" + strings.Repeat(" }", 10) + " } }"
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		query      url.Values
+		wantStatus int
+		wantError  string // Substring of the error, top-level or GraphQL
+	}{
+		{"missing query", "POST", `{}`, nil, http.StatusBadRequest, "query is required"},
+		{"malformed body", "POST", `{`, nil, http.StatusBadRequest, "Invalid request"},
+		{"malformed variables", "GET", "", url.Values{"query": {"{ countries { code } }"}, "variables": {"{"}}, http.StatusBadRequest, "Invalid variables"},
+		{"body too large", "POST", `{"query": "` + strings.Repeat(" ", maxQueryBytes) + `{ countries { code } }"}`, nil, http.StatusRequestEntityTooLarge, "bytes"},
+		{"query string too large", "GET", "", url.Values{"query": {strings.Repeat(" ", maxQueryBytes+1)}}, http.StatusRequestEntityTooLarge, "bytes"},
+		{"too deep", "POST", `{"query": "` + deep + `"}`, nil, http.StatusOK, "depth"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/graphql?"+tt.query.Encode(), strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d (%s)", tt.name, rec.Code, tt.wantStatus, rec.Body)
+			continue
+		}
+		var resp struct {
+			Error  string `json:"error"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Errorf("%s: body %q is not JSON: %v", tt.name, rec.Body, err)
+			continue
+		}
+		message := resp.Error
+		if len(resp.Errors) > 0 {
+			message = resp.Errors[0].Message
+		}
+		if !strings.Contains(message, tt.wantError) {
+			t.Errorf("%s: error %q, want it to mention %q", tt.name, message, tt.wantError)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// A loader issues its query once batchWait passes with no new key, or
+// maxBatchWait after the batch's first key. Sibling fields resolve
+// concurrently, so keys keep arriving while a list's fields are still being
+// started, and the short quiet period merges them into one round trip.
+const (
+	batchWait    = 2 * time.Millisecond
+	maxBatchWait = 20 * time.Millisecond
+)
+
+type result[V any] struct {
+	value V
+	err   error
+}
+
+type batch[K comparable, V any] struct {
+	keys    []K
+	started time.Time
+	timer   *time.Timer
+	done    chan struct{}
+	data    map[K]V
+	err     error
+}
+
+// Loader batches and caches lookups by key for the lifetime of one request.
+// fetch returns the values it found; missing keys resolve to the zero value.
+type Loader[K comparable, V any] struct {
+	fetch func(context.Context, []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	cache   map[K]*result[V]
+	pending *batch[K, V]
+}
+
+func NewLoader[K comparable, V any](fetch func(context.Context, []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch: fetch,
+		cache: make(map[K]*result[V]),
+	}
+}
+
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	if r, ok := l.cache[key]; ok {
+		l.mu.Unlock()
+		return r.value, r.err
+	}
+
+	b := l.pending
+	if b == nil {
+		b = &batch[K, V]{started: time.Now(), done: make(chan struct{})}
+		l.pending = b
+		b.timer = time.AfterFunc(batchWait, func() { l.dispatch(ctx, b) })
+	} else if time.Since(b.started) < maxBatchWait {
+		b.timer.Reset(batchWait)
+	}
+	b.keys = append(b.keys, key)
+	l.mu.Unlock()
+
+	<-b.done
+	if b.err != nil {
+		var zero V
+		return zero, b.err
+	}
+	return b.data[key], nil
+}
+
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) ([]V, error) {
+	values := make([]V, len(keys))
+	errs := make([]error, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func(i int, key K) {
+			defer wg.Done()
+			values[i], errs[i] = l.Load(ctx, key)
+		}(i, key)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func (l *Loader[K, V]) dispatch(ctx context.Context, b *batch[K, V]) {
+	l.mu.Lock()
+	if l.pending != b {
+		// A timer reset as it fired has already dispatched b.
+		l.mu.Unlock()
+		return
+	}
+	l.pending = nil
+	keys := dedupe(b.keys)
+	l.mu.Unlock()
+
+	b.data, b.err = l.fetch(ctx, keys)
+
+	l.mu.Lock()
+	for _, key := range keys {
+		l.cache[key] = &result[V]{value: b.data[key], err: b.err}
+	}
+	l.mu.Unlock()
+	close(b.done)
+}
+
+func dedupe[K comparable](keys []K) []K {
+	seen := make(map[K]bool, len(keys))
+	unique := make([]K, 0, len(keys))
+	for _, k := range keys {
+		if !seen[k] {
+			seen[k] = true
+			unique = append(unique, k)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLoaderBatchesConcurrentLoads(t *testing.T) {
+	var fetches atomic.Int32
+	l := NewLoader(func(ctx context.Context, keys []int) (map[int]int, error) {
+		fetches.Add(1)
+		values := make(map[int]int, len(keys))
+		for _, k := range keys {
+			values[k] = k * 2
+		}
+		return values, nil
+	})
+
+	const n = 300
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(key int) {
+			defer wg.Done()
+			// Every key is loaded twice; the second resolves from the same
+			// batch or the cache.
+			for j := 0; j < 2; j++ {
+				if v, err := l.Load(context.Background(), key%(n/2)); err != nil || v != key%(n/2)*2 {
+					t.Errorf("Load(%d) = %d, %v", key%(n/2), v, err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if got := fetches.Load(); got != 1 {
+		t.Errorf("fetched %d times, want 1", got)
+	}
+}
+
+func TestLoaderManyReturnsValuesInOrder(t *testing.T) {
+	l := NewLoader(func(ctx context.Context, keys []string) (map[string]string, error) {
+		values := make(map[string]string, len(keys))
+		for _, k := range keys {
+			if k != "missing" {
+				values[k] = "v" + k
+			}
+		}
+		return values, nil
+	})
+	got, err := l.LoadMany(context.Background(), []string{"b", "missing", "a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"vb", "", "va", "vb"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("LoadMany()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
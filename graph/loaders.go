@@ -0,0 +1,151 @@
+package graph
+
+import (
+	"context"
+	"rugby-live-api/db"
+	"rugby-live-api/models"
+)
+
+// Loaders holds the per-request dataloaders used by the resolvers so that
+// nested lists resolve with one query per level instead of one per row.
+type Loaders struct {
+	Countries      *Loader[string, *models.Country]
+	Leagues        *Loader[string, *models.League]
+	Seasons        *Loader[string, *models.Season]
+	Teams          *Loader[string, *models.Team]
+	Matches        *Loader[string, *models.Match]
+	LeagueChildren *Loader[string, []models.League]
+	LeagueSeasons  *Loader[string, []models.Season]
+	CountryLeagues *Loader[string, []models.League]
+	CountryTeams   *Loader[string, []models.Team]
+	SeasonMatches  *Loader[string, []models.Match]
+	TeamStadiums   *Loader[string, []models.TeamStadium]
+	APIMappings    *Loader[entityKey, []models.APIMapping]
+}
+
+type entityKey struct {
+	Type string
+	ID   string
+}
+
+type loadersKey struct{}
+
+func NewLoaders(store *db.Store) *Loaders {
+	return &Loaders{
+		Countries: NewLoader(func(ctx context.Context, codes []string) (map[string]*models.Country, error) {
+			countries, err := store.GetCountriesByCodes(ctx, codes)
+			if err != nil {
+				return nil, err
+			}
+			return indexBy(countries, func(c *models.Country) string { return c.Code }), nil
+		}),
+		Leagues: NewLoader(func(ctx context.Context, ids []string) (map[string]*models.League, error) {
+			leagues, err := store.GetLeaguesByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			return indexBy(leagues, func(l *models.League) string { return l.ID }), nil
+		}),
+		Seasons: NewLoader(func(ctx context.Context, ids []string) (map[string]*models.Season, error) {
+			seasons, err := store.GetSeasonsByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			return indexBy(seasons, func(s *models.Season) string { return s.ID }), nil
+		}),
+		Teams: NewLoader(func(ctx context.Context, ids []string) (map[string]*models.Team, error) {
+			teams, err := store.GetTeamsByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			return indexBy(teams, func(t *models.Team) string { return t.ID }), nil
+		}),
+		Matches: NewLoader(func(ctx context.Context, ids []string) (map[string]*models.Match, error) {
+			matches, err := store.GetMatchesByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			return indexBy(matches, func(m *models.Match) string { return m.ID }), nil
+		}),
+		LeagueChildren: NewLoader(func(ctx context.Context, ids []string) (map[string][]models.League, error) {
+			leagues, err := store.GetLeaguesByParentIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			return groupBy(leagues, func(l models.League) string { return *l.ParentID }), nil
+		}),
+		LeagueSeasons: NewLoader(func(ctx context.Context, ids []string) (map[string][]models.Season, error) {
+			seasons, err := store.GetSeasonsByLeagueIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			return groupBy(seasons, func(s models.Season) string { return s.LeagueID }), nil
+		}),
+		CountryLeagues: NewLoader(func(ctx context.Context, codes []string) (map[string][]models.League, error) {
+			leagues, err := store.GetLeaguesByCountryCodes(ctx, codes)
+			if err != nil {
+				return nil, err
+			}
+			return groupBy(leagues, func(l models.League) string { return l.Country.Code }), nil
+		}),
+		CountryTeams: NewLoader(func(ctx context.Context, codes []string) (map[string][]models.Team, error) {
+			teams, err := store.GetTeamsByCountryCodes(ctx, codes)
+			if err != nil {
+				return nil, err
+			}
+			return groupBy(teams, func(t models.Team) string { return t.Country.Code }), nil
+		}),
+		SeasonMatches: NewLoader(func(ctx context.Context, ids []string) (map[string][]models.Match, error) {
+			matches, err := store.GetMatchesBySeasonIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			return groupBy(matches, func(m models.Match) string { return m.LeagueID }), nil
+		}),
+		TeamStadiums: NewLoader(func(ctx context.Context, ids []string) (map[string][]models.TeamStadium, error) {
+			return store.GetStadiumsByTeamIDs(ctx, ids)
+		}),
+		APIMappings: NewLoader(func(ctx context.Context, keys []entityKey) (map[entityKey][]models.APIMapping, error) {
+			idsByType := make(map[string][]string)
+			for _, k := range keys {
+				idsByType[k.Type] = append(idsByType[k.Type], k.ID)
+			}
+			grouped := make(map[entityKey][]models.APIMapping)
+			for entityType, ids := range idsByType {
+				mappings, err := store.GetAPIMappingsByEntityIDs(ctx, entityType, ids)
+				if err != nil {
+					return nil, err
+				}
+				for _, m := range mappings {
+					key := entityKey{Type: m.EntityType, ID: m.EntityID}
+					grouped[key] = append(grouped[key], m)
+				}
+			}
+			return grouped, nil
+		}),
+	}
+}
+
+func WithLoaders(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, loaders)
+}
+
+func loadersFrom(ctx context.Context) *Loaders {
+	return ctx.Value(loadersKey{}).(*Loaders)
+}
+
+func indexBy[T any](items []T, key func(*T) string) map[string]*T {
+	index := make(map[string]*T, len(items))
+	for i := range items {
+		index[key(&items[i])] = &items[i]
+	}
+	return index
+}
+
+func groupBy[T any](items []T, key func(T) string) map[string][]T {
+	groups := make(map[string][]T)
+	for _, item := range items {
+		groups[key(item)] = append(groups[key(item)], item)
+	}
+	return groups
+}
@@ -0,0 +1,479 @@
+package graph
+
+import (
+	"context"
+	"rugby-live-api/db"
+	"rugby-live-api/models"
+	"rugby-live-api/services/kickoff"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// Resolver is the root query resolver.
+type Resolver struct {
+	store *db.Store
+}
+
+func (r *Resolver) Countries(ctx context.Context) ([]*countryResolver, error) {
+	countries, err := r.store.GetCountries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*countryResolver, len(countries))
+	for i := range countries {
+		resolvers[i] = &countryResolver{country: &countries[i]}
+	}
+	return resolvers, nil
+}
+
+func (r *Resolver) Country(ctx context.Context, args struct{ Code string }) (*countryResolver, error) {
+	return loadCountry(ctx, args.Code)
+}
+
+func (r *Resolver) League(ctx context.Context, args struct{ ID graphql.ID }) (*leagueResolver, error) {
+	return loadLeague(ctx, string(args.ID))
+}
+
+func (r *Resolver) Leagues(ctx context.Context, args struct{ IDs []graphql.ID }) ([]*leagueResolver, error) {
+	leagues, err := loadersFrom(ctx).Leagues.LoadMany(ctx, idStrings(args.IDs))
+	if err != nil {
+		return nil, err
+	}
+	var resolvers []*leagueResolver
+	for _, league := range leagues {
+		if league != nil {
+			resolvers = append(resolvers, &leagueResolver{league: league})
+		}
+	}
+	return resolvers, nil
+}
+
+func (r *Resolver) Season(ctx context.Context, args struct{ ID graphql.ID }) (*seasonResolver, error) {
+	return loadSeason(ctx, string(args.ID))
+}
+
+func (r *Resolver) Team(ctx context.Context, args struct{ ID graphql.ID }) (*teamResolver, error) {
+	return loadTeam(ctx, string(args.ID))
+}
+
+func (r *Resolver) Teams(ctx context.Context, args struct{ IDs []graphql.ID }) ([]*teamResolver, error) {
+	teams, err := loadersFrom(ctx).Teams.LoadMany(ctx, idStrings(args.IDs))
+	if err != nil {
+		return nil, err
+	}
+	var resolvers []*teamResolver
+	for _, team := range teams {
+		if team != nil {
+			resolvers = append(resolvers, &teamResolver{team: team})
+		}
+	}
+	return resolvers, nil
+}
+
+func (r *Resolver) Match(ctx context.Context, args struct{ ID graphql.ID }) (*matchResolver, error) {
+	match, err := loadersFrom(ctx).Matches.Load(ctx, string(args.ID))
+	if err != nil || match == nil {
+		return nil, err
+	}
+	return &matchResolver{match: match}, nil
+}
+
+func (r *Resolver) Matches(ctx context.Context, args struct {
+	Date string
+	Tz   *string
+}) ([]*matchResolver, error) {
+	loc, err := kickoff.LoadLocation(deref(args.Tz))
+	if err != nil {
+		return nil, err
+	}
+	from, to, err := kickoff.DayBounds(args.Date, loc)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := r.store.GetMatchesBetween(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return matchResolvers(matches), nil
+}
+
+type countryResolver struct {
+	country *models.Country
+}
+
+func (r *countryResolver) Code() graphql.ID { return graphql.ID(r.country.Code) }
+func (r *countryResolver) Name() string     { return r.country.Name }
+func (r *countryResolver) Flag() *string    { return optional(r.country.Flag) }
+
+func (r *countryResolver) Leagues(ctx context.Context) ([]*leagueResolver, error) {
+	leagues, err := loadersFrom(ctx).CountryLeagues.Load(ctx, r.country.Code)
+	return leagueResolvers(leagues), err
+}
+
+func (r *countryResolver) Teams(ctx context.Context) ([]*teamResolver, error) {
+	teams, err := loadersFrom(ctx).CountryTeams.Load(ctx, r.country.Code)
+	return teamResolvers(teams), err
+}
+
+type leagueResolver struct {
+	league *models.League
+}
+
+func (r *leagueResolver) ID() graphql.ID      { return graphql.ID(r.league.ID) }
+func (r *leagueResolver) Name() string        { return r.league.Name }
+func (r *leagueResolver) Tier() int32         { return int32(r.league.Tier) }
+func (r *leagueResolver) Format() *string     { return optional(r.league.Format) }
+func (r *leagueResolver) Phases() []string    { return nonNil(r.league.Phases) }
+func (r *leagueResolver) AltNames() []string  { return nonNil(r.league.AltNames) }
+func (r *leagueResolver) LogoUrl() *string    { return optional(r.league.LogoURL) }
+func (r *leagueResolver) Gender() *string     { return optional(r.league.Gender) }
+func (r *leagueResolver) International() bool { return r.league.International }
+func (r *leagueResolver) AllTime() bool       { return r.league.AllTime }
+
+func (r *leagueResolver) Country(ctx context.Context) (*countryResolver, error) {
+	return loadCountry(ctx, r.league.Country.Code)
+}
+
+func (r *leagueResolver) TeamCountries(ctx context.Context) ([]*countryResolver, error) {
+	codes := make([]string, len(r.league.TeamCountries))
+	for i, c := range r.league.TeamCountries {
+		codes[i] = c.Code
+	}
+	countries, err := loadersFrom(ctx).Countries.LoadMany(ctx, codes)
+	if err != nil {
+		return nil, err
+	}
+	var resolvers []*countryResolver
+	for _, country := range countries {
+		if country != nil {
+			resolvers = append(resolvers, &countryResolver{country: country})
+		}
+	}
+	return resolvers, nil
+}
+
+func (r *leagueResolver) Parent(ctx context.Context) (*leagueResolver, error) {
+	if r.league.ParentID == nil {
+		return nil, nil
+	}
+	return loadLeague(ctx, *r.league.ParentID)
+}
+
+func (r *leagueResolver) Children(ctx context.Context) ([]*leagueResolver, error) {
+	leagues, err := loadersFrom(ctx).LeagueChildren.Load(ctx, r.league.ID)
+	return leagueResolvers(leagues), err
+}
+
+func (r *leagueResolver) Successor(ctx context.Context) (*leagueResolver, error) {
+	if r.league.SuccessorID == nil {
+		return nil, nil
+	}
+	return loadLeague(ctx, *r.league.SuccessorID)
+}
+
+func (r *leagueResolver) AllTimeLeague(ctx context.Context) (*leagueResolver, error) {
+	return loadLeague(ctx, r.league.AllTimeID)
+}
+
+func (r *leagueResolver) Seasons(ctx context.Context) ([]*seasonResolver, error) {
+	seasons, err := loadersFrom(ctx).LeagueSeasons.Load(ctx, r.league.ID)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*seasonResolver, len(seasons))
+	for i := range seasons {
+		resolvers[i] = &seasonResolver{season: &seasons[i]}
+	}
+	return resolvers, nil
+}
+
+// CurrentSeason prefers the season flagged current and falls back to the
+// latest one, since only RugbyDB-scraped leagues maintain the flag.
+func (r *leagueResolver) CurrentSeason(ctx context.Context) (*seasonResolver, error) {
+	seasons, err := loadersFrom(ctx).LeagueSeasons.Load(ctx, r.league.ID)
+	if err != nil || len(seasons) == 0 {
+		return nil, err
+	}
+	for i := range seasons {
+		if seasons[i].Current {
+			return &seasonResolver{season: &seasons[i]}, nil
+		}
+	}
+	return &seasonResolver{season: &seasons[0]}, nil
+}
+
+func (r *leagueResolver) ApiMappings(ctx context.Context) ([]*apiMappingResolver, error) {
+	return loadMappings(ctx, "league", r.league.ID)
+}
+
+type seasonResolver struct {
+	season *models.Season
+}
+
+func (r *seasonResolver) ID() graphql.ID     { return graphql.ID(r.season.ID) }
+func (r *seasonResolver) Year() int32        { return int32(r.season.Year) }
+func (r *seasonResolver) YearRange() *string { return optional(r.season.YearRange) }
+func (r *seasonResolver) Current() bool      { return r.season.Current }
+func (r *seasonResolver) StartDate() *string { return optionalDate(r.season.StartDate) }
+func (r *seasonResolver) EndDate() *string   { return optionalDate(r.season.EndDate) }
+
+func (r *seasonResolver) League(ctx context.Context) (*leagueResolver, error) {
+	return loadLeague(ctx, r.season.LeagueID)
+}
+
+func (r *seasonResolver) Matches(ctx context.Context) ([]*matchResolver, error) {
+	matches, err := loadersFrom(ctx).SeasonMatches.Load(ctx, r.season.ID)
+	return matchResolvers(matches), err
+}
+
+func (r *seasonResolver) ApiMappings(ctx context.Context) ([]*apiMappingResolver, error) {
+	return loadMappings(ctx, "league_season", r.season.ID)
+}
+
+type teamResolver struct {
+	team *models.Team
+}
+
+func (r *teamResolver) ID() graphql.ID           { return graphql.ID(r.team.ID) }
+func (r *teamResolver) Name() string             { return r.team.Name }
+func (r *teamResolver) LogoUrl() *string         { return optional(r.team.LogoURL) }
+func (r *teamResolver) LogoSource() *string      { return optional(r.team.LogoSource) }
+func (r *teamResolver) AlternateNames() []string { return nonNil(r.team.AltNames) }
+
+func (r *teamResolver) Country(ctx context.Context) (*countryResolver, error) {
+	return loadCountry(ctx, r.team.Country.Code)
+}
+
+func (r *teamResolver) Stadiums(ctx context.Context) ([]*teamStadiumResolver, error) {
+	stadiums, err := loadersFrom(ctx).TeamStadiums.Load(ctx, r.team.ID)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*teamStadiumResolver, len(stadiums))
+	for i := range stadiums {
+		resolvers[i] = &teamStadiumResolver{teamStadium: &stadiums[i]}
+	}
+	return resolvers, nil
+}
+
+func (r *teamResolver) ApiMappings(ctx context.Context) ([]*apiMappingResolver, error) {
+	return loadMappings(ctx, "team", r.team.ID)
+}
+
+type teamStadiumResolver struct {
+	teamStadium *models.TeamStadium
+}
+
+func (r *teamStadiumResolver) Stadium() *stadiumResolver {
+	return &stadiumResolver{stadium: &r.teamStadium.Stadium}
+}
+
+func (r *teamStadiumResolver) IsPrimary() bool { return r.teamStadium.IsPrimary }
+
+type stadiumResolver struct {
+	stadium *models.Stadium
+}
+
+func (r *stadiumResolver) ID() graphql.ID    { return graphql.ID(r.stadium.ID) }
+func (r *stadiumResolver) Name() string      { return r.stadium.Name }
+func (r *stadiumResolver) Location() *string { return optional(r.stadium.Location) }
+
+func (r *stadiumResolver) Capacity() *int32 {
+	if r.stadium.Capacity == 0 {
+		return nil
+	}
+	capacity := int32(r.stadium.Capacity)
+	return &capacity
+}
+
+func (r *stadiumResolver) Country(ctx context.Context) (*countryResolver, error) {
+	return loadCountry(ctx, r.stadium.Country.Code)
+}
+
+type matchResolver struct {
+	match *models.Match
+}
+
+func (r *matchResolver) ID() graphql.ID        { return graphql.ID(r.match.ID) }
+func (r *matchResolver) HomeScore() int32      { return int32(r.match.HomeScore) }
+func (r *matchResolver) AwayScore() int32      { return int32(r.match.AwayScore) }
+func (r *matchResolver) Status() string        { return string(r.match.Status) }
+func (r *matchResolver) KickOff() string       { return r.match.KickOff.UTC().Format(time.RFC3339) }
+func (r *matchResolver) VenueTimezone() string { return r.match.VenueTimezone }
+func (r *matchResolver) Venue() *string        { return optional(r.match.Venue) }
+
+func (r *matchResolver) Date(args struct{ Tz *string }) (string, error) {
+	loc, err := kickoff.LoadLocation(deref(args.Tz))
+	if err != nil {
+		return "", err
+	}
+	return r.match.Localize(loc).Date, nil
+}
+
+func (r *matchResolver) Time(args struct{ Tz *string }) (string, error) {
+	loc, err := kickoff.LoadLocation(deref(args.Tz))
+	if err != nil {
+		return "", err
+	}
+	return r.match.Localize(loc).Time, nil
+}
+
+func (r *matchResolver) HomeTeam(ctx context.Context) (*teamResolver, error) {
+	return loadTeam(ctx, r.match.HomeTeamID)
+}
+
+func (r *matchResolver) AwayTeam(ctx context.Context) (*teamResolver, error) {
+	return loadTeam(ctx, r.match.AwayTeamID)
+}
+
+// Season resolves the match's league_id column as a season ID. Matches
+// ingested from the daily API Sports feed store a league ID there instead,
+// in which case the match has no season.
+func (r *matchResolver) Season(ctx context.Context) (*seasonResolver, error) {
+	return loadSeason(ctx, r.match.LeagueID)
+}
+
+func (r *matchResolver) League(ctx context.Context) (*leagueResolver, error) {
+	season, err := loadersFrom(ctx).Seasons.Load(ctx, r.match.LeagueID)
+	if err != nil {
+		return nil, err
+	}
+	if season != nil {
+		return loadLeague(ctx, season.LeagueID)
+	}
+	return loadLeague(ctx, r.match.LeagueID)
+}
+
+func (r *matchResolver) ApiMappings(ctx context.Context) ([]*apiMappingResolver, error) {
+	return loadMappings(ctx, "match", r.match.ID)
+}
+
+type apiMappingResolver struct {
+	mapping models.APIMapping
+}
+
+func (r *apiMappingResolver) ApiName() string    { return r.mapping.APIName }
+func (r *apiMappingResolver) ApiId() string      { return r.mapping.APIID }
+func (r *apiMappingResolver) EntityType() string { return r.mapping.EntityType }
+func (r *apiMappingResolver) EntityId() graphql.ID {
+	return graphql.ID(r.mapping.EntityID)
+}
+func (r *apiMappingResolver) IsActive() bool { return r.mapping.IsActive }
+
+func loadCountry(ctx context.Context, code string) (*countryResolver, error) {
+	if code == "" {
+		return nil, nil
+	}
+	country, err := loadersFrom(ctx).Countries.Load(ctx, code)
+	if err != nil || country == nil {
+		return nil, err
+	}
+	return &countryResolver{country: country}, nil
+}
+
+func loadLeague(ctx context.Context, id string) (*leagueResolver, error) {
+	if id == "" {
+		return nil, nil
+	}
+	league, err := loadersFrom(ctx).Leagues.Load(ctx, id)
+	if err != nil || league == nil {
+		return nil, err
+	}
+	return &leagueResolver{league: league}, nil
+}
+
+func loadSeason(ctx context.Context, id string) (*seasonResolver, error) {
+	if id == "" {
+		return nil, nil
+	}
+	season, err := loadersFrom(ctx).Seasons.Load(ctx, id)
+	if err != nil || season == nil {
+		return nil, err
+	}
+	return &seasonResolver{season: season}, nil
+}
+
+func loadTeam(ctx context.Context, id string) (*teamResolver, error) {
+	if id == "" {
+		return nil, nil
+	}
+	team, err := loadersFrom(ctx).Teams.Load(ctx, id)
+	if err != nil || team == nil {
+		return nil, err
+	}
+	return &teamResolver{team: team}, nil
+}
+
+func loadMappings(ctx context.Context, entityType string, id string) ([]*apiMappingResolver, error) {
+	mappings, err := loadersFrom(ctx).APIMappings.Load(ctx, entityKey{Type: entityType, ID: id})
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*apiMappingResolver, len(mappings))
+	for i, m := range mappings {
+		resolvers[i] = &apiMappingResolver{mapping: m}
+	}
+	return resolvers, nil
+}
+
+func leagueResolvers(leagues []models.League) []*leagueResolver {
+	resolvers := make([]*leagueResolver, len(leagues))
+	for i := range leagues {
+		resolvers[i] = &leagueResolver{league: &leagues[i]}
+	}
+	return resolvers
+}
+
+func teamResolvers(teams []models.Team) []*teamResolver {
+	resolvers := make([]*teamResolver, len(teams))
+	for i := range teams {
+		resolvers[i] = &teamResolver{team: &teams[i]}
+	}
+	return resolvers
+}
+
+func matchResolvers(matches []models.Match) []*matchResolver {
+	resolvers := make([]*matchResolver, len(matches))
+	for i := range matches {
+		resolvers[i] = &matchResolver{match: &matches[i]}
+	}
+	return resolvers
+}
+
+func idStrings(ids []graphql.ID) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = string(id)
+	}
+	return out
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func optionalDate(t time.Time) *string {
+	if t.IsZero() {
+		return nil
+	}
+	s := t.Format("2006-01-02")
+	return &s
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"rugby-live-api/models"
+	"rugby-live-api/services/reconcile"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConflictsResponse struct {
+	Conflicts []reconcile.Conflict `json:"conflicts"`
+}
+
+// ScoreOverrideRequest is a result entered by hand for a disputed match.
+type ScoreOverrideRequest struct {
+	HomeScore int                `json:"home_score"`
+	AwayScore int                `json:"away_score"`
+	Status    models.MatchStatus `json:"status"`
+	Note      string             `json:"note,omitempty"`
+}
+
+// ListConflicts lists matches whose providers disagree on the result.
+// include_resolved=true adds those already settled by an override.
+func (h *Handler) ListConflicts(c *gin.Context) {
+	includeResolved, ok := boolParam(c, "include_resolved")
+	if !ok {
+		return
+	}
+	conflicts, err := reconcile.Conflicts(c.Request.Context(), h.store, includeResolved != nil && *includeResolved)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to list conflicts: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, ConflictsResponse{Conflicts: conflicts})
+}
+
+// OverrideScore sets a match's result by hand.
+func (h *Handler) OverrideScore(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req ScoreOverrideRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+	if req.HomeScore < 0 || req.AwayScore < 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "scores cannot be negative"})
+		return
+	}
+	status, err := models.ParseMatchStatus(string(req.Status))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	matches, err := h.store.GetMatchesByIDs(ctx, []string{c.Param("id")})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to get match: %v", err)})
+		return
+	}
+	if len(matches) == 0 {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "match not found"})
+		return
+	}
+
+	match, err := reconcile.Override(ctx, h.store, &models.ScoreOverride{
+		MatchID:   c.Param("id"),
+		HomeScore: req.HomeScore,
+		AwayScore: req.AwayScore,
+		Status:    status,
+		Note:      req.Note,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to override score: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, match)
+}
+
+// ClearScoreOverride removes a match's override, restoring the provider result.
+func (h *Handler) ClearScoreOverride(c *gin.Context) {
+	match, err := reconcile.ClearOverride(c.Request.Context(), h.store, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to clear override: %v", err)})
+		return
+	}
+	if match == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "match has no override"})
+		return
+	}
+	c.JSON(http.StatusOK, match)
+}
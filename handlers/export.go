@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"rugby-live-api/services/export"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportResponse struct {
+	Files []export.Result `json:"files"`
+}
+
+func exportParams(c *gin.Context) (export.Format, export.Filter, bool) {
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return "", export.Filter{}, false
+	}
+	return format, export.Filter{SeasonID: c.Query("season_id"), LeagueID: c.Query("league_id")}, true
+}
+
+// ExportTable streams one table as an attachment.
+func (h *Handler) ExportTable(c *gin.Context) {
+	format, filter, ok := exportParams(c)
+	if !ok {
+		return
+	}
+	tables, err := export.LookupTables([]string{c.Param("table")})
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		return
+	}
+	table := tables[0]
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, table.Name, format.Extension()))
+	c.Status(http.StatusOK)
+
+	// The status is already sent once rows start flowing, so a failure part
+	// way through can only be logged.
+	if _, err := export.Write(c.Request.Context(), h.store, table, format, filter, c.Writer); err != nil {
+		h.log.ErrorContext(c.Request.Context(), "Error exporting table", "table", table.Name, "error", err)
+	}
+}
+
+// ExportToStorage writes the requested tables, or all of them, to the object
+// store under exports/<timestamp>/.
+func (h *Handler) ExportToStorage(c *gin.Context) {
+	format, filter, ok := exportParams(c)
+	if !ok {
+		return
+	}
+	var names []string
+	if list := c.Query("tables"); list != "" {
+		names = strings.Split(list, ",")
+	}
+	tables, err := export.LookupTables(names)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	prefix := "exports/" + time.Now().UTC().Format("20060102T150405Z")
+	results, err := export.ToStorage(c.Request.Context(), h.store, h.apiClient, tables, format, filter, prefix)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to export: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, ExportResponse{Files: results})
+}
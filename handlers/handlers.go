@@ -1,14 +1,23 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
 	"net/http"
+	"rugby-live-api/config"
 	"rugby-live-api/db"
 	"rugby-live-api/models"
 	"rugby-live-api/services"
+	"rugby-live-api/services/breaker"
+	"rugby-live-api/services/kickoff"
+	"rugby-live-api/services/live"
 	"rugby-live-api/services/rapidapi"
+	"rugby-live-api/services/reconcile"
+	"rugby-live-api/services/webhooks"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -17,83 +26,159 @@ import (
 type Handler struct {
 	apiClient *services.APIClient
 	store     *db.Store
+	log       *slog.Logger
 	rapidAPI  *rapidapi.Client
+	webhooks  *webhooks.Dispatcher
+	live      *live.Hub
+
+	// adminToken is the bearer token RequireAdmin checks for.
+	adminToken string
+
+	// draining is set once shutdown begins.
+	draining atomic.Bool
 }
 
-func NewHandler(store *db.Store) *Handler {
+// NewHandler returns a Handler fetching from providers through apiClient,
+// which it shares with the caller so rate limits hold process-wide, and
+// replaying dead letters through dispatcher, which delivers the service's
+// webhooks.
+func NewHandler(store *db.Store, cfg *config.Config, apiClient *services.APIClient, dispatcher *webhooks.Dispatcher) *Handler {
 	return &Handler{
-		apiClient: services.NewAPIClient(),
+		apiClient: apiClient,
 		store:     store,
-		rapidAPI:  rapidapi.NewClient(),
+		log:       store.Logger(),
+		rapidAPI:  rapidapi.NewClient(cfg.Providers.RapidAPI, store.Logger()),
+		webhooks:  dispatcher,
+		live:      live.NewHub(store),
+
+		adminToken: cfg.Server.AdminToken,
 	}
 }
 
-func (h *Handler) GetMatches(c *gin.Context) {
-	matches, err := h.apiClient.FetchFromAPISports()
+// timezoneParam reads the caller's IANA timezone from the tz query parameter,
+// defaulting to UTC.
+func timezoneParam(c *gin.Context) (*time.Location, bool) {
+	loc, err := kickoff.LoadLocation(c.Query("tz"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch matches: " + err.Error()})
-		return
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return nil, false
 	}
+	return loc, true
+}
 
-	// Store each match and its related data
-	for _, match := range matches {
-		// Insert country first
-		if err := h.store.UpsertCountry(&match.League.Country); err != nil {
-			log.Printf("Error upserting country: %v", err)
-			continue
-		}
-
-		if err := h.store.UpsertLeague(match.League); err != nil {
-			log.Printf("Error upserting league: %v", err)
-			continue
-		}
-		if err := h.store.UpsertTeam(match.HomeTeam); err != nil {
-			log.Printf("Error upserting home team: %v", err)
-			continue
-		}
-		if err := h.store.UpsertTeam(match.AwayTeam); err != nil {
-			log.Printf("Error upserting away team: %v", err)
-			continue
-		}
+// providerError answers 503, with Retry-After, while the provider's circuit
+// is open and 500 for anything else. msg prefixes the error.
+func providerError(c *gin.Context, msg string, err error) {
+	var open *breaker.OpenError
+	if errors.As(err, &open) {
+		seconds := max(int(math.Ceil(open.RetryAfter.Seconds())), 1)
+		c.Header("Retry-After", strconv.Itoa(seconds))
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: fmt.Sprintf("%s: %v", msg, err)})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("%s: %v", msg, err)})
+}
 
-		// Set IDs for the match
-		match.LeagueID = match.League.ID
-		match.HomeTeamID = match.HomeTeam.ID
-		match.AwayTeamID = match.AwayTeam.ID
+func (h *Handler) GetMatches(c *gin.Context) {
+	ctx := c.Request.Context()
+	loc, ok := timezoneParam(c)
+	if !ok {
+		return
+	}
 
-		if err := h.store.UpsertMatch(&match); err != nil {
-			log.Printf("Error upserting match: %v", err)
-			continue
-		}
+	matches, err := h.apiClient.FetchFromAPISports(ctx, loc)
+	if errors.Is(err, breaker.ErrOpen) {
+		h.storedTodaysMatches(c, loc, err)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch matches: " + err.Error()})
+		return
+	}
 
-		// Create API mapping for the match
-		apiMapping := &models.MatchAPIMapping{
-			MatchID:    match.ID,
-			APIName:    "api_sports",
-			APIMatchID: fmt.Sprintf("%d", match.APISportsID),
-			CreatedAt:  time.Now(),
-			UpdatedAt:  time.Now(),
+	// Store each match with its country, league and teams as one unit, so a
+	// failure leaves none of them half-written.
+	report := db.NewIngestionReport()
+	for i := range matches {
+		if ctx.Err() != nil {
+			// The client has gone away, so nobody is waiting on the rest.
+			return
 		}
+		match := &matches[i]
+		h.store.Unit(ctx, report, db.UnitMatch, strconv.Itoa(match.APISportsID), func(tx *db.Store) error {
+			if err := tx.UpsertCountry(ctx, &match.League.Country); err != nil {
+				return db.Step("country", err)
+			}
+			if err := tx.UpsertLeague(ctx, match.League); err != nil {
+				return db.Step("league", err)
+			}
+			if err := tx.UpsertTeam(ctx, match.HomeTeam); err != nil {
+				return db.Step("home team", err)
+			}
+			if err := tx.UpsertTeam(ctx, match.AwayTeam); err != nil {
+				return db.Step("away team", err)
+			}
+
+			// Set IDs for the match
+			match.LeagueID = match.League.ID
+			match.HomeTeamID = match.HomeTeam.ID
+			match.AwayTeamID = match.AwayTeam.ID
+
+			if err := reconcile.Record(ctx, tx, reconcile.SourceAPISports, match); err != nil {
+				return db.Step("match", err)
+			}
+
+			// Create API mapping for the match
+			apiMapping := &models.MatchAPIMapping{
+				MatchID:    match.ID,
+				APIName:    "api_sports",
+				APIMatchID: fmt.Sprintf("%d", match.APISportsID),
+				CreatedAt:  time.Now(),
+				UpdatedAt:  time.Now(),
+			}
+			return db.Step("match mapping", tx.UpsertMatchAPIMapping(ctx, apiMapping))
+		})
+	}
+	h.log.InfoContext(ctx, "Stored today's matches", "committed", report.Committed, "failed", len(report.Failed))
 
-		if err := h.store.UpsertMatchAPIMapping(apiMapping); err != nil {
-			log.Printf("Error upserting match API mapping: %v", err)
-			continue
-		}
+	for i := range matches {
+		matches[i] = matches[i].Localize(loc)
 	}
+	c.JSON(http.StatusOK, MatchesResponse{Matches: matches, DataFreshness: FreshnessLive, Report: report})
+}
+
+// storedTodaysMatches answers GET /matches from the database while API
+// Sports' circuit is open, flagging the matches as stale.
+func (h *Handler) storedTodaysMatches(c *gin.Context, loc *time.Location, cause error) {
+	ctx := c.Request.Context()
+	h.log.WarnContext(ctx, "API Sports unavailable; serving stored matches", "error", cause)
 
-	c.JSON(http.StatusOK, matches)
+	from, to, err := kickoff.DayBounds(kickoff.Today(loc), loc)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	matches, err := h.store.GetMatchesBetween(ctx, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch stored matches: " + err.Error()})
+		return
+	}
+	for i := range matches {
+		matches[i] = matches[i].Localize(loc)
+	}
+	c.JSON(http.StatusOK, MatchesResponse{Matches: matches, DataFreshness: FreshnessStale})
 }
 
 func (h *Handler) GetLiveMatches(c *gin.Context) {
 	// espnData, err := h.apiClient.FetchFromESPN()
 	// if err != nil {
-	// 	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ESPN data: " + err.Error()})
+	// 	c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch ESPN data: " + err.Error()})
 	// 	return
 	// }
 
 	// urData, err := h.apiClient.FetchFromUltimateRugby()
 	// if err != nil {
-	// 	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch Ultimate Rugby data: " + err.Error()})
+	// 	c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch Ultimate Rugby data: " + err.Error()})
 	// 	return
 	// }
 
@@ -105,13 +190,13 @@ func (h *Handler) GetUpcomingMatches(c *gin.Context) {
 	// For now, returns same data - you can filter by status later
 	// espnData, err := h.apiClient.FetchFromESPN()
 	// if err != nil {
-	// 	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ESPN data: " + err.Error()})
+	// 	c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch ESPN data: " + err.Error()})
 	// 	return
 	// }
 
 	// urData, err := h.apiClient.FetchFromUltimateRugby()
 	// if err != nil {
-	// 	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch Ultimate Rugby data: " + err.Error()})
+	// 	c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch Ultimate Rugby data: " + err.Error()})
 	// 	return
 	// }
 
@@ -120,9 +205,9 @@ func (h *Handler) GetUpcomingMatches(c *gin.Context) {
 }
 
 func (h *Handler) GetCountries(c *gin.Context) {
-	countries, err := h.store.GetCountries()
+	countries, err := h.store.GetCountries(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch countries: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch countries: " + err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, countries)
@@ -135,31 +220,33 @@ func (h *Handler) RefreshCountries(c *gin.Context) {
 		updateFlags = c.Query("update_flags") == "true"
 	}
 
-	changes, err := h.apiClient.FetchAndStoreCountries(h.store, updateFlags)
+	changes, err := h.apiClient.FetchAndStoreCountries(c.Request.Context(), h.store, updateFlags)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh countries: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to refresh countries: " + err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":       "Countries refresh completed",
-		"changes":       changes,
-		"total_changes": len(changes),
+	c.JSON(http.StatusOK, CountriesRefreshResponse{
+		Message:      "Countries refresh completed",
+		Changes:      changes,
+		TotalChanges: len(changes),
 	})
 }
 
 func (h *Handler) RefreshLeagues(c *gin.Context) {
-	log.Println("Refreshing leagues update images: ", c.Query("update_images"))
+	h.log.InfoContext(c.Request.Context(), "Refreshing leagues", "update_images", c.Query("update_images"))
 	updateImages := c.Query("update_images") == "true"
-	changes, err := h.apiClient.FetchAndStoreLeagues(h.store, updateImages)
+	report := db.NewIngestionReport()
+	changes, err := h.apiClient.FetchAndStoreLeagues(c.Request.Context(), h.store, updateImages, report)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh leagues: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to refresh leagues: " + err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{
-		"message":       "Leagues refresh completed",
-		"changes":       changes,
-		"total_changes": len(changes),
+	c.JSON(http.StatusOK, LeaguesRefreshResponse{
+		Message:      "Leagues refresh completed",
+		Changes:      changes,
+		TotalChanges: len(changes),
+		Report:       report,
 	})
 }
 
@@ -177,45 +264,47 @@ func (h *Handler) RefreshTeams(c *gin.Context) {
 
 	// Validate league parameters
 	if params.LeagueID != "" && params.Season == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "season parameter is required when searching by league"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "season parameter is required when searching by league"})
 		return
 	}
 
-	changes, failedTeams, err := h.apiClient.FetchAndStoreTeams(h.store, updateImages, params)
+	report := db.NewIngestionReport()
+	changes, failedTeams, err := h.apiClient.FetchAndStoreTeams(c.Request.Context(), h.store, updateImages, params, report)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh teams: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to refresh teams: " + err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{
-		"message":        "Teams refresh completed",
-		"changes":        changes,
-		"total_changes":  len(changes),
-		"failed_teams":   failedTeams,
-		"total_failures": len(failedTeams),
+	c.JSON(http.StatusOK, TeamsRefreshResponse{
+		Message:       "Teams refresh completed",
+		Changes:       changes,
+		TotalChanges:  len(changes),
+		FailedTeams:   failedTeams,
+		TotalFailures: len(failedTeams),
+		Report:        report,
 	})
 }
 
 func (h *Handler) UpdateTeamImages(c *gin.Context) {
-	if err := h.apiClient.UpdateTeamImages(h.store); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update team images: " + err.Error()})
+	if err := h.apiClient.UpdateTeamImages(c.Request.Context(), h.store); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update team images: " + err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Team images updated successfully"})
+	c.JSON(http.StatusOK, MessageResponse{Message: "Team images updated successfully"})
 }
 
 func (h *Handler) GetESPNLeagues(c *gin.Context) {
-	leagues, err := h.apiClient.ScrapeESPNLeagues()
+	leagues, err := h.apiClient.ScrapeESPNLeagues(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to scrape leagues: %v", err)})
+		providerError(c, "Failed to scrape leagues", err)
 		return
 	}
 	c.JSON(http.StatusOK, leagues)
 }
 
 func (h *Handler) GetWikidataTeams(c *gin.Context) {
-	teams, err := h.apiClient.GetWikidataTeams()
+	teams, err := h.apiClient.GetWikidataTeams(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to fetch teams: %v", err)})
+		providerError(c, "Failed to fetch teams", err)
 		return
 	}
 	c.JSON(http.StatusOK, teams)
@@ -224,13 +313,13 @@ func (h *Handler) GetWikidataTeams(c *gin.Context) {
 func (h *Handler) SearchWikidataTeams(c *gin.Context) {
 	name := c.Query("name")
 	if name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "name parameter is required"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "name parameter is required"})
 		return
 	}
 
-	team, err := h.apiClient.SearchWikidataTeam(name)
+	team, err := h.apiClient.SearchWikidataTeam(c.Request.Context(), name)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to search teams: %v", err)})
+		providerError(c, "Failed to search teams", err)
 		return
 	}
 	c.JSON(http.StatusOK, team)
@@ -244,7 +333,7 @@ func (h *Handler) GetRugbyDBTeams(c *gin.Context) {
 	if c.Request.Method == "POST" {
 		var req services.TeamCreateRequest
 		if err := c.BindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
 			return
 		}
 		priorityTeams = req.Names
@@ -254,9 +343,9 @@ func (h *Handler) GetRugbyDBTeams(c *gin.Context) {
 		countryFilter = c.Query("country")
 	}
 
-	teams, err := h.apiClient.GetRugbyDBTeams(h.store, priorityTeams, countryFilter)
+	teams, err := h.apiClient.GetRugbyDBTeams(c.Request.Context(), h.store, priorityTeams, countryFilter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to fetch teams: %v", err)})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to fetch teams: %v", err)})
 		return
 	}
 	c.JSON(http.StatusOK, teams)
@@ -265,23 +354,63 @@ func (h *Handler) GetRugbyDBTeams(c *gin.Context) {
 func (h *Handler) CreateRugbyDBTeams(c *gin.Context) {
 	var req services.TeamCreateRequest
 	if err := c.BindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
 		return
 	}
 
-	teams, err := h.apiClient.GetRugbyDBTeams(h.store, req.Names, req.Country)
+	teams, err := h.apiClient.GetRugbyDBTeams(c.Request.Context(), h.store, req.Names, req.Country)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to process teams: %v", err)})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to process teams: %v", err)})
 		return
 	}
 
 	c.JSON(http.StatusOK, teams)
 }
 
+func (h *Handler) GetRugbyDBLeagues(c *gin.Context) {
+	ctx := c.Request.Context()
+	isDryRun := c.DefaultQuery("dry_run", "false") == "true"
+	report := db.NewIngestionReport()
+	leagues, err := h.apiClient.GetLeaguesByYear(ctx, h.store, c.Param("year"), isDryRun, report)
+	if err != nil {
+		providerError(c, "Failed to scrape leagues", err)
+		return
+	}
+	// The response is the leagues alone, so failed units are only logged.
+	h.log.InfoContext(ctx, "Stored RugbyDB leagues", "committed", report.Committed, "failed", len(report.Failed))
+	c.JSON(http.StatusOK, leagues)
+}
+
+func (h *Handler) ScrapeRugbyDBMatches(c *gin.Context) {
+	year := 0
+	if y := c.Query("year"); y != "" {
+		var err error
+		if year, err = strconv.Atoi(y); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("invalid year %q", y)})
+			return
+		}
+	}
+	isDryRun := c.DefaultQuery("dry_run", "false") == "true"
+
+	result, err := h.apiClient.ScrapeRugbyDBMatches(c.Request.Context(), h.store, year, c.Query("season_id"), isDryRun)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to scrape matches: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
 func (h *Handler) MapAPISportsLeagues(c *gin.Context) {
-	results, err := h.apiClient.MapAPISportsLeagues(h.store)
+	ctx := c.Request.Context()
+	freshness := FreshnessLive
+	results, err := h.apiClient.MapAPISportsLeagues(ctx, h.store)
+	if errors.Is(err, breaker.ErrOpen) {
+		h.log.WarnContext(ctx, "API Sports unavailable; serving stored league mappings", "error", err)
+		freshness = FreshnessStale
+		results, err = h.apiClient.StoredLeagueMappings(ctx, h.store)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to map leagues: %v", err)})
+		providerError(c, "Failed to map leagues", err)
 		return
 	}
 
@@ -297,41 +426,37 @@ func (h *Handler) MapAPISportsLeagues(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"matched":   matched,
-		"unmatched": unmatched,
-		"stats": gin.H{
-			"total":      len(results),
-			"matched":    len(matched),
-			"unmatched":  len(unmatched),
-			"match_rate": float64(len(matched)) / float64(len(results)),
-		},
+	c.JSON(http.StatusOK, LeagueMappingResponse{
+		Matched:       matched,
+		Unmatched:     unmatched,
+		Stats:         newMappingStats(len(matched), len(unmatched)),
+		DataFreshness: freshness,
 	})
 }
 
 func (h *Handler) GetLeagueIDsByYear(c *gin.Context) {
 	year := c.Param("year")
 	if year == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "year parameter is required"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "year parameter is required"})
 		return
 	}
 
-	mappings, err := h.apiClient.GetLeagueIDsByYear(year, h.store)
+	mappings, err := h.apiClient.GetLeagueIDsByYear(c.Request.Context(), year, h.store)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to get league IDs: %v", err)})
+		providerError(c, "Failed to get league IDs", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"year":    year,
-		"leagues": mappings,
+	c.JSON(http.StatusOK, LeagueIDsResponse{
+		Year:    year,
+		Leagues: mappings,
 	})
 }
 
 func (h *Handler) GetMatchesByLeague(c *gin.Context) {
 	leagueID := c.Query("league_id")
 	if leagueID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "league_id parameter is required"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "league_id parameter is required"})
 		return
 	}
 
@@ -346,36 +471,54 @@ func (h *Handler) GetMatchesByLeague(c *gin.Context) {
 		dateParam = date
 	}
 
-	matches, dailyMatchesList, err := h.apiClient.GetMatchesByLeague(leagueID, date, season,
-		services.APIParams{
-			LeagueID: apiLeagueID,
-			Season:   apiSeason,
-			Date:     dateParam,
-		},
-		h.store)
+	loc, ok := timezoneParam(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	params := services.APIParams{
+		LeagueID: apiLeagueID,
+		Season:   apiSeason,
+		Date:     dateParam,
+		Timezone: loc.String(),
+	}
+	freshness := FreshnessLive
+	report := db.NewIngestionReport()
+	matches, dailyMatchesList, err := h.apiClient.GetMatchesByLeague(ctx, leagueID, date, season, params, h.store, report)
+	if errors.Is(err, breaker.ErrOpen) {
+		h.log.WarnContext(ctx, "API Sports unavailable; serving stored matches", "league_id", leagueID, "error", err)
+		freshness = FreshnessStale
+		report = nil
+		matches, dailyMatchesList, err = h.apiClient.StoredMatchesByLeague(ctx, leagueID, season, params, h.store)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to get matches: %v", err)})
+		providerError(c, "Failed to get matches", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"league_id": leagueID,
-		"date":      date,
-		"season":    season,
-		"api_params": gin.H{
-			"league_id": apiLeagueID,
-			"season":    apiSeason,
-			"date":      apiDate,
+	c.JSON(http.StatusOK, LeagueMatchesResponse{
+		LeagueID: leagueID,
+		Date:     date,
+		Season:   season,
+		Timezone: loc.String(),
+		APIParams: APISportsParams{
+			LeagueID: apiLeagueID,
+			Season:   apiSeason,
+			Date:     apiDate,
 		},
-		"matches":       matches,
-		"daily_matches": dailyMatchesList,
+		Matches:       matches,
+		DailyMatches:  dailyMatchesList,
+		DataFreshness: freshness,
+		Report:        report,
 	})
 }
 
 func (h *Handler) GetRugbyLiveCompetitions(c *gin.Context) {
-	mappings, err := h.rapidAPI.MapCompetitionsToLeagues(h.store)
+	report := db.NewIngestionReport()
+	mappings, err := h.rapidAPI.MapCompetitionsToLeagues(c.Request.Context(), h.store, report)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to fetch competitions: %v", err)})
+		providerError(c, "Failed to fetch competitions", err)
 		return
 	}
 
@@ -391,14 +534,22 @@ func (h *Handler) GetRugbyLiveCompetitions(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"matched":   matched,
-		"unmatched": unmatched,
-		"stats": gin.H{
-			"total":      len(mappings),
-			"matched":    len(matched),
-			"unmatched":  len(unmatched),
-			"match_rate": float64(len(matched)) / float64(len(mappings)),
-		},
+	c.JSON(http.StatusOK, CompetitionMappingResponse{
+		Matched:   matched,
+		Unmatched: unmatched,
+		Stats:     newMappingStats(len(matched), len(unmatched)),
+		Report:    report,
 	})
 }
+
+// SyncRugbyLiveMatches merges fixtures and results, or live scores when
+// live=true, for every active rugby-live-data season into matches.
+func (h *Handler) SyncRugbyLiveMatches(c *gin.Context) {
+	live := c.DefaultQuery("live", "false") == "true"
+	result, err := h.rapidAPI.SyncMatches(c.Request.Context(), h.store, live)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to sync matches: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"rugby-live-api/db"
+	"rugby-live-api/services/breaker"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readinessTimeout bounds all of a readiness probe's checks together.
+const readinessTimeout = 3 * time.Second
+
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+// ReadinessResponse reports each dependency's check as "ok" or the reason it
+// failed. Status is "ok" only when every check passed. Circuits gives the
+// circuit breaker state of each provider host contacted so far; an open one
+// degrades responses but doesn't make the service unready.
+type ReadinessResponse struct {
+	Status   string            `json:"status"`
+	Checks   map[string]string `json:"checks"`
+	Circuits map[string]string `json:"circuits"`
+}
+
+// Healthz reports that the process is up and serving requests.
+func (h *Handler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthResponse{Status: "ok"})
+}
+
+// Readyz reports whether the service can take traffic: the database answers
+// and has every migration this binary expects, and object storage is
+// reachable. It answers 503 while any check fails and once shutdown begins.
+func (h *Handler) Readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	resp := ReadinessResponse{Status: "ok", Checks: make(map[string]string), Circuits: make(map[string]string)}
+	check := func(name string, err error) {
+		if err != nil {
+			resp.Status = "unavailable"
+			resp.Checks[name] = err.Error()
+			h.log.WarnContext(ctx, "Readiness check failed", "check", name, "error", err)
+			return
+		}
+		resp.Checks[name] = "ok"
+	}
+
+	if h.draining.Load() {
+		check("shutdown", fmt.Errorf("shutting down"))
+	}
+	dbErr := h.store.Ping(ctx)
+	check("database", dbErr)
+	if dbErr == nil {
+		check("migrations", h.checkMigrations(ctx))
+	}
+	check("storage", h.apiClient.PingStorage(ctx))
+	for host, state := range breaker.States() {
+		resp.Circuits[host] = state.String()
+	}
+
+	status := http.StatusOK
+	if resp.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, resp)
+}
+
+// checkMigrations fails when the database is behind the migrations embedded
+// in the binary. A database ahead of them, as during a rolling deploy, passes.
+func (h *Handler) checkMigrations(ctx context.Context) error {
+	version, err := h.store.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if latest := db.LatestMigrationVersion(); version < latest {
+		return fmt.Errorf("database at migration version %d, expected %d", version, latest)
+	}
+	return nil
+}
+
+// Drain fails readiness checks from now on, so load balancers stop routing
+// to this instance, and disconnects live WebSocket clients, which would
+// otherwise hold the server open. Call it when shutdown begins.
+func (h *Handler) Drain() {
+	h.draining.Store(true)
+	h.live.Close()
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"rugby-live-api/services/hierarchy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetLeagueTree returns a league with its parent competitions and every
+// competition played within it.
+func (h *Handler) GetLeagueTree(c *gin.Context) {
+	tree, err := hierarchy.Tree(c.Request.Context(), h.store, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to get league tree: %v", err)})
+		return
+	}
+	if tree == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "league not found"})
+		return
+	}
+	c.JSON(http.StatusOK, tree)
+}
+
+// GetLeagueLineage returns the names a league has been played under, from
+// its earliest predecessor to its latest successor.
+func (h *Handler) GetLeagueLineage(c *gin.Context) {
+	lineage, err := hierarchy.Lineage(c.Request.Context(), h.store, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to get league lineage: %v", err)})
+		return
+	}
+	if lineage == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "league not found"})
+		return
+	}
+	c.JSON(http.StatusOK, lineage)
+}
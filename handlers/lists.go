@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"rugby-live-api/db"
+	"rugby-live-api/models"
+	"rugby-live-api/services/kickoff"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listOptions reads the limit, cursor, sort and q query parameters shared by
+// every list endpoint.
+func listOptions(c *gin.Context) (db.ListOptions, bool) {
+	opts := db.ListOptions{
+		Cursor: c.Query("cursor"),
+		Sort:   c.Query("sort"),
+		Search: c.Query("q"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "limit must be a positive integer"})
+			return opts, false
+		}
+		opts.Limit = n
+	}
+	return opts, true
+}
+
+func intParam(c *gin.Context, name string) (*int, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("%s must be an integer", name)})
+		return nil, false
+	}
+	return &n, true
+}
+
+func boolParam(c *gin.Context, name string) (*bool, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("%s must be true or false", name)})
+		return nil, false
+	}
+	return &b, true
+}
+
+// listError answers 400 for bad paging options and 500 for anything else.
+func listError(c *gin.Context, what string, err error) {
+	if errors.Is(err, db.ErrInvalidListOptions) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to list %s: %v", what, err)})
+}
+
+func (h *Handler) ListTeams(c *gin.Context) {
+	opts, ok := listOptions(c)
+	if !ok {
+		return
+	}
+	page, err := h.store.ListTeams(c.Request.Context(), db.TeamFilter{ListOptions: opts, CountryCode: c.Query("country")})
+	if err != nil {
+		listError(c, "teams", err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *Handler) ListLeagues(c *gin.Context) {
+	opts, ok := listOptions(c)
+	if !ok {
+		return
+	}
+	filter := db.LeagueFilter{
+		ListOptions: opts,
+		CountryCode: c.Query("country"),
+		Gender:      c.Query("gender"),
+		ParentID:    c.Query("parent_id"),
+		Tourist:     c.Query("tourist"),
+		Host:        c.Query("host"),
+	}
+	if filter.Tier, ok = intParam(c, "tier"); !ok {
+		return
+	}
+	if filter.International, ok = boolParam(c, "international"); !ok {
+		return
+	}
+	if filter.AllTime, ok = boolParam(c, "all_time"); !ok {
+		return
+	}
+
+	page, err := h.store.ListLeagues(c.Request.Context(), filter)
+	if err != nil {
+		listError(c, "leagues", err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *Handler) ListSeasons(c *gin.Context) {
+	opts, ok := listOptions(c)
+	if !ok {
+		return
+	}
+	filter := db.SeasonFilter{ListOptions: opts, LeagueID: c.Query("league_id")}
+	if filter.Year, ok = intParam(c, "year"); !ok {
+		return
+	}
+	if filter.Current, ok = boolParam(c, "current"); !ok {
+		return
+	}
+
+	page, err := h.store.ListSeasons(c.Request.Context(), filter)
+	if err != nil {
+		listError(c, "seasons", err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// ListMatches returns stored matches with dates and times in the caller's
+// timezone. date selects a calendar day in that timezone, so a 01:00 kick-off
+// in Auckland shows up on the right day in London; from and to bound kick-off
+// as RFC 3339 timestamps. With none of the three it lists today's matches, as
+// the endpoint always has.
+func (h *Handler) ListMatches(c *gin.Context) {
+	loc, ok := timezoneParam(c)
+	if !ok {
+		return
+	}
+	opts, ok := listOptions(c)
+	if !ok {
+		return
+	}
+
+	filter := db.MatchFilter{
+		ListOptions: opts,
+		LeagueID:    c.Query("league_id"),
+		TeamID:      c.Query("team_id"),
+	}
+	if status := c.Query("status"); status != "" {
+		parsed, err := models.ParseMatchStatus(status)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		filter.Status = parsed
+	}
+	date := c.Query("date")
+	if date == "" && c.Query("from") == "" && c.Query("to") == "" {
+		date = kickoff.Today(loc)
+	}
+	if date != "" {
+		from, to, err := kickoff.DayBounds(date, loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		filter.From, filter.To = from, to
+	}
+	for name, bound := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("%s must be an RFC 3339 timestamp", name)})
+			return
+		}
+		*bound = t
+	}
+
+	page, err := h.store.ListMatches(c.Request.Context(), filter)
+	if err != nil {
+		listError(c, "matches", err)
+		return
+	}
+	for i := range page.Items {
+		page.Items[i] = page.Items[i].Localize(loc)
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+type StatusHistoryResponse struct {
+	MatchID string                `json:"match_id"`
+	Status  models.MatchStatus    `json:"status"`
+	History []models.StatusChange `json:"history"`
+}
+
+// GetMatchStatusHistory returns a match's current status and every status
+// change recorded for it, oldest first.
+func (h *Handler) GetMatchStatusHistory(c *gin.Context) {
+	ctx := c.Request.Context()
+	matches, err := h.store.GetMatchesByIDs(ctx, []string{c.Param("id")})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to get match: %v", err)})
+		return
+	}
+	if len(matches) == 0 {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "match not found"})
+		return
+	}
+	history, err := h.store.GetStatusHistory(ctx, matches[0].ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to get status history: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, StatusHistoryResponse{MatchID: matches[0].ID, Status: matches[0].Status, History: history})
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"rugby-live-api/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LiveWS upgrades to the live match WebSocket. Clients send subscribe and
+// unsubscribe messages naming match_ids, league_ids and team_ids, and are
+// sent a snapshot of those matches followed by deltas as they change.
+func (h *Handler) LiveWS(c *gin.Context) {
+	h.live.ServeWS(c.Writer, c.Request)
+}
+
+// LiveEvents forwards published events to live WebSocket clients. Pass it to
+// events.Subscribe.
+func (h *Handler) LiveEvents(e events.Event) {
+	h.live.Handle(e)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"rugby-live-api/logging"
+	"rugby-live-api/metrics"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries a request's correlation ID. A caller's own ID is
+// kept so logs can be traced across services; otherwise one is generated.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestLogger tags each request's context with its ID, which every log
+// record written with that context carries, and logs the request once it is
+// served. Only the path is logged, since query strings may hold keys.
+func RequestLogger(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > 64 {
+			id = logging.NewID()
+		}
+		c.Header(RequestIDHeader, id)
+		ctx := logging.WithRequestID(c.Request.Context(), id)
+		c.Request = c.Request.WithContext(ctx)
+
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		logger.LogAttrs(ctx, level, "Request",
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Duration("duration", time.Since(start)),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		)
+	}
+}
+
+// RequestMetrics records each request's latency and status against the route
+// it matched. Requests that match no route share one label, so probing
+// unknown paths cannot grow the number of series.
+func RequestMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// RequireAdmin lets a request through only when it carries the configured
+// admin token as "Authorization: Bearer <token>". With no token configured
+// every request is refused, so admin routes are never open by default.
+func (h *Handler) RequireAdmin(c *gin.Context) {
+	if h.adminToken == "" {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{Error: "admin API is disabled; set ADMIN_TOKEN to enable it"})
+		return
+	}
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) != 1 {
+		c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{Error: "admin token required"})
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireAdmin(t *testing.T) {
+	const token = "0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		name, configured, header string
+		want                     int
+	}{
+		{"disabled", "", "Bearer " + token, http.StatusForbidden},
+		{"disabled without header", "", "", http.StatusForbidden},
+		{"no header", token, "", http.StatusUnauthorized},
+		{"wrong token", token, "Bearer " + token[1:] + "x", http.StatusUnauthorized},
+		{"not bearer", token, "Basic " + token, http.StatusUnauthorized},
+		{"bare token", token, token, http.StatusUnauthorized},
+		{"right token", token, "Bearer " + token, http.StatusOK},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		h := &Handler{adminToken: tt.configured}
+		router := gin.New()
+		router.GET("/admin", h.RequireAdmin, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		req := httptest.NewRequest("GET", "/admin", nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"rugby-live-api/db"
+	"rugby-live-api/models"
+	"rugby-live-api/services"
+	"rugby-live-api/services/rapidapi"
+)
+
+// Response bodies returned by the handlers. They are named, rather than
+// built with gin.H, so the OpenAPI document and generated client can describe
+// them.
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// Values of DataFreshness.
+const (
+	// FreshnessLive marks data just fetched from its provider.
+	FreshnessLive = "live"
+	// FreshnessStale marks stored data served because its provider's
+	// circuit is open.
+	FreshnessStale = "stale"
+)
+
+// MatchesResponse carries today's matches and whether they came from the
+// provider or, while it is unavailable, from the database. Report describes
+// storing live matches.
+type MatchesResponse struct {
+	Matches       []models.Match      `json:"matches"`
+	DataFreshness string              `json:"data_freshness"`
+	Report        *db.IngestionReport `json:"report,omitempty"`
+}
+
+type CountriesRefreshResponse struct {
+	Message      string                   `json:"message"`
+	Changes      []services.CountryChange `json:"changes"`
+	TotalChanges int                      `json:"total_changes"`
+}
+
+type LeaguesRefreshResponse struct {
+	Message      string                  `json:"message"`
+	Changes      []services.LeagueChange `json:"changes"`
+	TotalChanges int                     `json:"total_changes"`
+	Report       *db.IngestionReport     `json:"report"`
+}
+
+type TeamsRefreshResponse struct {
+	Message       string                `json:"message"`
+	Changes       []services.TeamChange `json:"changes"`
+	TotalChanges  int                   `json:"total_changes"`
+	FailedTeams   []services.FailedTeam `json:"failed_teams"`
+	TotalFailures int                   `json:"total_failures"`
+	Report        *db.IngestionReport   `json:"report"`
+}
+
+// MappingStats summarises how many external competitions matched one of ours.
+type MappingStats struct {
+	Total     int     `json:"total"`
+	Matched   int     `json:"matched"`
+	Unmatched int     `json:"unmatched"`
+	MatchRate float64 `json:"match_rate"`
+}
+
+func newMappingStats(matched, unmatched int) MappingStats {
+	stats := MappingStats{
+		Total:     matched + unmatched,
+		Matched:   matched,
+		Unmatched: unmatched,
+	}
+	if stats.Total > 0 {
+		stats.MatchRate = float64(matched) / float64(stats.Total)
+	}
+	return stats
+}
+
+// LeagueMappingResponse carries API Sports league mappings. While API Sports
+// is unavailable the stored mappings are served, with DataFreshness stale.
+type LeagueMappingResponse struct {
+	Matched       []services.LeagueMappingResult `json:"matched"`
+	Unmatched     []services.LeagueMappingResult `json:"unmatched"`
+	Stats         MappingStats                   `json:"stats"`
+	DataFreshness string                         `json:"data_freshness"`
+}
+
+type CompetitionMappingResponse struct {
+	Matched   []rapidapi.CompetitionMapping `json:"matched"`
+	Unmatched []rapidapi.CompetitionMapping `json:"unmatched"`
+	Stats     MappingStats                  `json:"stats"`
+	Report    *db.IngestionReport           `json:"report"`
+}
+
+type LeagueIDsResponse struct {
+	Year    string                     `json:"year"`
+	Leagues []services.LeagueIDMapping `json:"leagues"`
+}
+
+// APISportsParams echoes the API Sports overrides a league query was run with.
+type APISportsParams struct {
+	LeagueID string `json:"league_id"`
+	Season   string `json:"season"`
+	Date     string `json:"date"`
+}
+
+// LeagueMatchesResponse carries a league's matches and, as MatchesResponse
+// does, whether they came from API Sports or the database. Report describes
+// storing live matches.
+type LeagueMatchesResponse struct {
+	LeagueID      string                   `json:"league_id"`
+	Date          string                   `json:"date"`
+	Season        string                   `json:"season"`
+	Timezone      string                   `json:"timezone"`
+	APIParams     APISportsParams          `json:"api_params"`
+	Matches       []services.Match         `json:"matches"`
+	DailyMatches  []*services.DailyMatches `json:"daily_matches"`
+	DataFreshness string                   `json:"data_freshness"`
+	Report        *db.IngestionReport      `json:"report,omitempty"`
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"rugby-live-api/services/stats"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxFormMatches = 50
+
+// teamParam loads the team named by the :id path parameter, answering 404
+// when there is none.
+func (h *Handler) teamParam(c *gin.Context) (string, bool) {
+	teams, err := h.store.GetTeamsByIDs(c.Request.Context(), []string{c.Param("id")})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to get team: %v", err)})
+		return "", false
+	}
+	if len(teams) == 0 {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "team not found"})
+		return "", false
+	}
+	return teams[0].ID, true
+}
+
+// GetTeamForm returns a team's last n results, five by default, across every
+// competition.
+func (h *Handler) GetTeamForm(c *gin.Context) {
+	n := 5
+	if value := c.Query("n"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxFormMatches {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("n must be between 1 and %d", maxFormMatches)})
+			return
+		}
+		n = parsed
+	}
+	teamID, ok := h.teamParam(c)
+	if !ok {
+		return
+	}
+
+	results, err := h.store.GetTeamResults(c.Request.Context(), teamID, "", n)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to get results: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, stats.NewTeamForm(teamID, results))
+}
+
+// GetTeamStats returns a team's record in one season, or across every stored
+// season when season_id is omitted.
+func (h *Handler) GetTeamStats(c *gin.Context) {
+	ctx := c.Request.Context()
+	teamID, ok := h.teamParam(c)
+	if !ok {
+		return
+	}
+	seasonID := c.Query("season_id")
+	if seasonID != "" {
+		seasons, err := h.store.GetSeasonsByIDs(ctx, []string{seasonID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to get season: %v", err)})
+			return
+		}
+		if len(seasons) == 0 {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "season not found"})
+			return
+		}
+	}
+
+	results, err := h.store.GetTeamResults(ctx, teamID, seasonID, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to get results: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, stats.Summarise(teamID, seasonID, results))
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"rugby-live-api/events"
+	"rugby-live-api/models"
+	"rugby-live-api/services/webhooks"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookSubscriptionRequest registers a partner endpoint. A secret is
+// generated when none is given; it is only returned by the create call.
+type WebhookSubscriptionRequest struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events"`
+}
+
+type WebhookSubscriptionsResponse struct {
+	Subscriptions []models.WebhookSubscription `json:"subscriptions"`
+}
+
+type WebhookDeadLettersResponse struct {
+	DeadLetters []models.WebhookDeadLetter `json:"dead_letters"`
+}
+
+func idParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "id must be an integer"})
+		return 0, false
+	}
+	return id, true
+}
+
+// CreateWebhookSubscription registers an endpoint for one or more event types.
+func (h *Handler) CreateWebhookSubscription(c *gin.Context) {
+	var req WebhookSubscriptionRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+	if err := webhooks.CheckURL(c.Request.Context(), req.URL); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if len(req.Events) == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("events must list at least one of %v", events.Types)})
+		return
+	}
+	for _, e := range req.Events {
+		if !events.Valid(e) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("unknown event type %q", e)})
+			return
+		}
+	}
+	if req.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to generate secret: %v", err)})
+			return
+		}
+		req.Secret = hex.EncodeToString(secret)
+	}
+
+	sub := models.WebhookSubscription{URL: req.URL, Secret: req.Secret, Events: req.Events, Active: true}
+	if err := h.store.CreateWebhookSubscription(c.Request.Context(), &sub); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to create subscription: %v", err)})
+		return
+	}
+	h.webhooks.Invalidate()
+	c.JSON(http.StatusOK, sub)
+}
+
+// ListWebhookSubscriptions lists subscriptions without their secrets.
+func (h *Handler) ListWebhookSubscriptions(c *gin.Context) {
+	subs, err := h.store.ListWebhookSubscriptions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to list subscriptions: %v", err)})
+		return
+	}
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	c.JSON(http.StatusOK, WebhookSubscriptionsResponse{Subscriptions: subs})
+}
+
+// DeleteWebhookSubscription removes a subscription and its dead letters,
+// returning the subscription without its secret.
+func (h *Handler) DeleteWebhookSubscription(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	sub, err := h.store.DeleteWebhookSubscription(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to delete subscription: %v", err)})
+		return
+	}
+	if sub == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "subscription not found"})
+		return
+	}
+	h.webhooks.Invalidate()
+	sub.Secret = ""
+	c.JSON(http.StatusOK, sub)
+}
+
+// ListWebhookDeadLetters lists deliveries that failed every retry.
+// include_replayed=true adds those since replayed successfully.
+func (h *Handler) ListWebhookDeadLetters(c *gin.Context) {
+	includeReplayed, ok := boolParam(c, "include_replayed")
+	if !ok {
+		return
+	}
+	letters, err := h.store.ListWebhookDeadLetters(c.Request.Context(), includeReplayed != nil && *includeReplayed)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to list dead letters: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, WebhookDeadLettersResponse{DeadLetters: letters})
+}
+
+// ReplayWebhookDeadLetter sends a dead letter again. The response is the dead
+// letter as updated: replayed_at is set when the endpoint accepted it, and
+// last_error explains why not otherwise.
+func (h *Handler) ReplayWebhookDeadLetter(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	letter, err := h.webhooks.Replay(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Failed to replay: %v", err)})
+		return
+	}
+	if letter == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "dead letter not found"})
+		return
+	}
+	c.JSON(http.StatusOK, letter)
+}
@@ -0,0 +1,200 @@
+// Package logging builds the structured logger shared by the store, services
+// and handlers. Records carry the request or job ID found in their context
+// and have API keys, bearer tokens and other secrets redacted before they are
+// written.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"rugby-live-api/config"
+	"strings"
+	"sync"
+)
+
+// Formats.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Redacted replaces secrets in log output.
+const Redacted = "[REDACTED]"
+
+// New returns a logger writing format records at level or above to w.
+func New(w io.Writer, format string, level slog.Level) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+	var h slog.Handler
+	if format == FormatJSON {
+		h = slog.NewJSONHandler(w, opts)
+	} else {
+		h = slog.NewTextHandler(w, opts)
+	}
+	return slog.New(&handler{next: h})
+}
+
+// FromConfig returns a logger writing to stderr as cfg says, with cfg's
+// credentials redacted.
+func FromConfig(cfg *config.Config) *slog.Logger {
+	level, err := cfg.LogLevel()
+	if err != nil {
+		level = slog.LevelInfo
+	}
+	RedactValues(cfg.Secrets()...)
+	return New(os.Stderr, cfg.Log.Format, level)
+}
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	jobIDKey
+)
+
+// NewID returns a random correlation ID.
+func NewID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// WithRequestID returns ctx carrying the ID of the HTTP request it serves.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request ID carried by ctx, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithJobID returns ctx carrying the ID of the background job it runs.
+func WithJobID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, jobIDKey, id)
+}
+
+// JobID returns the job ID carried by ctx, if any.
+func JobID(ctx context.Context) string {
+	id, _ := ctx.Value(jobIDKey).(string)
+	return id
+}
+
+// sensitiveKeys are attribute keys whose values are always redacted.
+var sensitiveKeys = []string{"key", "token", "secret", "password", "authorization", "signature"}
+
+func sensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range sensitiveKeys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+var secretPatterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`), "${1}" + Redacted},
+	{regexp.MustCompile(`(?i)([?&](?:api_?key|key|token|access_token|secret)=)[^&\s"]+`), "${1}" + Redacted},
+	{regexp.MustCompile(`(?i)((?:x-rapidapi-key|x-apisports-key|api[_-]?key|apikey)["']?\s*[:=]\s*["']?)[^\s"',&]+`), "${1}" + Redacted},
+	{regexp.MustCompile(`(://[^:/@\s]+:)[^@\s]+@`), "${1}" + Redacted + "@"},
+}
+
+var (
+	secretsMu sync.RWMutex
+	secrets   []string
+)
+
+// RedactValues adds secrets, such as configured API keys, to be redacted
+// wherever they appear. Values shorter than eight characters are ignored, as
+// they would redact ordinary text.
+func RedactValues(values ...string) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, v := range values {
+		if len(v) >= 8 {
+			secrets = append(secrets, v)
+		}
+	}
+}
+
+// Redact returns s with bearer tokens, API keys in URLs and headers, database
+// passwords and the values passed to RedactValues replaced.
+func Redact(s string) string {
+	secretsMu.RLock()
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, Redacted)
+	}
+	secretsMu.RUnlock()
+	for _, p := range secretPatterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	return s
+}
+
+// handler adds correlation IDs from the record's context and redacts the
+// message and attributes before passing records on.
+type handler struct {
+	next slog.Handler
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, Redact(r.Message), r.PC)
+	if id := RequestID(ctx); id != "" {
+		out.AddAttrs(slog.String("request_id", id))
+	}
+	if id := JobID(ctx); id != "" {
+		out.AddAttrs(slog.String("job_id", id))
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactAttr(a)
+	}
+	return &handler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{next: h.next.WithGroup(name)}
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch {
+	case v.Kind() == slog.KindGroup:
+		group := v.Group()
+		redacted := make([]any, len(group))
+		for i, g := range group {
+			redacted[i] = redactAttr(g)
+		}
+		return slog.Group(a.Key, redacted...)
+	case sensitiveKey(a.Key):
+		return slog.String(a.Key, Redacted)
+	case v.Kind() == slog.KindString:
+		return slog.String(a.Key, Redact(v.String()))
+	case v.Kind() == slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.String(a.Key, Redact(err.Error()))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
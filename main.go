@@ -1,83 +1,300 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
 	"rugby-live-api/config"
 	"rugby-live-api/db"
+	"rugby-live-api/events"
+	"rugby-live-api/graph"
 	"rugby-live-api/handlers"
+	"rugby-live-api/logging"
+	"rugby-live-api/metrics"
+	"rugby-live-api/openapi"
+	"rugby-live-api/routes"
 	"rugby-live-api/services"
+	"rugby-live-api/services/export"
+	"rugby-live-api/services/importer"
+	"rugby-live-api/services/reconcile"
+	"rugby-live-api/services/webhooks"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jmoiron/sqlx"
 )
 
 func main() {
-	// Load environment variables
-	if err := config.LoadConfig(); err != nil {
-		log.Fatal("Error loading config:", err)
+	// The drift check only needs the route table, so it runs before any
+	// config or database is required.
+	if len(os.Args) > 1 && os.Args[1] == "check-openapi" {
+		gin.SetMode(gin.ReleaseMode)
+		router := gin.New()
+		routes.Register(router, &handlers.Handler{}, graph.NewHandler(nil))
+		if err := openapi.CheckRoutes(router.Routes()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println("OpenAPI document matches the registered routes")
+		return
 	}
 
-	// Initialize database
-	database, err := config.InitDB()
+	// Settings come from .env, an optional YAML file and the environment,
+	// and are checked before anything connects. A subcommand only needs the
+	// settings it uses.
+	parts := config.PartAll
+	if len(os.Args) > 1 {
+		if p, ok := commandParts[os.Args[1]]; ok {
+			parts = p
+		}
+	}
+	cfg, err := config.Load(parts)
 	if err != nil {
-		log.Fatal("Error connecting to database:", err)
+		fmt.Fprintln(os.Stderr, "Invalid configuration:")
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
-	defer database.Close()
 
-	// Create store and API client
-	store := db.NewStore(sqlx.NewDb(database, "postgres"))
-	apiClient := services.NewAPIClient()
+	// Everything logs through one logger, which redacts secrets. A
+	// subcommand run is a job, so its records share a job ID.
+	logger := logging.FromConfig(cfg)
+	if len(os.Args) > 1 {
+		logger = logger.With("job", os.Args[1], "job_id", logging.NewID())
+	}
+	slog.SetDefault(logger)
+
+	reconcile.Configure(reconcile.PolicyFromConfig(cfg.Score))
+
+	// SIGINT or SIGTERM cancels a running subcommand, and starts a graceful
+	// shutdown when serving.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Create the store; the API client is created after the subcommands
+	// that only touch the database
+	store, err := db.NewStore(cfg.Database, logger)
+	if err != nil {
+		fatal(logger, "Error connecting to database", err)
+	}
+	defer store.Close()
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		fmt.Println("Running database migrations...")
+		applied, err := db.Migrate(ctx, store.DB)
+		if err != nil {
+			fatal(logger, "Failed to migrate database", err)
+		}
+		for _, name := range applied {
+			fmt.Printf("Applied %s\n", name)
+		}
+		fmt.Printf("Database at migration version %d\n", db.LatestMigrationVersion())
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := runImport(ctx, store, os.Args[2:]); err != nil {
+			fatal(logger, "Failed to import", err)
+		}
+		return
+	}
+
+	// The one provider client for the process: the handlers share it, so its
+	// rate limits and storage bucket set-up are not duplicated.
+	apiClient := services.NewAPIClient(cfg, logger)
 
 	if len(os.Args) > 1 && os.Args[1] == "migrate-storage" {
 		fmt.Println("Migrating storage paths...")
-		if err := apiClient.MigrateStoragePaths(store); err != nil {
-			log.Fatalf("Failed to migrate storage: %v", err)
+		if err := apiClient.MigrateStoragePaths(ctx, store); err != nil {
+			fatal(logger, "Failed to migrate storage", err)
 		}
 		fmt.Println("Storage migration complete")
 		return
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := runExport(ctx, store, apiClient, os.Args[2:]); err != nil {
+			fatal(logger, "Failed to export", err)
+		}
+		return
+	}
+
+	// Deliver webhooks for changes made while serving. The subcommands above
+	// exit as soon as they finish, so they do not send any.
+	dispatcher := webhooks.NewDispatcher(store)
+	dispatcher.Start(4)
+	events.Subscribe(dispatcher.Handle)
+
 	// Initialize router
-	router := gin.Default()
-
-	// Initialize handlers
-	h := handlers.NewHandler(store)
-
-	// Define routes
-	router.GET("/matches", h.GetMatches)
-	router.GET("/countries", h.GetCountries)
-	router.POST("/countries/refresh", h.RefreshCountries)
-	router.POST("/leagues/refresh", h.RefreshLeagues)
-	router.POST("/teams/refresh", h.RefreshTeams)
-	router.POST("/teams/update-images", h.UpdateTeamImages)
-	router.GET("/espn/leagues", h.GetESPNLeagues)
-	router.GET("/wikidata/teams", h.GetWikidataTeams)
-	router.GET("/wikidata/teams/search", h.SearchWikidataTeams)
-	router.POST("/rugbydb/teams", h.GetRugbyDBTeams)
-	router.GET("/rugbydb/leagues/:year", func(c *gin.Context) {
-		yearStr := c.Param("year")
-		dryRun := c.DefaultQuery("dry_run", "false")
-		isDryRun := dryRun == "true"
-		leagues, err := apiClient.GetLeaguesByYear(store, yearStr, isDryRun)
+	router := gin.New()
+	router.Use(handlers.RequestLogger(logger), handlers.RequestMetrics(), gin.Recovery())
+	metrics.RegisterLiveMatches(store.CountMatchesByStatus, logger)
+	h := handlers.NewHandler(store, cfg, apiClient, dispatcher)
+	events.Subscribe(h.LiveEvents)
+	routes.Register(router, h, graph.NewHandler(store))
+	if err := openapi.CheckRoutes(router.Routes()); err != nil {
+		logger.Warn("OpenAPI document is out of date", "error", err)
+	}
+
+	// Serve until SIGINT or SIGTERM, then stop taking new requests and let
+	// in-flight ones and queued webhook deliveries finish before the
+	// deferred store.Close runs.
+	server := &http.Server{Addr: cfg.Server.Addr, Handler: router}
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("Listening", "addr", server.Addr)
+		serveErr <- server.ListenAndServe()
+	}()
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Server failed", "error", err)
+		}
+		return
+	case <-ctx.Done():
+	}
+	stop()
+
+	logger.Info("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	h.Drain()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Error draining requests", "error", err)
+	}
+	if err := dispatcher.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Error draining webhook deliveries", "error", err)
+	}
+	logger.Info("Shut down")
+}
+
+// commandParts lists the settings each subcommand uses beyond the database
+// and logging. Serving, and anything not listed, uses them all.
+var commandParts = map[string]config.Part{
+	"migrate":         0,
+	"import":          0,
+	"migrate-storage": config.PartStorage | config.PartProviders,
+	"export":          config.PartStorage,
+}
+
+// fatal logs err and exits.
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// runExport implements the export subcommand:
+//
+//	export -table matches -format csv -season <id> > matches.csv
+//	export -out storage -format parquet -league <id>
+//
+// Writing to stdout takes a single table; the object store takes a
+// comma-separated list, or every table when -table is omitted.
+func runExport(ctx context.Context, store *db.Store, uploader export.Uploader, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	tableList := fs.String("table", "", "comma-separated tables: "+strings.Join(export.TableNames(), ", "))
+	formatName := fs.String("format", "csv", "csv, jsonl or parquet")
+	seasonID := fs.String("season", "", "only rows belonging to this season")
+	leagueID := fs.String("league", "", "only rows belonging to this league")
+	out := fs.String("out", "-", "\"-\" for stdout or \"storage\" for the object store")
+	prefix := fs.String("prefix", "exports/"+time.Now().UTC().Format("20060102T150405Z"), "object store path prefix")
+	fs.Parse(args)
+
+	format, err := export.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+	var names []string
+	if *tableList != "" {
+		names = strings.Split(*tableList, ",")
+	}
+	tables, err := export.LookupTables(names)
+	if err != nil {
+		return err
+	}
+	filter := export.Filter{SeasonID: *seasonID, LeagueID: *leagueID}
+
+	switch *out {
+	case "-":
+		if len(tables) != 1 {
+			return fmt.Errorf("stdout takes exactly one -table")
+		}
+		w := bufio.NewWriter(os.Stdout)
+		count, err := export.Write(ctx, store, tables[0], format, filter, w)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
+			return err
 		}
-		c.JSON(http.StatusOK, leagues)
-	})
-	router.GET("/leagues/map-api-sports", h.MapAPISportsLeagues)
-	router.GET("/rugbydb/leagues/ids/:year", h.GetLeagueIDsByYear)
-	router.GET("/matches/api-sports/league", h.GetMatchesByLeague)
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		store.Logger().Info("Exported rows", "table", tables[0].Name, "rows", count)
+	case "storage":
+		results, err := export.ToStorage(ctx, store, uploader, tables, format, filter, *prefix)
+		if err != nil {
+			return err
+		}
+		for _, r := range results {
+			fmt.Printf("%s: %d rows -> %s\n", r.Table, r.Rows, r.URL)
+		}
+	default:
+		return fmt.Errorf("unknown -out %q", *out)
+	}
+	return nil
+}
+
+// runImport implements the import subcommand:
+//
+//	import -dry-run results.csv
+//	import results.json
+//
+// Rows that fail validation or name resolution are listed and skipped; the
+// rest are written in one transaction.
+func runImport(ctx context.Context, store *db.Store, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "validate and resolve rows without writing")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: import [-dry-run] <results.csv|results.json>\n")
+		fmt.Fprintf(fs.Output(), "columns: %s\n", strings.Join(importer.Columns, ", "))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one results file")
+	}
+
+	path := fs.Arg(0)
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
 
-	// API routes
-	api := router.Group("/api")
-	{
-		api.GET("/rapidapi/competitions", h.GetRugbyLiveCompetitions)
+	rows, err := importer.ReadFile(path, f)
+	if err != nil {
+		return err
+	}
+	report, err := importer.Import(ctx, store, rows, *dryRun)
+	if err != nil {
+		return err
 	}
 
-	// Start server
-	router.Run(":8080")
+	for _, r := range report.Rejected {
+		fmt.Printf("line %d: %s\n", r.Line, r.Reason)
+	}
+	verb := "Imported"
+	if report.DryRun {
+		verb = "Would import"
+	}
+	fmt.Printf("%s %d of %d rows; %d rejected\n", verb, report.Matches, report.Rows, len(report.Rejected))
+	for _, id := range report.NewSeasons {
+		fmt.Printf("New season: %s\n", id)
+	}
+	return nil
 }
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"rugby-live-api/models"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var liveMatchesDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "live_matches"),
+	"Matches currently in play, by status.",
+	[]string{"status"}, nil,
+)
+
+// RegisterLiveMatches reports the matches in play, counted by count whenever
+// metrics are scraped. count returns the number of matches in each of the
+// statuses it is given. A scrape gives up on the count after
+// liveMatchesTimeout, so a slow database does not stall it.
+func RegisterLiveMatches(count func(context.Context, []models.MatchStatus) (map[models.MatchStatus]int, error), logger *slog.Logger) {
+	var inPlay []models.MatchStatus
+	for _, status := range models.MatchStatuses {
+		if status.InPlay() {
+			inPlay = append(inPlay, status)
+		}
+	}
+	prometheus.MustRegister(&liveMatches{count: count, statuses: inPlay, log: logger})
+}
+
+const liveMatchesTimeout = 5 * time.Second
+
+type liveMatches struct {
+	count    func(context.Context, []models.MatchStatus) (map[models.MatchStatus]int, error)
+	statuses []models.MatchStatus
+	log      *slog.Logger
+}
+
+func (l *liveMatches) Describe(ch chan<- *prometheus.Desc) {
+	ch <- liveMatchesDesc
+}
+
+func (l *liveMatches) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), liveMatchesTimeout)
+	defer cancel()
+	counts, err := l.count(ctx, l.statuses)
+	if err != nil {
+		l.log.Error("Error counting live matches", "error", err)
+		return
+	}
+	for _, status := range l.statuses {
+		ch <- prometheus.MustNewConstMetric(liveMatchesDesc, prometheus.GaugeValue, float64(counts[status]), string(status))
+	}
+}
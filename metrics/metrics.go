@@ -0,0 +1,181 @@
+// Package metrics defines the Prometheus metrics served at /metrics: HTTP
+// route latencies, outbound requests to each data provider and the state of
+// their circuit breakers, rows written by ingestion, mapping match rates and
+// the number of matches in play.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "rugby"
+
+var (
+	httpRequests = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to serve HTTP requests, by route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	providerRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "provider_requests_total",
+		Help:      "Outbound requests to data providers, by outcome.",
+	}, []string{"provider", "outcome"})
+
+	providerDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "provider_request_duration_seconds",
+		Help:      "Time taken by outbound requests to data providers, until response headers arrive.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"provider"})
+
+	rowsUpserted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rows_upserted_total",
+		Help:      "Rows inserted or updated by ingestion, by entity type.",
+	}, []string{"entity"})
+
+	mappingResults = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mapping_results_total",
+		Help:      "Provider entities a mapping run tried to match to stored ones, by result.",
+	}, []string{"mapper", "result"})
+
+	mappingMatchRate = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "mapping_match_rate",
+		Help:      "Share of provider entities matched by the latest mapping run.",
+	}, []string{"mapper"})
+
+	circuitState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "provider_circuit_state",
+		Help:      "State of each provider host's circuit breaker: 0 closed, 1 half-open, 2 open.",
+	}, []string{"provider", "host"})
+)
+
+// SetCircuitState records the state of host's circuit breaker.
+func SetCircuitState(host string, state int) {
+	circuitState.WithLabelValues(Provider(host), host).Set(float64(state))
+}
+
+// Provider outcomes other than an HTTP status class.
+const (
+	OutcomeTimeout = "timeout"
+	OutcomeNetwork = "network_error"
+)
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveHTTPRequest records a request served by route, the pattern it
+// matched, such as /api/leagues/:id.
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// RowsUpserted records n rows of entity written.
+func RowsUpserted(entity string, n int) {
+	rowsUpserted.WithLabelValues(entity).Add(float64(n))
+}
+
+// ObserveMapping records a mapping run that matched matched of total
+// provider entities.
+func ObserveMapping(mapper string, matched, total int) {
+	mappingResults.WithLabelValues(mapper, "matched").Add(float64(matched))
+	mappingResults.WithLabelValues(mapper, "unmatched").Add(float64(total - matched))
+	if total > 0 {
+		mappingMatchRate.WithLabelValues(mapper).Set(float64(matched) / float64(total))
+	}
+}
+
+// providerHosts names the provider behind each host, matched by suffix.
+var providerHosts = []struct {
+	suffix   string
+	provider string
+}{
+	{"api-sports.io", "api_sports"},
+	{"rapidapi.com", "rapidapi"},
+	{"rugbydatabase.co.nz", "rugbydb"},
+	{"wikidata.org", "wikidata"},
+	{"wikimedia.org", "wikidata"},
+	{"supabase.co", "supabase"},
+	{"espn.com", "espn"},
+}
+
+// Provider returns the name requests to host are recorded under.
+func Provider(host string) string {
+	host = strings.ToLower(host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	for _, p := range providerHosts {
+		if host == p.suffix || strings.HasSuffix(host, "."+p.suffix) {
+			return p.provider
+		}
+	}
+	return "other"
+}
+
+// Transport returns a RoundTripper that records each request sent through
+// next against the provider its host belongs to, or against provider when
+// that is set. next defaults to http.DefaultTransport.
+func Transport(provider string, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{provider: provider, next: next}
+}
+
+type transport struct {
+	provider string
+	next     http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	provider := t.provider
+	if provider == "" {
+		provider = Provider(req.URL.Host)
+	}
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	providerDuration.WithLabelValues(provider).Observe(time.Since(start).Seconds())
+	providerRequests.WithLabelValues(provider, outcome(req, resp, err)).Inc()
+	return resp, err
+}
+
+// outcome classes a provider response: 2xx, 3xx, 4xx or 5xx, with 429 apart
+// as rate_limited, or timeout or network_error when no response came back.
+func outcome(req *http.Request, resp *http.Response, err error) string {
+	if err != nil {
+		var netErr net.Error
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(req.Context().Err(), context.DeadlineExceeded) ||
+			(errors.As(err, &netErr) && netErr.Timeout()) {
+			return OutcomeTimeout
+		}
+		return OutcomeNetwork
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return "rate_limited"
+	}
+	return strconv.Itoa(resp.StatusCode/100) + "xx"
+}
+
+// NewHTTPClient returns a client whose requests are recorded by Transport.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: Transport("", nil)}
+}
@@ -24,6 +24,17 @@ type League struct {
 	SuccessorID   *string   `json:"successor_id,omitempty" db:"successor_league_id"`
 	AllTime       bool      `json:"all_time" db:"all_time"`
 	AllTimeID     string    `json:"all_time_id,omitempty" db:"all_time_league_id"`
+	Tour          *Tour     `json:"tour,omitempty" db:"-"`
+}
+
+// Tour is who is touring and where, for leagues with the Tour format.
+// Countries are codes; HostCountries is empty when the hosts are regions
+// whose countries aren't named.
+type Tour struct {
+	Tourists         []string `json:"tourists"`
+	TouristCountries []string `json:"tourist_countries"`
+	Hosts            []string `json:"hosts"`
+	HostCountries    []string `json:"host_countries"`
 }
 
 type LeagueTeam struct {
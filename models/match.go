@@ -3,22 +3,69 @@ package models
 import "time"
 
 type Match struct {
-	ID          string    `json:"id"`
-	HomeTeam    *Team     `json:"home_team"`
-	AwayTeam    *Team     `json:"away_team"`
-	League      *League   `json:"league"`
-	HomeTeamID  string    `json:"home_team_id"`
-	AwayTeamID  string    `json:"away_team_id"`
-	LeagueID    string    `json:"league_id"`
-	HomeScore   int       `json:"home_score"`
-	AwayScore   int       `json:"away_score"`
-	Status      string    `json:"status"`
-	KickOff     time.Time `json:"kick_off"`
-	Date        string    `json:"date"`
-	Time        string    `json:"time"`
-	Week        string    `json:"week"`
-	Season      int       `json:"season"`
-	APISportsID int       `json:"api_sports_id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID            string      `json:"id"`
+	HomeTeam      *Team       `json:"home_team"`
+	AwayTeam      *Team       `json:"away_team"`
+	League        *League     `json:"league"`
+	HomeTeamID    string      `json:"home_team_id"`
+	AwayTeamID    string      `json:"away_team_id"`
+	LeagueID      string      `json:"league_id"`
+	HomeScore     int         `json:"home_score"`
+	AwayScore     int         `json:"away_score"`
+	Status        MatchStatus `json:"status"`
+	KickOff       time.Time   `json:"kick_off"`       // Always UTC
+	Date          string      `json:"date"`           // Kick-off date, UTC unless localized for a caller
+	Time          string      `json:"time"`           // Kick-off time, UTC unless localized for a caller
+	VenueTimezone string      `json:"venue_timezone"` // IANA zone of the venue, e.g. "Europe/London", or a fixed offset such as "+05:30"
+	Venue         string      `json:"venue,omitempty"`
+	ScoreSource   string      `json:"score_source,omitempty"`   // Provider whose score won, or "override"
+	ScoreConflict bool        `json:"score_conflict,omitempty"` // Providers disagree on the final score or status
+	Week          string      `json:"week"`
+	Season        int         `json:"season"`
+	APISportsID   int         `json:"api_sports_id"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+}
+
+// Localize returns a copy of the match with Date and Time rendered in loc.
+func (m Match) Localize(loc *time.Location) Match {
+	local := m.KickOff.In(loc)
+	m.Date = local.Format("2006-01-02")
+	m.Time = local.Format("15:04")
+	return m
+}
+
+// SourceReport is one provider's latest score and status for a match.
+type SourceReport struct {
+	MatchID    string      `json:"match_id"`
+	Source     string      `json:"source"`
+	HomeScore  int         `json:"home_score"`
+	AwayScore  int         `json:"away_score"`
+	Status     MatchStatus `json:"status"`
+	ReportedAt time.Time   `json:"reported_at"`
+}
+
+// ScoreOverride is a manually entered result that wins over every provider.
+type ScoreOverride struct {
+	MatchID   string      `json:"match_id"`
+	HomeScore int         `json:"home_score"`
+	AwayScore int         `json:"away_score"`
+	Status    MatchStatus `json:"status"`
+	Note      string      `json:"note"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// TeamResult is a finished match from one team's side.
+type TeamResult struct {
+	MatchID       string    `json:"match_id"`
+	KickOff       time.Time `json:"kick_off"`
+	SeasonID      string    `json:"season_id"`
+	LeagueID      string    `json:"league_id"`
+	LeagueName    string    `json:"league_name"`
+	Home          bool      `json:"home"`
+	OpponentID    string    `json:"opponent_id"`
+	OpponentName  string    `json:"opponent_name"`
+	PointsFor     int       `json:"points_for"`
+	PointsAgainst int       `json:"points_against"`
+	Result        string    `json:"result"` // W, D or L
 }
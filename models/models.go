@@ -144,9 +144,11 @@ type TeamStadium struct {
 }
 
 type LeagueTransition struct {
+	OldName     string
 	SuccessorID string
 	Year        int
 	DisplayName string
+	RenamedTo   string // Empty when renamed straight to the successor league
 }
 
 type RapidAPICompetition struct {
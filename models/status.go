@@ -0,0 +1,193 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MatchStatus is where a match is in its lifecycle. Every provider's status
+// is mapped onto these values before it is stored.
+type MatchStatus string
+
+const (
+	StatusScheduled  MatchStatus = "scheduled"
+	StatusFirstHalf  MatchStatus = "first_half"
+	StatusHalfTime   MatchStatus = "half_time"
+	StatusSecondHalf MatchStatus = "second_half"
+	StatusExtraTime  MatchStatus = "extra_time"
+	StatusFinished   MatchStatus = "finished"
+	StatusPostponed  MatchStatus = "postponed"
+	StatusAbandoned  MatchStatus = "abandoned"
+	StatusCancelled  MatchStatus = "cancelled"
+)
+
+// MatchStatuses lists every status in lifecycle order.
+var MatchStatuses = []MatchStatus{
+	StatusScheduled, StatusFirstHalf, StatusHalfTime, StatusSecondHalf, StatusExtraTime,
+	StatusFinished, StatusPostponed, StatusAbandoned, StatusCancelled,
+}
+
+// EnumValues lists the allowed values for the OpenAPI document.
+func (s MatchStatus) EnumValues() []string {
+	values := make([]string, len(MatchStatuses))
+	for i, status := range MatchStatuses {
+		values[i] = string(status)
+	}
+	return values
+}
+
+func (s MatchStatus) Valid() bool {
+	_, ok := matchTransitions[s]
+	return ok
+}
+
+// InPlay reports whether the match has kicked off and not yet ended.
+func (s MatchStatus) InPlay() bool {
+	switch s {
+	case StatusFirstHalf, StatusHalfTime, StatusSecondHalf, StatusExtraTime:
+		return true
+	}
+	return false
+}
+
+// Settled reports whether the match's result will not change without a new
+// fixture: it has ended, or it will not be played as scheduled.
+func (s MatchStatus) Settled() bool {
+	return s.Valid() && s != StatusScheduled && !s.InPlay()
+}
+
+// matchTransitions lists the statuses each status may move to. Providers are
+// polled, so a match can skip states in between, e.g. scheduled straight to
+// finished when a result is scraped after the game. Finished and cancelled
+// are terminal; an abandoned match can only be rescheduled.
+var matchTransitions = map[MatchStatus][]MatchStatus{
+	StatusScheduled:  {StatusFirstHalf, StatusHalfTime, StatusSecondHalf, StatusExtraTime, StatusFinished, StatusPostponed, StatusAbandoned, StatusCancelled},
+	StatusFirstHalf:  {StatusHalfTime, StatusSecondHalf, StatusExtraTime, StatusFinished, StatusAbandoned},
+	StatusHalfTime:   {StatusSecondHalf, StatusExtraTime, StatusFinished, StatusAbandoned},
+	StatusSecondHalf: {StatusExtraTime, StatusFinished, StatusAbandoned},
+	StatusExtraTime:  {StatusFinished, StatusAbandoned},
+	StatusPostponed:  {StatusScheduled, StatusFirstHalf, StatusHalfTime, StatusSecondHalf, StatusExtraTime, StatusFinished, StatusCancelled},
+	StatusAbandoned:  {StatusScheduled},
+	StatusFinished:   {},
+	StatusCancelled:  {},
+}
+
+// CanTransition reports whether a match may move from one status to another.
+// Staying put is always allowed, as is any first status for a new match.
+func CanTransition(from, to MatchStatus) bool {
+	if !to.Valid() {
+		return false
+	}
+	if from == "" || from == to {
+		return true
+	}
+	for _, next := range matchTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseMatchStatus reads a status given by a caller, accepting the names used
+// before statuses were normalised.
+func ParseMatchStatus(value string) (MatchStatus, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if status := MatchStatus(value); status.Valid() {
+		return status, nil
+	}
+	if status, ok := ProviderStatuses["legacy"][value]; ok {
+		return status, nil
+	}
+	return "", fmt.Errorf("unknown match status %q", value)
+}
+
+// ProviderStatuses maps each provider's status values, lower-cased, onto
+// MatchStatus. "legacy" covers values stored before statuses were normalised.
+var ProviderStatuses = map[string]map[string]MatchStatus{
+	// API-Sports reports both a short code and a long description.
+	"api_sports": {
+		"ns":                 StatusScheduled,
+		"not started":        StatusScheduled,
+		"tbd":                StatusScheduled,
+		"time to be defined": StatusScheduled,
+		"1h":                 StatusFirstHalf,
+		"first half":         StatusFirstHalf,
+		"in play":            StatusFirstHalf,
+		"ht":                 StatusHalfTime,
+		"halftime":           StatusHalfTime,
+		"half time":          StatusHalfTime,
+		"2h":                 StatusSecondHalf,
+		"second half":        StatusSecondHalf,
+		"et":                 StatusExtraTime,
+		"bt":                 StatusExtraTime,
+		"pt":                 StatusExtraTime,
+		"extra time":         StatusExtraTime,
+		"break time":         StatusExtraTime,
+		"penalties time":     StatusExtraTime,
+		"ft":                 StatusFinished,
+		"aet":                StatusFinished,
+		"aw":                 StatusFinished,
+		"finished":           StatusFinished,
+		"match finished":     StatusFinished,
+		"after extra time":   StatusFinished,
+		"awarded":            StatusFinished,
+		"post":               StatusPostponed,
+		"postponed":          StatusPostponed,
+		"intr":               StatusAbandoned,
+		"interrupted":        StatusAbandoned,
+		"abd":                StatusAbandoned,
+		"abandoned":          StatusAbandoned,
+		"canc":               StatusCancelled,
+		"cancelled":          StatusCancelled,
+		"canceled":           StatusCancelled,
+	},
+	"rapid_api": {
+		"":            StatusScheduled,
+		"fixture":     StatusScheduled,
+		"scheduled":   StatusScheduled,
+		"not started": StatusScheduled,
+		"1st half":    StatusFirstHalf,
+		"first half":  StatusFirstHalf,
+		"live":        StatusFirstHalf,
+		"half time":   StatusHalfTime,
+		"ht":          StatusHalfTime,
+		"2nd half":    StatusSecondHalf,
+		"second half": StatusSecondHalf,
+		"extra time":  StatusExtraTime,
+		"result":      StatusFinished,
+		"finished":    StatusFinished,
+		"ft":          StatusFinished,
+		"postponed":   StatusPostponed,
+		"abandoned":   StatusAbandoned,
+		"cancelled":   StatusCancelled,
+	},
+	// RugbyDB lists only fixtures and results.
+	"rugbydatabase": {
+		"fixture": StatusScheduled,
+		"result":  StatusFinished,
+	},
+	"legacy": {
+		"upcoming": StatusScheduled,
+		"live":     StatusFirstHalf,
+	},
+}
+
+// ProviderStatus maps a provider's status onto MatchStatus. ok is false when
+// the value is not in the provider's table.
+func ProviderStatus(provider, value string) (status MatchStatus, ok bool) {
+	status, ok = ProviderStatuses[provider][strings.ToLower(strings.TrimSpace(value))]
+	return status, ok
+}
+
+// StatusChange is one recorded move of a match from one status to another.
+// From is empty for the status a match was first stored with.
+type StatusChange struct {
+	ID        int64       `json:"id"`
+	MatchID   string      `json:"match_id"`
+	From      MatchStatus `json:"from,omitempty"`
+	To        MatchStatus `json:"to"`
+	Source    string      `json:"source"`
+	ChangedAt time.Time   `json:"changed_at"`
+}
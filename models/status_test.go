@@ -0,0 +1,118 @@
+package models
+
+import "testing"
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to MatchStatus
+		want     bool
+	}{
+		{"", StatusScheduled, true},
+		{"", StatusFinished, true},
+		{"", "kicked_off", false},
+		{StatusScheduled, StatusScheduled, true},
+		{StatusScheduled, StatusFirstHalf, true},
+		{StatusScheduled, StatusFinished, true},
+		{StatusFirstHalf, StatusSecondHalf, true},
+		{StatusHalfTime, StatusFirstHalf, false},
+		{StatusSecondHalf, StatusHalfTime, false},
+		{StatusExtraTime, StatusFinished, true},
+		{StatusExtraTime, StatusPostponed, false},
+		{StatusPostponed, StatusScheduled, true},
+		{StatusPostponed, StatusAbandoned, false},
+		{StatusAbandoned, StatusScheduled, true},
+		{StatusAbandoned, StatusFinished, false},
+		{StatusFinished, StatusFinished, true},
+		{StatusFinished, StatusScheduled, false},
+		{StatusCancelled, StatusScheduled, false},
+		{StatusScheduled, "", false},
+	}
+	for _, tt := range tests {
+		if got := CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+// Every status must have an entry in matchTransitions, or it isn't Valid.
+func TestMatchStatusesValid(t *testing.T) {
+	for _, status := range MatchStatuses {
+		if !status.Valid() {
+			t.Errorf("%q is listed in MatchStatuses but has no transitions", status)
+		}
+	}
+	if len(matchTransitions) != len(MatchStatuses) {
+		t.Errorf("matchTransitions has %d statuses, MatchStatuses %d", len(matchTransitions), len(MatchStatuses))
+	}
+}
+
+func TestStatusPredicates(t *testing.T) {
+	tests := []struct {
+		status          MatchStatus
+		inPlay, settled bool
+	}{
+		{StatusScheduled, false, false},
+		{StatusFirstHalf, true, false},
+		{StatusHalfTime, true, false},
+		{StatusSecondHalf, true, false},
+		{StatusExtraTime, true, false},
+		{StatusFinished, false, true},
+		{StatusPostponed, false, true},
+		{StatusAbandoned, false, true},
+		{StatusCancelled, false, true},
+		{"unknown", false, false},
+	}
+	for _, tt := range tests {
+		if got := tt.status.InPlay(); got != tt.inPlay {
+			t.Errorf("%q.InPlay() = %v, want %v", tt.status, got, tt.inPlay)
+		}
+		if got := tt.status.Settled(); got != tt.settled {
+			t.Errorf("%q.Settled() = %v, want %v", tt.status, got, tt.settled)
+		}
+	}
+}
+
+func TestParseMatchStatus(t *testing.T) {
+	tests := []struct {
+		value string
+		want  MatchStatus
+		ok    bool
+	}{
+		{"finished", StatusFinished, true},
+		{" Half_Time ", StatusHalfTime, true},
+		{"upcoming", StatusScheduled, true},
+		{"LIVE", StatusFirstHalf, true},
+		{"ft", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, err := ParseMatchStatus(tt.value)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseMatchStatus(%q) = %q, %v, want %q (ok %v)", tt.value, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestProviderStatus(t *testing.T) {
+	tests := []struct {
+		provider, value string
+		want            MatchStatus
+		ok              bool
+	}{
+		{"api_sports", "NS", StatusScheduled, true},
+		{"api_sports", "AET", StatusFinished, true},
+		{"api_sports", "Match Finished", StatusFinished, true},
+		{"api_sports", "INTR", StatusAbandoned, true},
+		{"rapid_api", "", StatusScheduled, true},
+		{"rapid_api", "2nd Half", StatusSecondHalf, true},
+		{"rugbydatabase", "Result", StatusFinished, true},
+		{"rugbydatabase", "live", "", false},
+		{"espn", "ft", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ProviderStatus(tt.provider, tt.value)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ProviderStatus(%q, %q) = %q, %v, want %q, %v", tt.provider, tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,385 @@
+package openapi
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// GenerateClient renders a Go client package for doc: one struct per
+// component schema and one method per operation.
+func GenerateClient(doc *Document, pkg string) ([]byte, error) {
+	g := &clientGen{}
+
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var types strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&types, "type %s %s\n\n", name, g.goType(doc.Components.Schemas[name]))
+	}
+
+	var methods strings.Builder
+	for _, op := range sortedOperations(doc) {
+		if _, upgrade := op.Responses["101"]; upgrade {
+			continue // WebSockets need a WebSocket client
+		}
+		g.writeOperation(&methods, op)
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "// Code generated by openapigen from the OpenAPI document. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "// Package %s is a typed client for the Rugby Live API.\npackage %s\n\n", pkg, pkg)
+	out.WriteString("import (\n\t\"bytes\"\n\t\"context\"\n\t\"encoding/json\"\n\t\"fmt\"\n\t\"io\"\n\t\"net/http\"\n\t\"net/url\"\n")
+	if g.usesStrconv {
+		out.WriteString("\t\"strconv\"\n")
+	}
+	out.WriteString("\t\"strings\"\n")
+	if g.usesTime {
+		out.WriteString("\t\"time\"\n")
+	}
+	out.WriteString(")\n\n")
+	out.WriteString(clientRuntime)
+	out.WriteString(types.String())
+	out.WriteString(methods.String())
+
+	src, err := format.Source([]byte(out.String()))
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated client: %v", err)
+	}
+	return src, nil
+}
+
+const clientRuntime = `// Client calls the API at BaseURL. AdminToken is sent as a bearer token,
+// which admin operations require.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	AdminToken string
+}
+
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), HTTPClient: http.DefaultClient}
+}
+
+// APIError is returned for non-2xx responses.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	respBody, err := c.stream(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer respBody.Close()
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(respBody).Decode(out)
+}
+
+// stream sends a request and returns the response body of a 2xx response.
+func (c *Client) stream(ctx context.Context, method, path string, query url.Values, body interface{}) (io.ReadCloser, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.AdminToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.AdminToken)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		var e ErrorResponse
+		data, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(data, &e) != nil || e.Error == "" {
+			e.Error = strings.TrimSpace(string(data))
+		}
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: e.Error}
+	}
+	return resp.Body, nil
+}
+
+`
+
+type namedOperation struct {
+	Method string
+	Path   string
+	*Operation
+}
+
+func sortedOperations(doc *Document) []namedOperation {
+	var ops []namedOperation
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			ops = append(ops, namedOperation{Method: strings.ToUpper(method), Path: path, Operation: op})
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].OperationID < ops[j].OperationID })
+	return ops
+}
+
+type clientGen struct {
+	usesTime    bool
+	usesStrconv bool
+}
+
+func (g *clientGen) writeOperation(b *strings.Builder, op namedOperation) {
+	name := exported(op.OperationID)
+
+	var pathArgs, queryParams []Parameter
+	for _, p := range op.Parameters {
+		if p.In == "path" {
+			pathArgs = append(pathArgs, p)
+		} else {
+			queryParams = append(queryParams, p)
+		}
+	}
+
+	if len(queryParams) > 0 {
+		fmt.Fprintf(b, "// %sParams holds the query parameters for %s.\ntype %sParams struct {\n", name, name, name)
+		for _, p := range queryParams {
+			if p.Description != "" {
+				fmt.Fprintf(b, "\t// %s\n", p.Description)
+			}
+			fmt.Fprintf(b, "\t%s %s\n", goName(p.Name), g.paramType(p))
+		}
+		b.WriteString("}\n\n")
+	}
+
+	args := []string{"ctx context.Context"}
+	for _, p := range pathArgs {
+		args = append(args, lowerName(p.Name)+" string")
+	}
+	if len(queryParams) > 0 {
+		args = append(args, "params "+name+"Params")
+	}
+	if op.RequestBody != nil {
+		args = append(args, "body "+g.goType(op.RequestBody.Content[jsonContent].Schema))
+	}
+
+	result, hasResult, streamed := "", false, false
+	if ok, found := op.Responses["200"]; found && ok.Content != nil {
+		if media, isJSON := ok.Content[jsonContent]; isJSON {
+			result, hasResult = g.goType(media.Schema), true
+		} else {
+			streamed = true
+		}
+	}
+	pointerResult := hasResult && isNamed(result)
+
+	fmt.Fprintf(b, "// %s calls %s %s.\n", name, op.Method, op.Path)
+	if op.Summary != "" {
+		fmt.Fprintf(b, "// %s.\n", op.Summary)
+	}
+	switch {
+	case streamed:
+		fmt.Fprintf(b, "// The caller must close the returned body.\n")
+		fmt.Fprintf(b, "func (c *Client) %s(%s) (io.ReadCloser, error) {\n", name, strings.Join(args, ", "))
+	case pointerResult:
+		fmt.Fprintf(b, "func (c *Client) %s(%s) (*%s, error) {\n", name, strings.Join(args, ", "), result)
+	case hasResult:
+		fmt.Fprintf(b, "func (c *Client) %s(%s) (%s, error) {\n", name, strings.Join(args, ", "), result)
+	default:
+		fmt.Fprintf(b, "func (c *Client) %s(%s) error {\n", name, strings.Join(args, ", "))
+	}
+
+	path := fmt.Sprintf("%q", op.Path)
+	for _, p := range pathArgs {
+		path = strings.Replace(path, "{"+p.Name+"}", `" + url.PathEscape(`+lowerName(p.Name)+`) + "`, 1)
+	}
+	path = strings.TrimSuffix(strings.TrimPrefix(path, `"" + `), ` + ""`)
+
+	query := "nil"
+	if len(queryParams) > 0 {
+		query = "query"
+		b.WriteString("\tquery := url.Values{}\n")
+		for _, p := range queryParams {
+			g.writeQueryParam(b, p)
+		}
+	}
+
+	body := "nil"
+	if op.RequestBody != nil {
+		body = "body"
+	}
+
+	switch {
+	case streamed:
+		fmt.Fprintf(b, "\treturn c.stream(ctx, %q, %s, %s, %s)\n}\n\n", op.Method, path, query, body)
+	case hasResult:
+		fmt.Fprintf(b, "\tvar out %s\n", result)
+		fmt.Fprintf(b, "\tif err := c.do(ctx, %q, %s, %s, %s, &out); err != nil {\n", op.Method, path, query, body)
+		if pointerResult {
+			b.WriteString("\t\treturn nil, err\n\t}\n\treturn &out, nil\n}\n\n")
+		} else {
+			b.WriteString("\t\treturn out, err\n\t}\n\treturn out, nil\n}\n\n")
+		}
+	default:
+		fmt.Fprintf(b, "\treturn c.do(ctx, %q, %s, %s, %s, nil)\n}\n\n", op.Method, path, query, body)
+	}
+}
+
+func (g *clientGen) paramType(p Parameter) string {
+	switch p.Schema.Type {
+	case "array":
+		return "[]string"
+	case "boolean":
+		return "*bool"
+	case "integer":
+		return "*int"
+	}
+	return "string"
+}
+
+func (g *clientGen) writeQueryParam(b *strings.Builder, p Parameter) {
+	field := "params." + goName(p.Name)
+	switch p.Schema.Type {
+	case "array":
+		fmt.Fprintf(b, "\tfor _, v := range %s {\n\t\tquery.Add(%q, v)\n\t}\n", field, p.Name)
+	case "boolean":
+		g.usesStrconv = true
+		fmt.Fprintf(b, "\tif %s != nil {\n\t\tquery.Set(%q, strconv.FormatBool(*%s))\n\t}\n", field, p.Name, field)
+	case "integer":
+		g.usesStrconv = true
+		fmt.Fprintf(b, "\tif %s != nil {\n\t\tquery.Set(%q, strconv.Itoa(*%s))\n\t}\n", field, p.Name, field)
+	default:
+		fmt.Fprintf(b, "\tif %s != \"\" {\n\t\tquery.Set(%q, %s)\n\t}\n", field, p.Name, field)
+	}
+}
+
+// goType renders the Go type for a schema.
+func (g *clientGen) goType(s *Schema) string {
+	if s.Ref != "" {
+		return strings.TrimPrefix(s.Ref, "#/components/schemas/")
+	}
+	if len(s.AllOf) == 1 {
+		return "*" + g.goType(s.AllOf[0])
+	}
+
+	var t string
+	switch s.Type {
+	case "string":
+		t = "string"
+		if s.Format == "date-time" {
+			g.usesTime = true
+			t = "time.Time"
+		}
+	case "integer":
+		t = "int"
+		if s.Format == "int64" {
+			t = "int64"
+		}
+	case "number":
+		t = "float64"
+	case "boolean":
+		t = "bool"
+	case "array":
+		return "[]" + g.goType(s.Items)
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "map[string]" + g.goType(s.AdditionalProperties)
+		}
+		return g.structType(s)
+	default:
+		return "json.RawMessage"
+	}
+	if s.Nullable {
+		return "*" + t
+	}
+	return t
+}
+
+func (g *clientGen) structType(s *Schema) string {
+	required := make(map[string]bool, len(s.Required))
+	for _, name := range s.Required {
+		required[name] = true
+	}
+	props := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		props = append(props, name)
+	}
+	sort.Strings(props)
+
+	var b strings.Builder
+	b.WriteString("struct {\n")
+	for _, name := range props {
+		tag := name
+		if !required[name] {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&b, "\t%s %s `json:\"%s\"`\n", goName(name), g.goType(s.Properties[name]), tag)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+func isNamed(goType string) bool {
+	return !strings.ContainsAny(goType, "[]*{ ") && goType != "string" && goType != "int" &&
+		goType != "int64" && goType != "float64" && goType != "bool"
+}
+
+var initialisms = map[string]string{
+	"api": "API", "db": "DB", "espn": "ESPN", "fifa": "FIFA", "id": "ID",
+	"ids": "IDs", "json": "JSON", "tz": "TZ", "url": "URL",
+}
+
+// goName turns a JSON or parameter name such as home_team_id into HomeTeamID.
+func goName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' || r == '.' }) {
+		if upper, ok := initialisms[strings.ToLower(part)]; ok {
+			b.WriteString(upper)
+			continue
+		}
+		b.WriteString(exported(part))
+	}
+	return b.String()
+}
+
+func lowerName(name string) string {
+	n := goName(name)
+	if n == "" {
+		return n
+	}
+	// A leading initialism is lowered whole, so id becomes id rather than iD.
+	for _, upper := range initialisms {
+		if strings.HasPrefix(n, upper) && (len(n) == len(upper) || unicode.IsUpper(rune(n[len(upper)]))) {
+			return strings.ToLower(upper) + n[len(upper):]
+		}
+	}
+	return strings.ToLower(n[:1]) + n[1:]
+}
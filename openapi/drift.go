@@ -0,0 +1,52 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CheckRoutes compares the routes registered on a Gin engine with the
+// document and returns an error listing any that appear on only one side.
+func CheckRoutes(registered gin.RoutesInfo) error {
+	documented := make(map[string]bool)
+	for path, item := range Spec().Paths {
+		for method := range item {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	var undocumented []string
+	for _, route := range registered {
+		key := route.Method + " " + openAPIPath(route.Path)
+		if documented[key] {
+			delete(documented, key)
+			continue
+		}
+		undocumented = append(undocumented, key)
+	}
+
+	var unregistered []string
+	for key := range documented {
+		unregistered = append(unregistered, key)
+	}
+
+	if len(undocumented) == 0 && len(unregistered) == 0 {
+		return nil
+	}
+
+	sort.Strings(undocumented)
+	sort.Strings(unregistered)
+	var msg strings.Builder
+	msg.WriteString("OpenAPI document and routes have drifted")
+	if len(undocumented) > 0 {
+		fmt.Fprintf(&msg, "; routes missing from the document: %s", strings.Join(undocumented, ", "))
+	}
+	if len(unregistered) > 0 {
+		fmt.Fprintf(&msg, "; documented routes not registered: %s", strings.Join(unregistered, ", "))
+	}
+	return errors.New(msg.String())
+}
@@ -0,0 +1,70 @@
+package openapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"rugby-live-api/graph"
+	"rugby-live-api/handlers"
+	"rugby-live-api/openapi"
+	"rugby-live-api/routes"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func router() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	routes.Register(router, &handlers.Handler{}, graph.NewHandler(nil))
+	return router
+}
+
+func TestRoutesMatchDocument(t *testing.T) {
+	if err := openapi.CheckRoutes(router().Routes()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckRoutesReportsDrift(t *testing.T) {
+	r := router()
+	r.GET("/undocumented/:id", func(*gin.Context) {})
+	registered := r.Routes()
+	for i, route := range registered {
+		if route.Method == "GET" && route.Path == "/countries" {
+			registered = append(registered[:i], registered[i+1:]...)
+			break
+		}
+	}
+
+	err := openapi.CheckRoutes(registered)
+	if err == nil {
+		t.Fatal("CheckRoutes = nil, want drift reported")
+	}
+	for _, want := range []string{"GET /undocumented/{id}", "GET /countries"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("CheckRoutes error %q does not mention %s", err, want)
+		}
+	}
+}
+
+// Admin routes refuse every request while no admin token is configured, as
+// the zero Handler has none. Everything under /admin and /api/admin is one.
+func TestAdminRoutesRequireToken(t *testing.T) {
+	r := router()
+	for _, route := range openapi.Routes {
+		underAdmin := strings.HasPrefix(route.Path, "/admin/") || strings.HasPrefix(route.Path, "/api/admin/")
+		if underAdmin && !route.Admin {
+			t.Errorf("%s %s is under an admin path but not marked Admin", route.Method, route.Path)
+		}
+		if !route.Admin {
+			continue
+		}
+		path := strings.ReplaceAll(strings.ReplaceAll(route.Path, ":id", "1"), ":table", "matches")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(route.Method, path, nil))
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s %s answered %d without an admin token configured, want 403", route.Method, route.Path, rec.Code)
+		}
+	}
+}
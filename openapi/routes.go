@@ -0,0 +1,376 @@
+package openapi
+
+import (
+	"reflect"
+	"rugby-live-api/db"
+	"rugby-live-api/graph"
+	"rugby-live-api/handlers"
+	"rugby-live-api/models"
+	"rugby-live-api/services"
+	"rugby-live-api/services/hierarchy"
+	"rugby-live-api/services/live"
+	"rugby-live-api/services/rapidapi"
+	"rugby-live-api/services/stats"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// Route describes one registered Gin route. Path uses Gin syntax (:param).
+// Body and Response are zero values of the request and response types;
+// Produces replaces Response for routes that stream non-JSON files. Upgrade
+// marks WebSocket routes, whose Response is the type of message the server
+// sends; the generated client leaves them out. Admin marks routes that need
+// the admin bearer token.
+type Route struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Tag         string
+	Query       []QueryParam
+	Body        interface{}
+	Response    interface{}
+	Produces    []string
+	Upgrade     bool
+	Admin       bool
+}
+
+// QueryParam describes a query string parameter. Type is an OpenAPI
+// primitive; Array marks parameters that may repeat.
+type QueryParam struct {
+	Name        string
+	Type        string
+	Description string
+	Required    bool
+	Array       bool
+}
+
+func (p QueryParam) parameter() Parameter {
+	schema := &Schema{Type: p.Type}
+	if schema.Type == "" {
+		schema.Type = "string"
+	}
+	if p.Array {
+		schema = &Schema{Type: "array", Items: schema}
+	}
+	return Parameter{
+		Name:        p.Name,
+		In:          "query",
+		Description: p.Description,
+		Required:    p.Required,
+		Schema:      schema,
+	}
+}
+
+var errorType = reflect.TypeOf(handlers.ErrorResponse{})
+
+var tzParam = QueryParam{Name: "tz", Description: "IANA timezone for dates and times in the response; defaults to UTC"}
+
+var searchParam = QueryParam{Name: "q", Description: "Case-insensitive substring of the name or any alternate name"}
+
+var exportParams = []QueryParam{
+	{Name: "format", Description: "csv (default), jsonl or parquet"},
+	{Name: "season_id", Description: "Only rows belonging to this season"},
+	{Name: "league_id", Description: "Only rows belonging to this league"},
+}
+
+// listParams returns the paging parameters shared by list endpoints followed
+// by the endpoint's own filters. sortKeys lists the accepted sort values.
+func listParams(sortKeys string, filters ...QueryParam) []QueryParam {
+	return append([]QueryParam{
+		{Name: "limit", Type: "integer", Description: "Page size, at most 200; defaults to 50"},
+		{Name: "cursor", Description: "next_cursor from the previous page"},
+		{Name: "sort", Description: "One of " + sortKeys + "; prefix with - for descending"},
+	}, filters...)
+}
+
+// Routes lists every public route. Keep it in step with registerRoutes in
+// main.go; check-openapi reports any difference.
+var Routes = []Route{
+	{
+		Method: "GET", Path: "/matches", OperationID: "fetchTodaysMatches", Tag: "matches",
+		Summary:  "Fetch today's matches from API Sports and store them, or the stored ones while API Sports is unavailable",
+		Query:    []QueryParam{tzParam},
+		Response: handlers.MatchesResponse{},
+	},
+	{
+		Method: "GET", Path: "/api/matches", OperationID: "listMatches", Tag: "matches",
+		Summary: "List stored matches, today's in tz unless date, from or to is given",
+		Query: listParams("kick_off",
+			QueryParam{Name: "league_id", Description: "Season or league ID stored on the match"},
+			QueryParam{Name: "team_id", Description: "Home or away team"},
+			QueryParam{Name: "status", Description: "scheduled, first_half, half_time, second_half, extra_time, finished, postponed, abandoned or cancelled"},
+			QueryParam{Name: "date", Description: "Calendar day as YYYY-MM-DD in tz"},
+			QueryParam{Name: "from", Description: "Earliest kick-off, RFC 3339"},
+			QueryParam{Name: "to", Description: "Kick-off before, RFC 3339"},
+			tzParam,
+		),
+		Response: db.Page[models.Match]{},
+	},
+	{
+		Method: "GET", Path: "/api/matches/:id/status-history", OperationID: "getMatchStatusHistory", Tag: "matches",
+		Summary:  "A match's status and its recorded status changes",
+		Response: handlers.StatusHistoryResponse{},
+	},
+	{
+		Method: "GET", Path: "/api/teams", OperationID: "listTeams", Tag: "teams",
+		Summary: "List teams",
+		Query: listParams("name, created_at",
+			QueryParam{Name: "country", Description: "Country code"},
+			searchParam,
+		),
+		Response: db.Page[models.Team]{},
+	},
+	{
+		Method: "GET", Path: "/api/teams/:id/form", OperationID: "getTeamForm", Tag: "teams",
+		Summary:  "A team's most recent results as a W/D/L form guide",
+		Query:    []QueryParam{{Name: "n", Type: "integer", Description: "Number of results, 1 to 50; defaults to 5"}},
+		Response: stats.TeamForm{},
+	},
+	{
+		Method: "GET", Path: "/api/teams/:id/stats", OperationID: "getTeamStats", Tag: "teams",
+		Summary:  "A team's record, points and winning margin, overall and split by home and away",
+		Query:    []QueryParam{{Name: "season_id", Description: "Season to summarise; defaults to every stored season outside all-time leagues"}},
+		Response: stats.TeamStats{},
+	},
+	{
+		Method: "GET", Path: "/api/leagues", OperationID: "listLeagues", Tag: "leagues",
+		Summary: "List leagues",
+		Query: listParams("name, tier, created_at",
+			QueryParam{Name: "country", Description: "Country code"},
+			QueryParam{Name: "gender"},
+			QueryParam{Name: "tier", Type: "integer"},
+			QueryParam{Name: "international", Type: "boolean"},
+			QueryParam{Name: "all_time", Type: "boolean"},
+			QueryParam{Name: "parent_id"},
+			QueryParam{Name: "tourist", Description: "Country code of a touring side, selecting tours"},
+			QueryParam{Name: "host", Description: "Country code of a tour's hosts"},
+			searchParam,
+		),
+		Response: db.Page[models.League]{},
+	},
+	{
+		Method: "GET", Path: "/api/leagues/:id/tree", OperationID: "getLeagueTree", Tag: "leagues",
+		Summary:  "A league with its parent competitions and the competitions played within it",
+		Response: hierarchy.LeagueTree{},
+	},
+	{
+		Method: "GET", Path: "/api/leagues/:id/lineage", OperationID: "getLeagueLineage", Tag: "leagues",
+		Summary:  "The names a league has been played under, with the years each was used",
+		Response: hierarchy.LeagueLineage{},
+	},
+	{
+		Method: "GET", Path: "/api/seasons", OperationID: "listSeasons", Tag: "leagues",
+		Summary: "List seasons",
+		Query: listParams("year, created_at",
+			QueryParam{Name: "league_id"},
+			QueryParam{Name: "year", Type: "integer"},
+			QueryParam{Name: "current", Type: "boolean"},
+		),
+		Response: db.Page[models.Season]{},
+	},
+	{
+		Method: "GET", Path: "/matches/api-sports/league", OperationID: "getMatchesByLeague", Tag: "matches",
+		Summary: "Fetch and store a league's matches from API Sports, or the stored ones while API Sports is unavailable",
+		Query: []QueryParam{
+			{Name: "league_id", Required: true},
+			{Name: "date"},
+			{Name: "season"},
+			{Name: "api_league_id", Description: "API Sports league ID override"},
+			{Name: "api_season", Description: "API Sports season override"},
+			{Name: "api_date", Description: "API Sports date override"},
+			tzParam,
+		},
+		Response: handlers.LeagueMatchesResponse{},
+	},
+	{
+		Method: "GET", Path: "/countries", OperationID: "getCountries", Tag: "countries",
+		Summary:  "List countries",
+		Response: []models.Country{},
+	},
+	{
+		Method: "POST", Path: "/countries/refresh", OperationID: "refreshCountries", Tag: "countries",
+		Summary:  "Refresh countries from API Sports",
+		Query:    []QueryParam{{Name: "update_flags", Type: "boolean"}},
+		Response: handlers.CountriesRefreshResponse{},
+	},
+	{
+		Method: "POST", Path: "/leagues/refresh", OperationID: "refreshLeagues", Tag: "leagues",
+		Summary:  "Refresh leagues from API Sports",
+		Query:    []QueryParam{{Name: "update_images", Type: "boolean"}},
+		Response: handlers.LeaguesRefreshResponse{},
+	},
+	{
+		Method: "GET", Path: "/leagues/map-api-sports", OperationID: "mapAPISportsLeagues", Tag: "leagues",
+		Summary:  "Match API Sports leagues to stored leagues, or list the stored mappings while API Sports is unavailable",
+		Response: handlers.LeagueMappingResponse{},
+	},
+	{
+		Method: "POST", Path: "/teams/refresh", OperationID: "refreshTeams", Tag: "teams",
+		Summary: "Refresh teams from API Sports",
+		Query: []QueryParam{
+			{Name: "update_images", Type: "boolean"},
+			{Name: "country", Description: "API Sports country ID"},
+			{Name: "league", Description: "API Sports league ID; requires season"},
+			{Name: "season", Type: "integer"},
+		},
+		Response: handlers.TeamsRefreshResponse{},
+	},
+	{
+		Method: "POST", Path: "/teams/update-images", OperationID: "updateTeamImages", Tag: "teams",
+		Summary:  "Re-upload team logos to storage",
+		Response: handlers.MessageResponse{},
+	},
+	{
+		Method: "GET", Path: "/espn/leagues", OperationID: "getESPNLeagues", Tag: "scrapers",
+		Summary:  "Scrape the ESPN league list",
+		Response: []services.ESPNLeague{},
+	},
+	{
+		Method: "GET", Path: "/wikidata/teams", OperationID: "getWikidataTeams", Tag: "scrapers",
+		Summary:  "List rugby union teams from Wikidata",
+		Response: []services.WikidataTeam{},
+	},
+	{
+		Method: "GET", Path: "/wikidata/teams/search", OperationID: "searchWikidataTeams", Tag: "scrapers",
+		Summary:  "Search Wikidata for a team by name",
+		Query:    []QueryParam{{Name: "name", Required: true}},
+		Response: services.WikidataTeam{},
+	},
+	{
+		Method: "POST", Path: "/rugbydb/teams", OperationID: "getRugbyDBTeams", Tag: "rugbydb",
+		Summary:  "Scrape and store RugbyDB teams",
+		Body:     services.TeamCreateRequest{},
+		Response: []services.RugbyDBTeam{},
+	},
+	{
+		Method: "GET", Path: "/rugbydb/leagues/:year", OperationID: "getRugbyDBLeagues", Tag: "rugbydb",
+		Summary:  "Scrape and store RugbyDB competitions for a year",
+		Query:    []QueryParam{{Name: "dry_run", Type: "boolean"}},
+		Response: []models.League{},
+	},
+	{
+		Method: "POST", Path: "/rugbydb/matches", OperationID: "scrapeRugbyDBMatches", Tag: "rugbydb",
+		Summary: "Scrape fixtures and results for seasons mapped to RugbyDB competitions",
+		Query: []QueryParam{
+			{Name: "year", Type: "integer", Description: "Only seasons starting in this year"},
+			{Name: "season_id", Description: "Only this season"},
+			{Name: "dry_run", Type: "boolean"},
+		},
+		Response: services.RugbyDBMatchesResult{},
+	},
+	{
+		Method: "GET", Path: "/rugbydb/leagues/ids/:year", OperationID: "getLeagueIDsByYear", Tag: "rugbydb",
+		Summary:  "List RugbyDB competition IDs mapped for a year",
+		Response: handlers.LeagueIDsResponse{},
+	},
+	{
+		Method: "GET", Path: "/api/rapidapi/competitions", OperationID: "getRugbyLiveCompetitions", Tag: "rapidapi",
+		Summary:  "Match Rugby Live Data competitions to stored leagues",
+		Response: handlers.CompetitionMappingResponse{},
+	},
+	{
+		Method: "POST", Path: "/api/rapidapi/matches", OperationID: "syncRugbyLiveMatches", Tag: "rapidapi",
+		Summary:  "Merge fixtures, results or live scores for active Rugby Live Data seasons into matches",
+		Query:    []QueryParam{{Name: "live", Type: "boolean", Description: "Poll live scores instead of full fixture lists"}},
+		Response: rapidapi.SyncResult{},
+	},
+	{
+		Method: "GET", Path: "/graphql", OperationID: "queryGraphQL", Tag: "graphql",
+		Summary: "Run a GraphQL query of at most 16 KiB, nested at most 10 fields deep",
+		Query: []QueryParam{
+			{Name: "query", Required: true},
+			{Name: "operationName"},
+			{Name: "variables", Description: "JSON-encoded variables"},
+		},
+		Response: graphql.Response{},
+	},
+	{
+		Method: "POST", Path: "/graphql", OperationID: "postGraphQL", Tag: "graphql",
+		Summary:  "Run a GraphQL query of at most 16 KiB, nested at most 10 fields deep",
+		Body:     graph.Request{},
+		Response: graphql.Response{},
+	},
+	{
+		Method: "GET", Path: "/admin/export/:table", OperationID: "exportTable", Tag: "admin", Admin: true,
+		Summary:  "Stream one table as CSV, JSON Lines or Parquet",
+		Query:    exportParams,
+		Produces: []string{"text/csv", "application/x-ndjson", "application/vnd.apache.parquet"},
+	},
+	{
+		Method: "POST", Path: "/admin/export", OperationID: "exportToStorage", Tag: "admin", Admin: true,
+		Summary:  "Write tables to the object store",
+		Query:    append([]QueryParam{{Name: "tables", Description: "Comma-separated table names; defaults to all"}}, exportParams...),
+		Response: handlers.ExportResponse{},
+	},
+	{
+		Method: "GET", Path: "/api/admin/conflicts", OperationID: "listConflicts", Tag: "admin", Admin: true,
+		Summary:  "List matches whose providers disagree on the result",
+		Query:    []QueryParam{{Name: "include_resolved", Type: "boolean", Description: "Include conflicts already settled by an override"}},
+		Response: handlers.ConflictsResponse{},
+	},
+	{
+		Method: "PUT", Path: "/api/admin/conflicts/:id/override", OperationID: "overrideScore", Tag: "admin", Admin: true,
+		Summary:  "Set a match's result by hand",
+		Body:     handlers.ScoreOverrideRequest{},
+		Response: models.Match{},
+	},
+	{
+		Method: "DELETE", Path: "/api/admin/conflicts/:id/override", OperationID: "clearScoreOverride", Tag: "admin", Admin: true,
+		Summary:  "Remove a match's override and restore the provider result",
+		Response: models.Match{},
+	},
+	{
+		Method: "GET", Path: "/api/admin/webhooks", OperationID: "listWebhookSubscriptions", Tag: "admin", Admin: true,
+		Summary:  "List webhook subscriptions, without their secrets",
+		Response: handlers.WebhookSubscriptionsResponse{},
+	},
+	{
+		Method: "POST", Path: "/api/admin/webhooks", OperationID: "createWebhookSubscription", Tag: "admin", Admin: true,
+		Summary:  "Subscribe an endpoint to match.kickoff, match.score, match.status, match.final or team.updated events",
+		Body:     handlers.WebhookSubscriptionRequest{},
+		Response: models.WebhookSubscription{},
+	},
+	{
+		Method: "DELETE", Path: "/api/admin/webhooks/:id", OperationID: "deleteWebhookSubscription", Tag: "admin", Admin: true,
+		Summary:  "Remove a webhook subscription and its dead letters",
+		Response: models.WebhookSubscription{},
+	},
+	{
+		Method: "GET", Path: "/api/admin/webhooks/dead-letters", OperationID: "listWebhookDeadLetters", Tag: "admin", Admin: true,
+		Summary:  "List webhook deliveries that failed every retry",
+		Query:    []QueryParam{{Name: "include_replayed", Type: "boolean", Description: "Include dead letters already replayed successfully"}},
+		Response: handlers.WebhookDeadLettersResponse{},
+	},
+	{
+		Method: "POST", Path: "/api/admin/webhooks/dead-letters/:id/replay", OperationID: "replayWebhookDeadLetter", Tag: "admin", Admin: true,
+		Summary:  "Send a dead-lettered delivery again",
+		Response: models.WebhookDeadLetter{},
+	},
+	{
+		Method: "GET", Path: "/api/live/ws", OperationID: "liveMatches", Tag: "matches",
+		Summary:  "WebSocket of live match snapshots and deltas for subscribed matches, leagues and teams",
+		Upgrade:  true,
+		Response: live.ServerMessage{},
+	},
+	{
+		Method: "GET", Path: "/openapi.json", OperationID: "getOpenAPI", Tag: "meta",
+		Summary:  "This document",
+		Response: map[string]interface{}{},
+	},
+	{
+		Method: "GET", Path: "/healthz", OperationID: "getHealth", Tag: "meta",
+		Summary:  "Liveness: 200 while the process is serving",
+		Response: handlers.HealthResponse{},
+	},
+	{
+		Method: "GET", Path: "/readyz", OperationID: "getReadiness", Tag: "meta",
+		Summary:  "Readiness: checks the database, its migration version and object storage; 503 when any fails or during shutdown",
+		Response: handlers.ReadinessResponse{},
+	},
+	{
+		Method: "GET", Path: "/metrics", OperationID: "getMetrics", Tag: "meta",
+		Summary:  "Prometheus metrics for HTTP routes, providers, ingestion and live matches",
+		Produces: []string{"text/plain"},
+	},
+}
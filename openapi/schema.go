@@ -0,0 +1,189 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// namePrefixes disambiguates component names for packages whose type names
+// are too generic to stand alone in the document.
+var namePrefixes = map[string]string{
+	"rugby-live-api/graph":                       "GraphQL",
+	"github.com/graph-gophers/graphql-go":        "GraphQL",
+	"github.com/graph-gophers/graphql-go/errors": "GraphQL",
+}
+
+// registry turns Go types into schemas, collecting named struct types as
+// reusable components.
+type registry struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newRegistry() *registry {
+	return &registry{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+func typeOf(v interface{}) reflect.Type {
+	if t, ok := v.(reflect.Type); ok {
+		return t
+	}
+	return reflect.TypeOf(v)
+}
+
+// enumerated is implemented by string types with a fixed set of values.
+type enumerated interface {
+	EnumValues() []string
+}
+
+func (r *registry) schemaOf(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(r.schemaOf(t.Elem()))
+	case reflect.String:
+		if e, ok := reflect.Zero(t).Interface().(enumerated); ok {
+			return &Schema{Type: "string", Enum: e.EnumValues()}
+		}
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: r.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaOf(t.Elem())}
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		return r.ref(t)
+	}
+	return &Schema{}
+}
+
+func (r *registry) ref(t reflect.Type) *Schema {
+	name, ok := r.names[t]
+	if !ok {
+		name = r.componentName(t)
+		r.names[t] = name
+		// Reserve the name before descending so recursive types terminate.
+		r.schemas[name] = &Schema{}
+		*r.schemas[name] = *r.structSchema(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (r *registry) componentName(t reflect.Type) string {
+	name := genericName(t.Name())
+	if prefix, ok := namePrefixes[t.PkgPath()]; ok {
+		name = prefix + name
+	}
+	if _, taken := r.schemas[name]; taken {
+		pkg := t.PkgPath()
+		pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+		name = exported(pkg) + name
+	}
+	return name
+}
+
+// genericName flattens an instantiated generic type name such as
+// Page[rugby-live-api/models.Match] into PageMatch.
+func genericName(name string) string {
+	open := strings.Index(name, "[")
+	if open < 0 {
+		return name
+	}
+	base := name[:open]
+	for _, arg := range strings.Split(strings.TrimSuffix(name[open+1:], "]"), ",") {
+		arg = arg[strings.LastIndex(arg, ".")+1:]
+		base += exported(strings.TrimLeft(arg, "*[]"))
+	}
+	return base
+}
+
+func exported(s string) string {
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+func (r *registry) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	r.addFields(schema, t)
+	return schema
+}
+
+func (r *registry) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				r.addFields(schema, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = r.schemaOf(field.Type)
+		if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Ptr {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// nullable marks s as accepting null. References cannot carry siblings in
+// OpenAPI 3.0, so they are wrapped in allOf.
+func nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{AllOf: []*Schema{s}, Nullable: true}
+	}
+	if s.Type == "" {
+		return s
+	}
+	s.Nullable = true
+	return s
+}
@@ -0,0 +1,222 @@
+// Package openapi describes the HTTP API as an OpenAPI 3 document. The
+// document is built from the Routes table and the Go types the handlers
+// return, so response schemas cannot fall out of step with the code.
+package openapi
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem maps a lower-case HTTP method to its operation.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// adminScheme names the security scheme of routes marked Admin.
+const adminScheme = "adminToken"
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+}
+
+const jsonContent = "application/json"
+
+var (
+	buildOnce sync.Once
+	document  *Document
+)
+
+// Spec returns the OpenAPI document for Routes. It is built once and shared,
+// so callers must not modify it.
+func Spec() *Document {
+	buildOnce.Do(func() {
+		document = Build(Routes)
+	})
+	return document
+}
+
+// Handler serves the OpenAPI document as JSON.
+func Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, Spec())
+	}
+}
+
+// Build assembles an OpenAPI document describing routes.
+func Build(routes []Route) *Document {
+	reg := newRegistry()
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "Rugby Live API",
+			Description: "Fixtures, results and reference data for rugby union competitions.",
+			Version:     "1.0.0",
+		},
+		Paths: make(map[string]PathItem),
+	}
+
+	for _, route := range routes {
+		op := &Operation{
+			OperationID: route.OperationID,
+			Summary:     route.Summary,
+			Responses: map[string]Response{
+				"default": {
+					Description: "Error",
+					Content:     map[string]MediaType{jsonContent: {Schema: reg.schemaOf(errorType)}},
+				},
+			},
+		}
+		if route.Tag != "" {
+			op.Tags = []string{route.Tag}
+		}
+		if route.Admin {
+			op.Security = []map[string][]string{{adminScheme: {}}}
+			doc.Components.SecuritySchemes = map[string]SecurityScheme{adminScheme: {
+				Type:        "http",
+				Scheme:      "bearer",
+				Description: "The server's ADMIN_TOKEN",
+			}}
+		}
+
+		for _, name := range pathParams(route.Path) {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+		for _, p := range route.Query {
+			op.Parameters = append(op.Parameters, p.parameter())
+		}
+
+		if route.Body != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{jsonContent: {Schema: reg.schemaOf(typeOf(route.Body))}},
+			}
+		}
+
+		if route.Upgrade {
+			op.Responses["101"] = Response{
+				Description: "Switching Protocols to a WebSocket carrying these messages",
+				Content:     map[string]MediaType{jsonContent: {Schema: reg.schemaOf(typeOf(route.Response))}},
+			}
+			addOperation(doc, route, op)
+			continue
+		}
+
+		ok := Response{Description: "OK"}
+		switch {
+		case len(route.Produces) > 0:
+			ok.Content = make(map[string]MediaType)
+			for _, mediaType := range route.Produces {
+				ok.Content[mediaType] = MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+			}
+		case route.Response != nil:
+			ok.Content = map[string]MediaType{jsonContent: {Schema: reg.schemaOf(typeOf(route.Response))}}
+		}
+		op.Responses["200"] = ok
+		addOperation(doc, route, op)
+	}
+
+	doc.Components.Schemas = reg.schemas
+	return doc
+}
+
+func addOperation(doc *Document, route Route, op *Operation) {
+	path := openAPIPath(route.Path)
+	if doc.Paths[path] == nil {
+		doc.Paths[path] = PathItem{}
+	}
+	doc.Paths[path][strings.ToLower(route.Method)] = op
+}
+
+// openAPIPath converts a Gin path such as /leagues/:id to /leagues/{id}.
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			segments[i] = "{" + seg[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func pathParams(path string) []string {
+	var names []string
+	for _, seg := range strings.Split(path, "/") {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			names = append(names, seg[1:])
+		}
+	}
+	return names
+}
@@ -0,0 +1,84 @@
+// Package routes holds the service's route table, shared by the server and
+// the OpenAPI drift check.
+package routes
+
+import (
+	"rugby-live-api/handlers"
+	"rugby-live-api/metrics"
+	"rugby-live-api/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Register wires every public route. Each one must also be described in
+// openapi.Routes; the check-openapi command and the openapi package's tests
+// fail when the two drift apart.
+func Register(router *gin.Engine, h *handlers.Handler, graphqlHandler gin.HandlerFunc) {
+	router.GET("/matches", h.GetMatches)
+	router.GET("/countries", h.GetCountries)
+	router.POST("/countries/refresh", h.RefreshCountries)
+	router.POST("/leagues/refresh", h.RefreshLeagues)
+	router.POST("/teams/refresh", h.RefreshTeams)
+	router.POST("/teams/update-images", h.UpdateTeamImages)
+	router.GET("/espn/leagues", h.GetESPNLeagues)
+	router.GET("/wikidata/teams", h.GetWikidataTeams)
+	router.GET("/wikidata/teams/search", h.SearchWikidataTeams)
+	router.POST("/rugbydb/teams", h.GetRugbyDBTeams)
+	router.GET("/rugbydb/leagues/:year", h.GetRugbyDBLeagues)
+	router.POST("/rugbydb/matches", h.ScrapeRugbyDBMatches)
+	router.GET("/leagues/map-api-sports", h.MapAPISportsLeagues)
+	router.GET("/rugbydb/leagues/ids/:year", h.GetLeagueIDsByYear)
+	router.GET("/matches/api-sports/league", h.GetMatchesByLeague)
+
+	// GraphQL
+	router.GET("/graphql", graphqlHandler)
+	router.POST("/graphql", graphqlHandler)
+
+	// API description
+	router.GET("/openapi.json", openapi.Handler())
+
+	// Monitoring
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	router.GET("/healthz", h.Healthz)
+	router.GET("/readyz", h.Readyz)
+
+	// Admin
+	admin := router.Group("/admin", h.RequireAdmin)
+	{
+		admin.GET("/export/:table", h.ExportTable)
+		admin.POST("/export", h.ExportToStorage)
+	}
+
+	// API routes
+	api := router.Group("/api")
+	{
+		api.GET("/teams", h.ListTeams)
+		api.GET("/teams/:id/form", h.GetTeamForm)
+		api.GET("/teams/:id/stats", h.GetTeamStats)
+		api.GET("/leagues", h.ListLeagues)
+		api.GET("/leagues/:id/tree", h.GetLeagueTree)
+		api.GET("/leagues/:id/lineage", h.GetLeagueLineage)
+		api.GET("/seasons", h.ListSeasons)
+		api.GET("/matches", h.ListMatches)
+		api.GET("/matches/:id/status-history", h.GetMatchStatusHistory)
+		api.GET("/rapidapi/competitions", h.GetRugbyLiveCompetitions)
+		api.POST("/rapidapi/matches", h.SyncRugbyLiveMatches)
+		api.GET("/live/ws", h.LiveWS)
+
+		apiAdmin := api.Group("/admin", h.RequireAdmin)
+		{
+			apiAdmin.GET("/conflicts", h.ListConflicts)
+			apiAdmin.PUT("/conflicts/:id/override", h.OverrideScore)
+			apiAdmin.DELETE("/conflicts/:id/override", h.ClearScoreOverride)
+		}
+
+		webhooks := apiAdmin.Group("/webhooks")
+		{
+			webhooks.GET("", h.ListWebhookSubscriptions)
+			webhooks.POST("", h.CreateWebhookSubscription)
+			webhooks.DELETE("/:id", h.DeleteWebhookSubscription)
+			webhooks.GET("/dead-letters", h.ListWebhookDeadLetters)
+			webhooks.POST("/dead-letters/:id/replay", h.ReplayWebhookDeadLetter)
+		}
+	}
+}
@@ -1,30 +1,72 @@
 package services
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"net/http"
+	"rugby-live-api/config"
+	"rugby-live-api/metrics"
+	"rugby-live-api/services/pool"
+	"rugby-live-api/services/retry"
 	"time"
 )
 
+// storageTimeout bounds object storage requests, which carry whole exports.
+const storageTimeout = 5 * time.Minute
+
 type APIClient struct {
-	client *http.Client
+	providers config.Providers
+	storage   config.Storage
+
+	// Each provider has its own client so its configured timeout, quota and
+	// retries apply. ESPN is scraped through colly, which takes a transport.
+	apiSports *retry.Client
+	rugbyDB   *retry.Client
+	wikidata  *retry.Client
+	espn      http.RoundTripper
+	client    *retry.Client // Object storage
+	log       *slog.Logger
 }
 
-func NewAPIClient() *APIClient {
+func NewAPIClient(cfg *config.Config, logger *slog.Logger) *APIClient {
 	client := &APIClient{
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		providers: cfg.Providers,
+		storage:   cfg.Storage,
+		apiSports: retry.NewProviderClient(cfg.Providers.APISports),
+		rugbyDB:   retry.NewProviderClient(cfg.Providers.RugbyDB),
+		wikidata:  retry.NewProviderClient(cfg.Providers.Wikidata),
+		espn:      pool.NewTransport(cfg.Providers.ESPN, metrics.Transport("espn", nil)),
+		client:    retry.NewClient(metrics.NewHTTPClient(storageTimeout), retry.Default),
+		log:       logger,
 	}
 
 	// Ensure bucket exists
-	if err := client.createBucketIfNotExists(); err != nil {
-		log.Printf("Error creating bucket: %v", err)
+	if client.StorageEnabled() {
+		ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+		defer cancel()
+		if err := client.createBucketIfNotExists(ctx); err != nil {
+			logger.Error("Error creating bucket", "error", err)
+		}
 	}
 
 	return client
 }
 
+// apiSportsRequest builds a GET of path, with its query, on API Sports,
+// authenticated with the configured key.
+func (a *APIClient) apiSportsRequest(ctx context.Context, path string) (*http.Request, error) {
+	if err := a.providers.APISports.Require("api_sports"); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", a.providers.APISports.BaseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("x-rapidapi-key", a.providers.APISports.APIKey)
+	req.Header.Add("x-rapidapi-host", req.URL.Host)
+	return req, nil
+}
+
 // func (a *APIClient) createBucketIfNotExists() error {
 // 	baseURL := strings.TrimSuffix(os.Getenv("SUPABASE_URL"), "/storage/v1/s3")
 // 	url := fmt.Sprintf("%s/storage/v1/bucket", baseURL)
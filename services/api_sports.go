@@ -2,16 +2,20 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
-	"net/http"
-	"os"
+	"log/slog"
 	"rugby-live-api/db"
+	"rugby-live-api/metrics"
 	"rugby-live-api/models"
+	"rugby-live-api/services/kickoff"
+	"rugby-live-api/services/pool"
+	"rugby-live-api/services/reconcile"
 	"rugby-live-api/services/rugbydb"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -41,26 +45,28 @@ type APIParams struct {
 	LeagueID string
 	Season   string
 	Date     string
+	Timezone string // IANA zone that Date is expressed in; empty means UTC
 }
 
 type Match struct {
-	ID                string          `json:"id"`
-	HomeTeamID        string          `json:"home_team_id"`
-	AwayTeamID        string          `json:"away_team_id"`
-	LeagueID          string          `json:"league_id"`
-	HomeScore         int             `json:"home_score"`
-	AwayScore         int             `json:"away_score"`
-	Status            string          `json:"status"`
-	KickOff           time.Time       `json:"kick_off"`
-	Date              string          `json:"date"`
-	Time              string          `json:"time"`
-	Venue             string          `json:"venue,omitempty"`
-	Referee           string          `json:"referee,omitempty"`
-	Attendance        int             `json:"attendance,omitempty"`
-	WeatherConditions string          `json:"weather_conditions,omitempty"`
-	HeadToHead        json.RawMessage `json:"head_to_head,omitempty"`
-	Lineups           json.RawMessage `json:"lineups,omitempty"`
-	LiveStats         json.RawMessage `json:"live_stats,omitempty"`
+	ID                string             `json:"id"`
+	HomeTeamID        string             `json:"home_team_id"`
+	AwayTeamID        string             `json:"away_team_id"`
+	LeagueID          string             `json:"league_id"`
+	HomeScore         int                `json:"home_score"`
+	AwayScore         int                `json:"away_score"`
+	Status            models.MatchStatus `json:"status"`
+	KickOff           time.Time          `json:"kick_off"`
+	Date              string             `json:"date"`
+	Time              string             `json:"time"`
+	VenueTimezone     string             `json:"venue_timezone"`
+	Venue             string             `json:"venue,omitempty"`
+	Referee           string             `json:"referee,omitempty"`
+	Attendance        int                `json:"attendance,omitempty"`
+	WeatherConditions string             `json:"weather_conditions,omitempty"`
+	HeadToHead        json.RawMessage    `json:"head_to_head,omitempty"`
+	Lineups           json.RawMessage    `json:"lineups,omitempty"`
+	LiveStats         json.RawMessage    `json:"live_stats,omitempty"`
 }
 
 type DailyMatches struct {
@@ -68,19 +74,18 @@ type DailyMatches struct {
 	MatchIDs []string `json:"match_ids"`
 }
 
-func (a *APIClient) FetchFromAPISports() ([]models.Match, error) {
-	today := time.Now().Format("2006-01-02")
-	url := fmt.Sprintf("https://v1.rugby.api-sports.io/games?date=%s", today)
+// FetchFromAPISports fetches today's games, where "today" is the calendar day
+// in the caller's timezone.
+func (a *APIClient) FetchFromAPISports(ctx context.Context, loc *time.Location) ([]models.Match, error) {
+	today := kickoff.Today(loc)
+	path := fmt.Sprintf("/games?date=%s&timezone=%s", today, loc.String())
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := a.apiSportsRequest(ctx, path)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("x-rapidapi-key", os.Getenv("API_SPORTS_KEY"))
-	req.Header.Add("x-rapidapi-host", "v1.rugby.api-sports.io")
-
-	resp, err := a.client.Do(req)
+	resp, err := a.apiSports.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -94,10 +99,26 @@ func (a *APIClient) FetchFromAPISports() ([]models.Match, error) {
 	return a.standardizeAPISportsData(apiResp), nil
 }
 
+// apiSportsStatus maps an API-Sports status, preferring the short code.
+// Unknown statuses are logged and treated as scheduled.
+func apiSportsStatus(short, long string) models.MatchStatus {
+	for _, value := range []string{short, long} {
+		if status, ok := models.ProviderStatus("api_sports", value); ok {
+			return status
+		}
+	}
+	slog.Warn("Unknown API-Sports status", "status", long, "short", short)
+	return models.StatusScheduled
+}
+
 func (a *APIClient) standardizeAPISportsData(resp models.APISportsTodaysMatchesResponse) []models.Match {
 	var matches []models.Match
 	for _, game := range resp.Response {
-		kickOff, _ := time.Parse("2006-01-02T15:04:05-07:00", game.Date)
+		kickOff, err := kickoff.Parse(game.Date, game.Timezone)
+		if err != nil {
+			a.log.Warn("Skipping API Sports game", "game_id", game.ID, "error", err)
+			continue
+		}
 
 		homeTeam := &models.Team{
 			ID:      fmt.Sprintf("%s-%s", game.Country.Code, strings.ToUpper(strings.ReplaceAll(game.Teams.Home.Name, " ", ""))),
@@ -146,46 +167,43 @@ func (a *APIClient) standardizeAPISportsData(resp models.APISportsTodaysMatchesR
 		}
 
 		match := models.Match{
-			ID:          fmt.Sprintf("%s-%s-%s", kickOff.Format("2006-01-02"), homeTeam.ID, awayTeam.ID),
-			HomeTeam:    homeTeam,
-			AwayTeam:    awayTeam,
-			League:      league,
-			HomeScore:   game.Scores.Home,
-			AwayScore:   game.Scores.Away,
-			Status:      game.Status.Long,
-			KickOff:     kickOff,
-			Date:        kickOff.Format("2006-01-02"),
-			Time:        kickOff.Format("15:04"),
-			Week:        game.Week,
-			Season:      game.League.Season,
-			APISportsID: game.ID,
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
+			ID:            fmt.Sprintf("%s-%s-%s", kickOff.Format("2006-01-02"), homeTeam.ID, awayTeam.ID),
+			HomeTeam:      homeTeam,
+			AwayTeam:      awayTeam,
+			League:        league,
+			HomeScore:     game.Scores.Home,
+			AwayScore:     game.Scores.Away,
+			Status:        apiSportsStatus(game.Status.Short, game.Status.Long),
+			KickOff:       kickOff,
+			Date:          kickOff.Format("2006-01-02"),
+			Time:          kickOff.Format("15:04"),
+			VenueTimezone: kickoff.VenueTimezone(game.Country.Code, game.Date),
+			Week:          game.Week,
+			Season:        game.League.Season,
+			APISportsID:   game.ID,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
 		}
 
 		matches = append(matches, match)
-		log.Printf("Processing match: %d %s vs %s in %s (%s)",
-			match.APISportsID,
-			homeTeam.Name,
-			awayTeam.Name,
-			league.Name,
-			match.Status,
+		a.log.Debug("Processing match",
+			"api_sports_id", match.APISportsID,
+			"home", homeTeam.Name,
+			"away", awayTeam.Name,
+			"league", league.Name,
+			"status", match.Status,
 		)
 	}
 	return matches
 }
 
-func (a *APIClient) FetchAndStoreCountries(store *db.Store, updateFlags bool) ([]countryChange, error) {
-	url := "https://v1.rugby.api-sports.io/countries"
-	req, err := http.NewRequest("GET", url, nil)
+func (a *APIClient) FetchAndStoreCountries(ctx context.Context, store *db.Store, updateFlags bool) ([]CountryChange, error) {
+	req, err := a.apiSportsRequest(ctx, "/countries")
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("x-rapidapi-key", os.Getenv("API_SPORTS_KEY"))
-	req.Header.Add("x-rapidapi-host", "v1.rugby.api-sports.io")
-
-	resp, err := a.client.Do(req)
+	resp, err := a.apiSports.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -196,9 +214,12 @@ func (a *APIClient) FetchAndStoreCountries(store *db.Store, updateFlags bool) ([
 		return nil, err
 	}
 
-	var changes []countryChange
+	var changes []CountryChange
 
 	for _, c := range countriesResp.Response {
+		if err := ctx.Err(); err != nil {
+			return changes, err
+		}
 		countryCode := c.Code
 		switch c.Name {
 		case "Australia-Oceania":
@@ -217,23 +238,23 @@ func (a *APIClient) FetchAndStoreCountries(store *db.Store, updateFlags bool) ([
 			countryCode = "WRLD"
 		}
 
-		existing, err := store.GetCountryByCode(countryCode)
+		existing, err := store.GetCountryByCode(ctx, countryCode)
 		if err != nil && err != sql.ErrNoRows {
 			return nil, fmt.Errorf("error checking existing country: %v", err)
 		}
 
 		flagURL := c.Flag
 		if existing == nil {
-			newFlagURL, err := a.downloadAndStoreImage(c.Flag, fmt.Sprintf("flags/%s.svg", strings.ToLower(countryCode)))
+			newFlagURL, err := a.downloadAndStoreImage(ctx, c.Flag, fmt.Sprintf("flags/%s.svg", strings.ToLower(countryCode)))
 			if err != nil {
-				log.Printf("Error downloading flag for %s: %v", countryCode, err)
+				a.log.Error("Error downloading flag", "country_code", countryCode, "error", err)
 			} else {
 				flagURL = newFlagURL
 			}
 		} else if updateFlags && existing.FlagSource == "api_sports" && existing.Flag != c.Flag {
-			newFlagURL, err := a.downloadAndStoreImage(c.Flag, fmt.Sprintf("flags/%s.svg", strings.ToLower(countryCode)))
+			newFlagURL, err := a.downloadAndStoreImage(ctx, c.Flag, fmt.Sprintf("flags/%s.svg", strings.ToLower(countryCode)))
 			if err != nil {
-				log.Printf("Error downloading flag for %s: %v", countryCode, err)
+				a.log.Error("Error downloading flag", "country_code", countryCode, "error", err)
 			} else {
 				flagURL = newFlagURL
 			}
@@ -247,7 +268,7 @@ func (a *APIClient) FetchAndStoreCountries(store *db.Store, updateFlags bool) ([
 		}
 
 		// Track changes
-		change := countryChange{
+		change := CountryChange{
 			Name:    c.Name,
 			Code:    countryCode,
 			Changes: make(map[string]interface{}),
@@ -257,21 +278,21 @@ func (a *APIClient) FetchAndStoreCountries(store *db.Store, updateFlags bool) ([
 		if existing != nil {
 			if existing.Name != country.Name {
 				change.Changes["name"] = map[string]string{"old": existing.Name, "new": country.Name}
-				if err := store.UpsertCountry(country); err != nil {
-					log.Printf("Error upserting country %s: %v", countryCode, err)
+				if err := store.UpsertCountry(ctx, country); err != nil {
+					a.log.Error("Error upserting country", "country_code", countryCode, "error", err)
 					continue
 				}
 			}
 			if existing.Flag != country.Flag {
 				change.Changes["flag"] = map[string]string{"old": existing.Flag, "new": country.Flag}
-				if err := store.UpsertCountry(country); err != nil {
-					log.Printf("Error upserting country %s: %v", countryCode, err)
+				if err := store.UpsertCountry(ctx, country); err != nil {
+					a.log.Error("Error upserting country", "country_code", countryCode, "error", err)
 					continue
 				}
 			}
 		} else if existing == nil {
-			if err := store.UpsertCountry(country); err != nil {
-				log.Printf("Error upserting country %s: %v", countryCode, err)
+			if err := store.UpsertCountry(ctx, country); err != nil {
+				a.log.Error("Error upserting country", "country_code", countryCode, "error", err)
 				continue
 			}
 			changes = append(changes, change)
@@ -288,30 +309,21 @@ func (a *APIClient) FetchAndStoreCountries(store *db.Store, updateFlags bool) ([
 			APIID:      fmt.Sprintf("%d", c.ID),
 			EntityType: "country",
 		}
-		if err := store.UpsertAPIMapping(mapping); err != nil {
-			log.Printf("Error creating API mapping for country %s: %v", countryCode, err)
+		if err := store.UpsertAPIMapping(ctx, mapping); err != nil {
+			a.log.Error("Error creating API mapping for country", "country_code", countryCode, "error", err)
 		}
 	}
 
 	return changes, nil
 }
 
-func (a *APIClient) FetchAndStoreLeagues(store *db.Store, updateImages bool) ([]leagueChange, error) {
-	url := "https://v1.rugby.api-sports.io/leagues"
-	req, err := http.NewRequest("GET", url, nil)
+func (a *APIClient) FetchAndStoreLeagues(ctx context.Context, store *db.Store, updateImages bool, report *db.IngestionReport) ([]LeagueChange, error) {
+	req, err := a.apiSportsRequest(ctx, "/leagues")
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("x-rapidapi-key", os.Getenv("API_SPORTS_KEY"))
-	req.Header.Add("x-rapidapi-host", "v1.rugby.api-sports.io")
-
-	// Increase timeout for large requests
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-
-	resp, err := client.Do(req)
+	resp, err := a.apiSports.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -322,20 +334,23 @@ func (a *APIClient) FetchAndStoreLeagues(store *db.Store, updateImages bool) ([]
 		return nil, err
 	}
 
-	var changes []leagueChange
+	var changes []LeagueChange
 
 	// Process leagues in batches
 	batchSize := 10
 	for i := 0; i < len(leaguesResp.Response); i += batchSize {
+		if err := ctx.Err(); err != nil {
+			return changes, err
+		}
 		end := i + batchSize
 		if end > len(leaguesResp.Response) {
 			end = len(leaguesResp.Response)
 		}
 
-		log.Printf("Processing leagues %d to %d of %d", i+1, end, len(leaguesResp.Response))
+		a.log.Info("Processing leagues", "from", i+1, "to", end, "total", len(leaguesResp.Response))
 		batch := leaguesResp.Response[i:end]
 		for _, l := range batch {
-			log.Printf("Processing leagues for %s (Country code: %s)", l.Country.Name, l.Country.Code)
+			a.log.Debug("Processing league", "league", l.Name, "country", l.Country.Name, "country_code", l.Country.Code)
 			// Clean up country code
 			countryCode := l.Country.Code
 			switch l.Country.Name {
@@ -371,13 +386,13 @@ func (a *APIClient) FetchAndStoreLeagues(store *db.Store, updateImages bool) ([]
 				Flag: l.Country.Flag,
 			}
 
-			existing, err := store.GetLeagueByID(fmt.Sprintf("%s-%s", countryCode, cleanLeagueName))
+			existing, err := store.GetLeagueByID(ctx, fmt.Sprintf("%s-%s", countryCode, cleanLeagueName))
 			if err != nil && err != sql.ErrNoRows {
-				log.Printf("Error checking existing league %s: %v", l.Name, err)
+				a.log.Error("Error checking existing league", "league", l.Name, "error", err)
 				continue
 			}
 
-			change := leagueChange{
+			change := LeagueChange{
 				Name:    l.Name,
 				ID:      fmt.Sprintf("%s-%s", countryCode, cleanLeagueName),
 				Changes: make(map[string]interface{}),
@@ -395,9 +410,9 @@ func (a *APIClient) FetchAndStoreLeagues(store *db.Store, updateImages bool) ([]
 					".", "",
 				))
 
-				newLogoURL, err := a.downloadAndStoreImage(l.Logo, fmt.Sprintf("logos/leagues/%s/%s.png", countryCode, cleanName))
+				newLogoURL, err := a.downloadAndStoreImage(ctx, l.Logo, fmt.Sprintf("logos/leagues/%s/%s.png", countryCode, cleanName))
 				if err != nil {
-					log.Printf("Error downloading logo for %s: %v", l.Name, err)
+					a.log.Error("Error downloading league logo", "league", l.Name, "error", err)
 				} else {
 					logoURL = newLogoURL
 					if existing != nil {
@@ -461,27 +476,27 @@ func (a *APIClient) FetchAndStoreLeagues(store *db.Store, updateImages bool) ([]
 				}
 			}
 
-			if err := store.UpsertLeague(league); err != nil {
-				log.Printf("Error upserting league %s: %v", l.Name, err)
+			// The league and its mapping are stored as one unit.
+			err = store.Unit(ctx, report, db.UnitLeague, fmt.Sprintf("%d", l.ID), func(tx *db.Store) error {
+				if err := tx.UpsertLeague(ctx, league); err != nil {
+					return db.Step("league", err)
+				}
+				mapping := &models.APIMapping{
+					EntityID:   league.ID,
+					APIName:    "api_sports",
+					APIID:      fmt.Sprintf("%d", l.ID),
+					EntityType: "league",
+				}
+				return db.Step("league mapping", tx.UpsertAPIMapping(ctx, mapping))
+			})
+			if err != nil {
 				continue
 			}
 
 			if change.IsNew {
-				log.Printf("Added new league: %s", l.Name)
+				a.log.Info("Added league", "league", l.Name)
 			} else if len(change.Changes) > 0 {
-				log.Printf("Updated league: %s with changes: %v", l.Name, change.Changes)
-			}
-
-			// Store API mapping for the league
-			mapping := &models.APIMapping{
-				EntityID:   league.ID,
-				APIName:    "api_sports",
-				APIID:      fmt.Sprintf("%d", l.ID),
-				EntityType: "league",
-			}
-
-			if err := store.UpsertAPIMapping(mapping); err != nil {
-				log.Printf("Error creating API mapping for league %s: %v", l.Name, err)
+				a.log.Info("Updated league", "league", l.Name, "changes", change.Changes)
 			}
 		}
 	}
@@ -489,18 +504,14 @@ func (a *APIClient) FetchAndStoreLeagues(store *db.Store, updateImages bool) ([]
 	return changes, nil
 }
 
-func (a *APIClient) FetchAndStoreTeams(store *db.Store, updateImages bool, params TeamSearchParams) ([]teamChange, []failedTeam, error) {
-	var allChanges []teamChange
-	var allFailedTeams []failedTeam
-
-	// Rate limiting
-	rateLimiter := time.NewTicker(time.Second / 10) // 10 requests per second
-	defer rateLimiter.Stop()
+func (a *APIClient) FetchAndStoreTeams(ctx context.Context, store *db.Store, updateImages bool, params TeamSearchParams, report *db.IngestionReport) ([]TeamChange, []FailedTeam, error) {
+	var allChanges []TeamChange
+	var allFailedTeams []FailedTeam
 
 	if params.CountryID != "" {
 		// Fetch teams for specific country
-		url := fmt.Sprintf("https://v1.rugby.api-sports.io/teams?country_id=%s", params.CountryID)
-		changes, failedTeams, err := a.fetchTeamsForCountry(store, url, updateImages)
+		path := fmt.Sprintf("/teams?country_id=%s", params.CountryID)
+		changes, failedTeams, err := a.fetchTeamsForCountry(ctx, store, path, updateImages, report)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -508,49 +519,65 @@ func (a *APIClient) FetchAndStoreTeams(store *db.Store, updateImages bool, param
 		allFailedTeams = append(allFailedTeams, failedTeams...)
 	} else {
 		// Fetch teams for all countries
-		countryMappings, err := store.GetAPIMappingsByType("api_sports", "country")
+		countryMappings, err := store.GetAPIMappingsByType(ctx, "api_sports", "country")
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to get country mappings: %v", err)
 		}
 
-		for _, mapping := range countryMappings {
-			log.Printf("Fetching teams for country: %s (API ID: %s)", mapping.EntityID, mapping.APIID)
-			<-rateLimiter.C
-
-			url := fmt.Sprintf("https://v1.rugby.api-sports.io/teams?country_id=%s", mapping.APIID)
-			changes, failedTeams, err := a.fetchTeamsForCountry(store, url, updateImages)
-			if err != nil {
-				log.Printf("Error fetching teams for country %s: %v", mapping.EntityID, err)
-				continue
+		// Countries are fetched side by side, as far as API Sports' quota
+		// allows, and their results gathered in country order. A country
+		// that fails is reported in place of its teams.
+		results := pool.Map(ctx, countryMappings, a.providers.APISports.Concurrency,
+			func(ctx context.Context, mapping models.APIMapping) (countryTeams, error) {
+				a.log.Info("Fetching teams for country", "country_code", mapping.EntityID, "api_id", mapping.APIID)
+				path := fmt.Sprintf("/teams?country_id=%s", mapping.APIID)
+				changes, failedTeams, err := a.fetchTeamsForCountry(ctx, store, path, updateImages, report)
+				return countryTeams{changes: changes, failed: failedTeams}, err
+			})
+		for i, r := range results {
+			mapping := countryMappings[i]
+			if r.Err != nil {
+				a.log.Error("Error fetching teams for country", "country_code", mapping.EntityID, "error", r.Err)
+				countryID, _ := strconv.Atoi(mapping.APIID)
+				allFailedTeams = append(allFailedTeams, FailedTeam{
+					CountryID:   countryID,
+					CountryName: mapping.EntityID,
+					Reason:      fmt.Sprintf("Failed to fetch teams for country: %v", r.Err),
+				})
 			}
-			log.Printf("Fetched %d teams for country %s", len(changes), mapping.EntityID)
-			allChanges = append(allChanges, changes...)
-			allFailedTeams = append(allFailedTeams, failedTeams...)
+			a.log.Info("Fetched teams for country", "country_code", mapping.EntityID, "count", len(r.Value.changes))
+			allChanges = append(allChanges, r.Value.changes...)
+			allFailedTeams = append(allFailedTeams, r.Value.failed...)
+		}
+		if err := ctx.Err(); err != nil {
+			return allChanges, allFailedTeams, err
 		}
 	}
 
 	if len(allFailedTeams) > 0 {
-		log.Printf("\nTeams that could not be processed:")
 		for _, ft := range allFailedTeams {
-			log.Printf("- %s\n  Country: (ID: %d, Name: %s)\n  Reason: %s\n  Data: %+v\n",
-				ft.Name, ft.CountryID, ft.CountryName, ft.Reason, ft.TeamData)
+			a.log.Warn("Team could not be processed",
+				"team", ft.Name, "country_id", ft.CountryID, "country", ft.CountryName, "reason", ft.Reason)
 		}
-		log.Printf("Total failed teams: %d", len(allFailedTeams))
+		a.log.Warn("Some teams could not be processed", "count", len(allFailedTeams))
 	}
 
 	return allChanges, allFailedTeams, nil
 }
 
-func (a *APIClient) fetchTeamsForCountry(store *db.Store, url string, updateImages bool) ([]teamChange, []failedTeam, error) {
-	req, err := http.NewRequest("GET", url, nil)
+// countryTeams is what fetchTeamsForCountry made of one country's teams.
+type countryTeams struct {
+	changes []TeamChange
+	failed  []FailedTeam
+}
+
+func (a *APIClient) fetchTeamsForCountry(ctx context.Context, store *db.Store, path string, updateImages bool, report *db.IngestionReport) ([]TeamChange, []FailedTeam, error) {
+	req, err := a.apiSportsRequest(ctx, path)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	req.Header.Add("x-rapidapi-key", os.Getenv("API_SPORTS_KEY"))
-	req.Header.Add("x-rapidapi-host", "v1.rugby.api-sports.io")
-
-	resp, err := a.client.Do(req)
+	resp, err := a.apiSports.Do(req)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -560,33 +587,33 @@ func (a *APIClient) fetchTeamsForCountry(store *db.Store, url string, updateImag
 	if err := json.NewDecoder(resp.Body).Decode(&teamsResp); err != nil {
 		return nil, nil, err
 	}
-	log.Printf("Teams response: %+v", teamsResp)
 
 	if teamsResp.Errors != nil {
 		if errArray, ok := teamsResp.Errors.([]interface{}); ok {
 			if len(errArray) > 0 {
-				log.Printf("API returned errors: %v", teamsResp.Errors)
 				return nil, nil, fmt.Errorf("API errors: %v", teamsResp.Errors)
 			}
 		} else if errMap, ok := teamsResp.Errors.(map[string]string); ok && len(errMap) > 0 {
-			log.Printf("API returned errors: %v", teamsResp.Errors)
 			return nil, nil, fmt.Errorf("API errors: %v", teamsResp.Errors)
 		}
 	}
 
 	if len(teamsResp.Response) == 0 {
-		log.Printf("No teams returned for this country")
+		a.log.Debug("No teams returned", "path", path)
 		return nil, nil, nil
 	}
 
-	var changes []teamChange
-	var failedTeams []failedTeam
+	var changes []TeamChange
+	var failedTeams []FailedTeam
 
-	log.Printf("Processing %d teams from response", len(teamsResp.Response))
+	a.log.Debug("Processing teams", "path", path, "count", len(teamsResp.Response))
 
 	for _, t := range teamsResp.Response {
+		if err := ctx.Err(); err != nil {
+			return changes, failedTeams, err
+		}
 		if t.Country.ID == 0 || t.Country.Code == "" {
-			failedTeams = append(failedTeams, failedTeam{
+			failedTeams = append(failedTeams, FailedTeam{
 				Name:        t.Name,
 				CountryID:   t.Country.ID,
 				CountryName: t.Country.Name,
@@ -596,9 +623,9 @@ func (a *APIClient) fetchTeamsForCountry(store *db.Store, url string, updateImag
 			continue
 		}
 
-		mapping, err := store.GetAPIMappingByAPIID("api_sports", fmt.Sprintf("%d", t.Country.ID), "country")
+		mapping, err := store.GetAPIMappingByAPIID(ctx, "api_sports", fmt.Sprintf("%d", t.Country.ID), "country")
 		if err != nil {
-			failedTeams = append(failedTeams, failedTeam{
+			failedTeams = append(failedTeams, FailedTeam{
 				Name:        t.Name,
 				CountryID:   t.Country.ID,
 				CountryName: t.Country.Name,
@@ -609,22 +636,22 @@ func (a *APIClient) fetchTeamsForCountry(store *db.Store, url string, updateImag
 		}
 		countryCode := mapping.EntityID
 
-		country, err := store.GetCountryByCode(countryCode)
+		country, err := store.GetCountryByCode(ctx, countryCode)
 		if err != nil {
-			log.Printf("Error getting country %s: %v", countryCode, err)
+			a.log.Error("Error getting country", "country_code", countryCode, "error", err)
 			continue
 		}
 
-		log.Printf("Processing team: %s for country: %s", t.Name, countryCode)
+		a.log.Debug("Processing team", "team", t.Name, "country_code", countryCode)
 
 		teamID := fmt.Sprintf("%s-%s", countryCode, strings.ToUpper(strings.ReplaceAll(t.Name, " ", "")))
-		existing, err := store.GetTeamByID(teamID)
+		existing, err := store.GetTeamByID(ctx, teamID)
 		if err != nil && err != sql.ErrNoRows {
-			log.Printf("Error checking existing team %s: %v", t.Name, err)
+			a.log.Error("Error checking existing team", "team", t.Name, "error", err)
 			continue
 		}
 
-		change := teamChange{
+		change := TeamChange{
 			Name:    t.Name,
 			ID:      teamID,
 			Changes: make(map[string]interface{}),
@@ -634,11 +661,12 @@ func (a *APIClient) fetchTeamsForCountry(store *db.Store, url string, updateImag
 		logoURL := t.Logo
 		if existing == nil || (updateImages && existing.LogoSource == "api_sports" && existing.LogoURL != t.Logo) {
 			newLogoURL, err := a.downloadAndStoreImage(
+				ctx,
 				t.Logo,
 				fmt.Sprintf("logos/teams/%s/%s/logo.png", countryCode, strings.TrimPrefix(teamID, countryCode+"-")),
 			)
 			if err != nil {
-				log.Printf("Error downloading logo for team %s: %v", t.Name, err)
+				a.log.Error("Error downloading team logo", "team", t.Name, "error", err)
 			} else {
 				logoURL = newLogoURL
 				if existing != nil {
@@ -647,7 +675,7 @@ func (a *APIClient) fetchTeamsForCountry(store *db.Store, url string, updateImag
 			}
 		}
 
-		var stadiums []models.TeamStadium
+		var stadium *models.Stadium
 		if t.Arena.Name != "" {
 			cleanArenaName := strings.ToUpper(strings.ReplaceAll(
 				strings.ReplaceAll(
@@ -667,7 +695,7 @@ func (a *APIClient) fetchTeamsForCountry(store *db.Store, url string, updateImag
 				"'", "",
 			))
 
-			stadium := &models.Stadium{
+			stadium = &models.Stadium{
 				ID:       fmt.Sprintf("%s-%s-%s", countryCode, cleanLocation, cleanArenaName),
 				Name:     t.Arena.Name,
 				Location: location,
@@ -677,23 +705,16 @@ func (a *APIClient) fetchTeamsForCountry(store *db.Store, url string, updateImag
 			if cap, ok := t.Arena.Capacity.(float64); ok {
 				stadium.Capacity = int(cap)
 			}
-
-			if err := store.UpsertStadium(stadium); err != nil {
-				log.Printf("Error upserting stadium for team %s: %v", t.Name, err)
-			} else {
-				stadiums = append(stadiums, models.TeamStadium{
-					Stadium:   *stadium,
-					IsPrimary: true,
-				})
-			}
 		}
 
 		team := &models.Team{
-			ID:       teamID,
-			Name:     t.Name,
-			LogoURL:  logoURL,
-			Country:  *country,
-			Stadiums: stadiums,
+			ID:      teamID,
+			Name:    t.Name,
+			LogoURL: logoURL,
+			Country: *country,
+		}
+		if stadium != nil {
+			team.Stadiums = []models.TeamStadium{{Stadium: *stadium, IsPrimary: true}}
 		}
 
 		if existing != nil {
@@ -705,59 +726,93 @@ func (a *APIClient) fetchTeamsForCountry(store *db.Store, url string, updateImag
 			}
 		}
 
-		if err := store.UpsertTeam(team); err != nil {
-			failedTeams = append(failedTeams, failedTeam{
+		// The team, its stadium and its mapping are stored as one unit.
+		err = store.Unit(ctx, report, db.UnitTeam, fmt.Sprintf("%d", t.ID), func(tx *db.Store) error {
+			if stadium != nil {
+				if err := tx.UpsertStadium(ctx, stadium); err != nil {
+					return db.Step("stadium", err)
+				}
+			}
+			if err := tx.UpsertTeam(ctx, team); err != nil {
+				return db.Step("team", err)
+			}
+			for _, teamStadium := range team.Stadiums {
+				if err := tx.UpsertTeamStadium(ctx, team.ID, &teamStadium); err != nil {
+					return db.Step("team stadium", err)
+				}
+			}
+
+			teamMapping := &models.APIMapping{
+				EntityID:   team.ID,
+				APIName:    "api_sports",
+				APIID:      fmt.Sprintf("%d", t.ID),
+				EntityType: "team",
+			}
+			return db.Step("team mapping", tx.UpsertAPIMapping(ctx, teamMapping))
+		})
+		if err != nil {
+			failedTeams = append(failedTeams, FailedTeam{
 				Name:        t.Name,
 				CountryID:   t.Country.ID,
 				CountryName: t.Country.Name,
-				Reason:      fmt.Sprintf("Failed to upsert: %v", err),
+				Reason:      fmt.Sprintf("Failed to store: %v", err),
 				TeamData:    t,
 			})
 			continue
 		}
 
-		if len(team.Stadiums) > 0 {
-			for _, stadium := range team.Stadiums {
-				if err := store.UpsertTeamStadium(team.ID, &stadium); err != nil {
-					log.Printf("Error upserting team stadium relationship for team %s: %v", team.Name, err)
-				}
-			}
-		}
-
 		if change.IsNew {
-			log.Printf("Added new team: %s", t.Name)
+			a.log.Info("Added team", "team", t.Name)
 		} else if len(change.Changes) > 0 {
-			log.Printf("Updated team: %s with changes: %v", t.Name, change.Changes)
+			a.log.Info("Updated team", "team", t.Name, "changes", change.Changes)
 		}
+	}
 
-		teamMapping := &models.APIMapping{
-			EntityID:   team.ID,
-			APIName:    "api_sports",
-			APIID:      fmt.Sprintf("%d", t.ID),
-			EntityType: "team",
-		}
+	return changes, failedTeams, nil
+}
 
-		if err := store.UpsertAPIMapping(teamMapping); err != nil {
-			log.Printf("Error creating API mapping for team %s: %v", t.Name, err)
-		}
+// StoredLeagueMappings returns the API Sports league mappings already stored,
+// as MapAPISportsLeagues would report them, for when API Sports can't be
+// reached.
+func (a *APIClient) StoredLeagueMappings(ctx context.Context, store *db.Store) ([]LeagueMappingResult, error) {
+	mappings, err := store.GetAPIMappingsByType(ctx, "api_sports", "league")
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(mappings))
+	for i, m := range mappings {
+		ids[i] = m.EntityID
+	}
+	leagues, err := store.GetLeaguesByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(leagues))
+	for _, l := range leagues {
+		names[l.ID] = l.Name
 	}
 
-	return changes, failedTeams, nil
+	results := make([]LeagueMappingResult, 0, len(mappings))
+	for _, m := range mappings {
+		results = append(results, LeagueMappingResult{
+			APILeague:   APILeague{ID: m.APIID},
+			Matched:     true,
+			MatchedName: names[m.EntityID],
+			InternalID:  m.EntityID,
+			Reason:      "stored_mapping",
+		})
+	}
+	return results, nil
 }
 
 // Add new function to fetch and map leagues
-func (a *APIClient) MapAPISportsLeagues(store *db.Store) ([]LeagueMappingResult, error) {
-	url := "https://v1.rugby.api-sports.io/leagues"
-
-	req, err := http.NewRequest("GET", url, nil)
+func (a *APIClient) MapAPISportsLeagues(ctx context.Context, store *db.Store) ([]LeagueMappingResult, error) {
+	req, err := a.apiSportsRequest(ctx, "/leagues")
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("x-rapidapi-key", os.Getenv("API_SPORTS_KEY"))
-	req.Header.Add("x-rapidapi-host", "v1.rugby.api-sports.io")
-
-	resp, err := a.client.Do(req)
+	resp, err := a.apiSports.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -777,7 +832,11 @@ func (a *APIClient) MapAPISportsLeagues(store *db.Store) ([]LeagueMappingResult,
 	}
 
 	var results []LeagueMappingResult
+	matched := 0
 	for _, league := range apiResp.Response {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		result := LeagueMappingResult{
 			APILeague: APILeague{
 				ID:   strconv.Itoa(league.ID),
@@ -831,24 +890,30 @@ func (a *APIClient) MapAPISportsLeagues(store *db.Store) ([]LeagueMappingResult,
 						EntityID:   result.InternalID,
 					}
 
-					if err := store.UpsertAPIMapping(mapping); err != nil {
-						log.Printf("Warning: failed to create mapping for league %s: %v", result.InternalID, err)
+					if err := store.UpsertAPIMapping(ctx, mapping); err != nil {
+						a.log.Warn("Failed to create mapping for league", "league_id", result.InternalID, "error", err)
 					}
 				}
 			}
 		}
 
 		results = append(results, result)
+		if result.Matched {
+			matched++
+		}
 	}
+	metrics.ObserveMapping("api_sports_leagues", matched, len(results))
 
 	return results, nil
 }
 
-func (a *APIClient) GetMatchesByLeague(leagueID string, date string, season string, apiParams APIParams, store *db.Store) ([]Match, []*DailyMatches, error) {
+// GetMatchesByLeague fetches a league's games from API Sports and stores each
+// with its mapping as a unit of work, recorded in report.
+func (a *APIClient) GetMatchesByLeague(ctx context.Context, leagueID string, date string, season string, apiParams APIParams, store *db.Store, report *db.IngestionReport) ([]Match, []*DailyMatches, error) {
 	// Get API Sports league ID from our internal ID if not provided
 	apiLeagueID := apiParams.LeagueID
 	if apiLeagueID == "" {
-		mapping, err := store.GetAPIMappingByEntityID("api_sports", leagueID, "league")
+		mapping, err := store.GetAPIMappingByEntityID(ctx, "api_sports", leagueID, "league")
 		if err != nil || mapping == nil {
 			return nil, nil, fmt.Errorf("league not found in API Sports mappings")
 		}
@@ -856,7 +921,7 @@ func (a *APIClient) GetMatchesByLeague(leagueID string, date string, season stri
 	}
 
 	// Get season from database
-	dbSeason, err := store.GetSeasonByLeagueAndYear(leagueID, season)
+	dbSeason, err := store.GetSeasonByLeagueAndYear(ctx, leagueID, season)
 	if err != nil {
 		return nil, nil, fmt.Errorf("season not found: %v", err)
 	}
@@ -874,32 +939,32 @@ func (a *APIClient) GetMatchesByLeague(leagueID string, date string, season stri
 	if apiParams.Date != "" {
 		params = append(params, fmt.Sprintf("date=%s", apiParams.Date))
 	}
+	// Ask API Sports to resolve dates in the caller's timezone
+	if apiParams.Timezone != "" {
+		params = append(params, fmt.Sprintf("timezone=%s", apiParams.Timezone))
+	}
 
-	url := fmt.Sprintf("https://v1.rugby.api-sports.io/games?%s", strings.Join(params, "&"))
+	path := fmt.Sprintf("/games?%s", strings.Join(params, "&"))
 
-	log.Printf("Calling API Sports URL: %s", url)
+	a.log.Debug("Calling API Sports", "path", path)
 
 	// Reuse existing API response struct and processing logic
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := a.apiSportsRequest(ctx, path)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	req.Header.Add("x-rapidapi-key", os.Getenv("API_SPORTS_KEY"))
-	req.Header.Add("x-rapidapi-host", "v1.rugby.api-sports.io")
-
-	resp, err := a.client.Do(req)
+	resp, err := a.apiSports.Do(req)
 	if err != nil {
 		return nil, nil, err
 	}
 	defer resp.Body.Close()
 
-	// Read and log the response body
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, nil, err
 	}
-	log.Printf("API Sports response: %s", string(respBody))
+	a.log.Debug("API Sports responded", "status", resp.StatusCode, "bytes", len(respBody))
 
 	// Create a new reader with the response body for json.Decode
 	resp.Body = io.NopCloser(bytes.NewBuffer(respBody))
@@ -907,10 +972,12 @@ func (a *APIClient) GetMatchesByLeague(leagueID string, date string, season stri
 	// Use the same response processing as GetMatchesByDate
 	var apiResp struct {
 		Response []struct {
-			ID     int    `json:"id"`
-			Date   string `json:"date"`
-			Status struct {
-				Long string `json:"long"`
+			ID       int    `json:"id"`
+			Date     string `json:"date"`
+			Timezone string `json:"timezone"`
+			Status   struct {
+				Long  string `json:"long"`
+				Short string `json:"short"`
 			} `json:"status"`
 			League struct {
 				ID   int    `json:"id"`
@@ -928,6 +995,9 @@ func (a *APIClient) GetMatchesByLeague(leagueID string, date string, season stri
 					Logo string `json:"logo"`
 				} `json:"away"`
 			} `json:"teams"`
+			Country struct {
+				Code string `json:"code"`
+			} `json:"country"`
 			Scores struct {
 				Home int `json:"home"`
 				Away int `json:"away"`
@@ -939,28 +1009,31 @@ func (a *APIClient) GetMatchesByLeague(leagueID string, date string, season stri
 		return nil, nil, err
 	}
 
+	loc, err := kickoff.LoadLocation(apiParams.Timezone)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Process matches using same logic as GetMatchesByDate
 	var matches []Match
+	var apiIDs []string // API Sports game ID of each match
 	for _, m := range apiResp.Response {
 		// Get team mappings
-		homeTeamMapping, err := store.GetAPIMappingByAPIID("api_sports", strconv.Itoa(m.Teams.Home.ID), "team")
+		homeTeamMapping, err := store.GetAPIMappingByAPIID(ctx, "api_sports", strconv.Itoa(m.Teams.Home.ID), "team")
 		if err != nil || homeTeamMapping == nil {
 			continue
 		}
 
-		awayTeamMapping, err := store.GetAPIMappingByAPIID("api_sports", strconv.Itoa(m.Teams.Away.ID), "team")
+		awayTeamMapping, err := store.GetAPIMappingByAPIID(ctx, "api_sports", strconv.Itoa(m.Teams.Away.ID), "team")
 		if err != nil || awayTeamMapping == nil {
 			continue
 		}
 
-		status := "upcoming"
-		if m.Status.Long == "Finished" {
-			status = "finished"
-		} else if m.Status.Long == "In Play" {
-			status = "live"
+		kickOff, err := kickoff.Parse(m.Date, m.Timezone)
+		if err != nil {
+			a.log.Warn("Skipping API Sports game", "game_id", m.ID, "error", err)
+			continue
 		}
-
-		kickOff, _ := time.Parse("2006-01-02T15:04:05-07:00", m.Date)
 		matchID := fmt.Sprintf("%s-%s-%s-%s",
 			dbSeason.ID,
 			homeTeamMapping.EntityID,
@@ -969,78 +1042,142 @@ func (a *APIClient) GetMatchesByLeague(leagueID string, date string, season stri
 		)
 
 		match := Match{
-			ID:         matchID,
-			HomeTeamID: homeTeamMapping.EntityID,
-			AwayTeamID: awayTeamMapping.EntityID,
-			LeagueID:   dbSeason.ID,
-			HomeScore:  m.Scores.Home,
-			AwayScore:  m.Scores.Away,
-			Status:     status,
-			KickOff:    kickOff,
-			Date:       kickOff.Format("2006-01-02"),
-			Time:       kickOff.Format("15:04"),
+			ID:            matchID,
+			HomeTeamID:    homeTeamMapping.EntityID,
+			AwayTeamID:    awayTeamMapping.EntityID,
+			LeagueID:      dbSeason.ID,
+			HomeScore:     m.Scores.Home,
+			AwayScore:     m.Scores.Away,
+			Status:        apiSportsStatus(m.Status.Short, m.Status.Long),
+			KickOff:       kickOff,
+			Date:          kickOff.Format("2006-01-02"),
+			Time:          kickOff.Format("15:04"),
+			VenueTimezone: kickoff.VenueTimezone(m.Country.Code, m.Date),
 		}
 		matches = append(matches, match)
+		apiIDs = append(apiIDs, strconv.Itoa(m.ID))
+	}
 
-		// Create API mapping for match
-		matchMapping := &models.APIMapping{
-			EntityID:   matchID,
-			APIName:    "api_sports",
-			APIID:      strconv.Itoa(m.ID),
-			EntityType: "match",
+	// Store each match with its mapping as one unit, so a failure leaves no
+	// mapping pointing at a match that was never written.
+	var stored []Match
+	for i, match := range matches {
+		dbMatch := &models.Match{
+			ID:            match.ID,
+			HomeTeamID:    match.HomeTeamID,
+			AwayTeamID:    match.AwayTeamID,
+			LeagueID:      match.LeagueID,
+			HomeScore:     match.HomeScore,
+			AwayScore:     match.AwayScore,
+			Status:        match.Status,
+			KickOff:       match.KickOff,
+			Date:          match.Date,
+			Time:          match.Time,
+			VenueTimezone: match.VenueTimezone,
 		}
-		if err := store.UpsertAPIMapping(matchMapping); err != nil {
-			log.Printf("Error creating API mapping for match %s: %v", matchID, err)
+		err := store.Unit(ctx, report, db.UnitMatch, apiIDs[i], func(tx *db.Store) error {
+			if err := reconcile.Record(ctx, tx, reconcile.SourceAPISports, dbMatch); err != nil {
+				return db.Step("match", err)
+			}
+			return db.Step("match mapping", tx.UpsertAPIMapping(ctx, &models.APIMapping{
+				EntityID:   match.ID,
+				APIName:    "api_sports",
+				APIID:      apiIDs[i],
+				EntityType: "match",
+			}))
+		})
+		if err == nil {
+			stored = append(stored, match)
 		}
 	}
 
-	// Create daily matches summary if date is provided
-	var dailyMatchesList []*DailyMatches
-
-	// Group matches by date
-	matchesByDate := make(map[string][]string)
-	for _, m := range matches {
-		matchesByDate[m.Date] = append(matchesByDate[m.Date], m.ID)
+	// The daily_matches index is keyed by UTC day, independent of the caller
+	utcMatchesByDate := make(map[string][]string)
+	for _, m := range stored {
+		utcMatchesByDate[m.Date] = append(utcMatchesByDate[m.Date], m.ID)
+	}
+	for date, matchIDs := range utcMatchesByDate {
+		if err := store.UpsertDailyMatches(ctx, date, matchIDs); err != nil {
+			a.log.Error("Error upserting daily matches", "date", date, "error", err)
+		}
 	}
 
-	// Create daily matches entries
-	for date, matchIDs := range matchesByDate {
-		// Insert matches
-		for _, match := range matches {
-			dbMatch := &models.Match{
-				ID:         match.ID,
-				HomeTeamID: match.HomeTeamID,
-				AwayTeamID: match.AwayTeamID,
-				LeagueID:   match.LeagueID,
-				HomeScore:  match.HomeScore,
-				AwayScore:  match.AwayScore,
-				Status:     match.Status,
-				KickOff:    match.KickOff,
-				Date:       match.Date,
-				Time:       match.Time,
-			}
-			if err := store.UpsertMatch(dbMatch); err != nil {
-				log.Printf("Error upserting match %s: %v", match.ID, err)
-			}
+	return matches, localDailyMatches(matches, loc, apiParams.Date), nil
+}
+
+// StoredMatchesByLeague answers GetMatchesByLeague from the database, for
+// when API Sports can't be reached: the season's stored matches, limited to
+// apiParams.Date in the caller's timezone when it is set.
+func (a *APIClient) StoredMatchesByLeague(ctx context.Context, leagueID string, season string, apiParams APIParams, store *db.Store) ([]Match, []*DailyMatches, error) {
+	dbSeason, err := store.GetSeasonByLeagueAndYear(ctx, leagueID, season)
+	if err != nil {
+		return nil, nil, fmt.Errorf("season not found: %v", err)
+	}
+	loc, err := kickoff.LoadLocation(apiParams.Timezone)
+	if err != nil {
+		return nil, nil, err
+	}
+	var from, to time.Time
+	if apiParams.Date != "" {
+		if from, to, err = kickoff.DayBounds(apiParams.Date, loc); err != nil {
+			return nil, nil, err
 		}
+	}
 
-		// Insert daily matches
-		if err := store.UpsertDailyMatches(date, matchIDs); err != nil {
-			log.Printf("Error upserting daily matches for date %s: %v", date, err)
+	stored, err := store.GetMatchesBySeasonIDs(ctx, []string{dbSeason.ID})
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(stored, func(i, j int) bool { return stored[i].KickOff.Before(stored[j].KickOff) })
+	var matches []Match
+	for _, m := range stored {
+		if apiParams.Date != "" && (m.KickOff.Before(from) || !m.KickOff.Before(to)) {
+			continue
 		}
+		matches = append(matches, Match{
+			ID:            m.ID,
+			HomeTeamID:    m.HomeTeamID,
+			AwayTeamID:    m.AwayTeamID,
+			LeagueID:      m.LeagueID,
+			HomeScore:     m.HomeScore,
+			AwayScore:     m.AwayScore,
+			Status:        m.Status,
+			KickOff:       m.KickOff,
+			VenueTimezone: m.VenueTimezone,
+			Venue:         m.Venue,
+		})
+	}
+	return matches, localDailyMatches(matches, loc, apiParams.Date), nil
+}
 
+// localDailyMatches renders matches' dates and times in loc and groups them
+// by local date. An empty group is returned for date when nothing matched.
+func localDailyMatches(matches []Match, loc *time.Location, date string) []*DailyMatches {
+	var dailyMatchesList []*DailyMatches
+	matchesByDate := make(map[string][]string)
+	var dates []string
+	for i := range matches {
+		local := matches[i].KickOff.In(loc)
+		matches[i].Date = local.Format("2006-01-02")
+		matches[i].Time = local.Format("15:04")
+		if _, seen := matchesByDate[matches[i].Date]; !seen {
+			dates = append(dates, matches[i].Date)
+		}
+		matchesByDate[matches[i].Date] = append(matchesByDate[matches[i].Date], matches[i].ID)
+	}
+	sort.Strings(dates)
+	for _, d := range dates {
 		dailyMatchesList = append(dailyMatchesList, &DailyMatches{
-			Date:     date,
-			MatchIDs: matchIDs,
+			Date:     d,
+			MatchIDs: matchesByDate[d],
 		})
 	}
 	// If no matches but date provided, add empty entry
-	if len(dailyMatchesList) == 0 && apiParams.Date != "" {
+	if len(dailyMatchesList) == 0 && date != "" {
 		dailyMatchesList = append(dailyMatchesList, &DailyMatches{
-			Date:     apiParams.Date,
+			Date:     date,
 			MatchIDs: []string{},
 		})
 	}
-
-	return matches, dailyMatchesList, nil
+	return dailyMatchesList
 }
@@ -0,0 +1,201 @@
+// Package breaker stops requests to an upstream host that keeps failing. Each
+// host has one circuit, shared by every client that talks to it: after
+// Threshold failures in a row it opens and requests fail at once with
+// ErrOpen. Once Cooldown has passed a single probe is let through; the
+// circuit closes if it succeeds and opens again if it fails.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"rugby-live-api/metrics"
+	"sync"
+	"time"
+)
+
+// State is a circuit's state.
+type State int
+
+const (
+	Closed State = iota
+	HalfOpen
+	Open
+)
+
+func (s State) String() string {
+	switch s {
+	case HalfOpen:
+		return "half_open"
+	case Open:
+		return "open"
+	}
+	return "closed"
+}
+
+// Settings tune a host's circuit.
+type Settings struct {
+	// Threshold is how many failures in a row open the circuit.
+	Threshold int
+	// Cooldown is how long the circuit stays open before a probe.
+	Cooldown time.Duration
+}
+
+// ErrOpen is returned, wrapped in an *OpenError, for requests refused while
+// their host's circuit is open.
+var ErrOpen = errors.New("circuit open")
+
+// OpenError reports a request refused by Host's open circuit.
+type OpenError struct {
+	Host string
+	// RetryAfter is how long until the circuit lets a probe through.
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	if e.RetryAfter <= 0 {
+		return fmt.Sprintf("%s: %v; probing", e.Host, ErrOpen)
+	}
+	return fmt.Sprintf("%s: %v; retry in %s", e.Host, ErrOpen, e.RetryAfter.Round(time.Millisecond))
+}
+
+func (e *OpenError) Unwrap() error { return ErrOpen }
+
+type circuit struct {
+	host     string
+	settings Settings
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+var (
+	mu       sync.Mutex
+	circuits = make(map[string]*circuit)
+)
+
+// get returns host's circuit, creating it with settings if it is new. Clients
+// sharing a host share the settings of whichever reached it first.
+func get(host string, settings Settings) *circuit {
+	mu.Lock()
+	defer mu.Unlock()
+	c, ok := circuits[host]
+	if !ok {
+		c = &circuit{host: host, settings: settings}
+		circuits[host] = c
+		metrics.SetCircuitState(host, int(Closed))
+	}
+	return c
+}
+
+// States returns the state of every host's circuit seen so far, by host.
+func States() map[string]State {
+	mu.Lock()
+	defer mu.Unlock()
+	states := make(map[string]State, len(circuits))
+	for host, c := range circuits {
+		c.mu.Lock()
+		states[host] = c.state
+		c.mu.Unlock()
+	}
+	return states
+}
+
+// allow reports whether a request may go out now, and whether it is the
+// half-open probe.
+func (c *circuit) allow(now time.Time) (probe bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch c.state {
+	case Closed:
+		return false, nil
+	case Open:
+		if wait := c.settings.Cooldown - now.Sub(c.openedAt); wait > 0 {
+			return false, &OpenError{Host: c.host, RetryAfter: wait}
+		}
+		c.set(HalfOpen)
+	}
+	if c.probing {
+		return false, &OpenError{Host: c.host}
+	}
+	c.probing = true
+	return true, nil
+}
+
+// outcome is what a request said of its host's health.
+type outcome int
+
+const (
+	succeeded outcome = iota
+	failed
+	// unknown is for requests the caller gave up on.
+	unknown
+)
+
+// done records the outcome of a request allow let through.
+func (c *circuit) done(probe bool, result outcome, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if probe {
+		c.probing = false
+	}
+	switch {
+	case result == unknown:
+	case result == succeeded:
+		c.failures = 0
+		if c.state != Closed {
+			c.set(Closed)
+		}
+	case probe || c.state == Closed:
+		// Failures of requests sent before the circuit opened are old news.
+		c.failures++
+		if probe || c.failures >= c.settings.Threshold {
+			c.openedAt = now
+			c.set(Open)
+		}
+	}
+}
+
+// set moves the circuit to state. The caller holds c.mu.
+func (c *circuit) set(state State) {
+	c.state = state
+	metrics.SetCircuitState(c.host, int(state))
+}
+
+// Transport returns a RoundTripper that sends requests through next while
+// their host's circuit allows. A transport error or a 5xx response counts as
+// a failure; a request the caller cancelled counts as neither.
+func Transport(next http.RoundTripper, settings Settings) http.RoundTripper {
+	if settings.Threshold < 1 {
+		settings.Threshold = 1
+	}
+	return &transport{next: next, settings: settings}
+}
+
+type transport struct {
+	next     http.RoundTripper
+	settings Settings
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c := get(req.URL.Host, t.settings)
+	probe, err := c.allow(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	result := succeeded
+	switch {
+	case errors.Is(req.Context().Err(), context.Canceled):
+		result = unknown
+	case err != nil || resp.StatusCode >= http.StatusInternalServerError:
+		result = failed
+	}
+	c.done(probe, result, time.Now())
+	return resp, err
+}
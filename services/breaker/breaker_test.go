@@ -0,0 +1,193 @@
+package breaker
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuit(t *testing.T) {
+	// A step sends a request at a time, in seconds from the start, and
+	// reports its outcome unless it is refused.
+	type step struct {
+		at      int
+		result  outcome
+		refused bool
+		want    State // After the step
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"failures below threshold", []step{
+			{at: 0, result: failed, want: Closed},
+			{at: 1, result: failed, want: Closed},
+			{at: 2, result: succeeded, want: Closed},
+			{at: 3, result: failed, want: Closed},
+			{at: 4, result: failed, want: Closed},
+		}},
+		{"opens at threshold", []step{
+			{at: 0, result: failed, want: Closed},
+			{at: 1, result: failed, want: Closed},
+			{at: 2, result: failed, want: Open},
+			{at: 3, refused: true, want: Open},
+			{at: 11, refused: true, want: Open},
+		}},
+		{"probe closes", []step{
+			{at: 0, result: failed}, {at: 0, result: failed}, {at: 0, result: failed, want: Open},
+			{at: 10, result: succeeded, want: Closed},
+			{at: 11, result: failed, want: Closed},
+		}},
+		{"probe reopens", []step{
+			{at: 0, result: failed}, {at: 0, result: failed}, {at: 0, result: failed, want: Open},
+			{at: 10, result: failed, want: Open},
+			{at: 15, refused: true, want: Open},
+			{at: 20, result: succeeded, want: Closed},
+		}},
+		{"cancelled probe", []step{
+			{at: 0, result: failed}, {at: 0, result: failed}, {at: 0, result: failed, want: Open},
+			{at: 10, result: unknown, want: HalfOpen},
+			{at: 11, result: succeeded, want: Closed},
+		}},
+		{"cancelled requests don't count", []step{
+			{at: 0, result: failed}, {at: 0, result: failed, want: Closed},
+			{at: 1, result: unknown, want: Closed},
+			{at: 2, result: unknown, want: Closed},
+			{at: 3, result: failed, want: Open},
+		}},
+	}
+	start := time.Date(2025, 3, 1, 15, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		c := &circuit{host: "test-" + tt.name, settings: Settings{Threshold: 3, Cooldown: 10 * time.Second}}
+		for i, s := range tt.steps {
+			now := start.Add(time.Duration(s.at) * time.Second)
+			probe, err := c.allow(now)
+			if refused := err != nil; refused != s.refused {
+				t.Fatalf("%s: step %d: allow error = %v, want refused %v", tt.name, i, err, s.refused)
+			}
+			if err == nil {
+				c.done(probe, s.result, now)
+			} else if !errors.Is(err, ErrOpen) {
+				t.Fatalf("%s: step %d: allow error = %v, want ErrOpen", tt.name, i, err)
+			}
+			if c.state != s.want {
+				t.Fatalf("%s: step %d: state %s, want %s", tt.name, i, c.state, s.want)
+			}
+		}
+	}
+}
+
+// Only one probe goes out while half open.
+func TestCircuitSingleProbe(t *testing.T) {
+	c := &circuit{host: "test-single-probe", settings: Settings{Threshold: 1, Cooldown: time.Second}}
+	now := time.Now()
+	c.done(false, failed, now)
+
+	later := now.Add(time.Second)
+	probe, err := c.allow(later)
+	if err != nil || !probe {
+		t.Fatalf("first allow after cooldown = %v, %v, want a probe", probe, err)
+	}
+	var openErr *OpenError
+	if _, err := c.allow(later); !errors.As(err, &openErr) || openErr.RetryAfter != 0 {
+		t.Fatalf("second allow while probing = %v, want an OpenError with no wait", err)
+	}
+	c.done(probe, succeeded, later)
+	if _, err := c.allow(later); err != nil {
+		t.Fatalf("allow after the probe succeeded = %v", err)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: Transport(server.Client().Transport, Settings{Threshold: 2, Cooldown: time.Hour})}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		resp.Body.Close()
+	}
+	status = http.StatusOK
+	if _, err := client.Get(server.URL); !errors.Is(err, ErrOpen) {
+		t.Fatalf("request after two 503s = %v, want ErrOpen", err)
+	}
+}
+
+// Of many requests racing to a half-open circuit, one goes out as the probe
+// and the rest are refused until it returns.
+func TestTransportHalfOpenConcurrency(t *testing.T) {
+	const requests = 20
+	var failing atomic.Bool
+	var probes atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		probes.Add(1)
+		<-release
+	}))
+	defer server.Close()
+
+	cooldown := 50 * time.Millisecond
+	client := &http.Client{Transport: Transport(server.Client().Transport, Settings{Threshold: 1, Cooldown: cooldown})}
+	failing.Store(true)
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	failing.Store(false)
+	time.Sleep(cooldown)
+
+	start := make(chan struct{})
+	results := make(chan error, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			resp, err := client.Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			results <- err
+		}()
+	}
+	close(start)
+
+	// The probe is held by the server, so every other request has to come
+	// back refused before it is let go.
+	for i := 0; i < requests-1; i++ {
+		var openErr *OpenError
+		if err := <-results; !errors.As(err, &openErr) {
+			t.Fatalf("request while probing = %v, want an OpenError", err)
+		}
+	}
+	close(release)
+	if err := <-results; err != nil {
+		t.Fatalf("probe = %v", err)
+	}
+	wg.Wait()
+	if got := probes.Load(); got != 1 {
+		t.Errorf("server saw %d probes, want 1", got)
+	}
+
+	resp, err = client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request after the probe succeeded = %v", err)
+	}
+	resp.Body.Close()
+}
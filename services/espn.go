@@ -1,16 +1,44 @@
 package services
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/gocolly/colly"
 )
 
-func (a *APIClient) ScrapeESPNTeam(teamURL string) (*ESPNTeamInfo, error) {
+// espnCollector returns a collector for scraping ESPN with the configured
+// timeout, whose requests are cancelled along with ctx.
+func (a *APIClient) espnCollector(ctx context.Context) (*colly.Collector, error) {
+	if err := a.providers.ESPN.Require("espn"); err != nil {
+		return nil, err
+	}
 	c := colly.NewCollector(
 		colly.UserAgent("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"),
 	)
+	c.WithTransport(contextTransport{ctx: ctx, next: a.espn})
+	c.SetRequestTimeout(a.providers.ESPN.Timeout)
+	return c, nil
+}
+
+// contextTransport sends requests under ctx, for clients such as colly that
+// build their own requests without one.
+type contextTransport struct {
+	ctx  context.Context
+	next http.RoundTripper
+}
+
+func (t contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.next.RoundTrip(req.WithContext(t.ctx))
+}
+
+func (a *APIClient) ScrapeESPNTeam(ctx context.Context, teamURL string) (*ESPNTeamInfo, error) {
+	c, err := a.espnCollector(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	teamInfo := &ESPNTeamInfo{
 		RawData: make(map[string]interface{}),
@@ -60,18 +88,18 @@ func (a *APIClient) ScrapeESPNTeam(teamURL string) (*ESPNTeamInfo, error) {
 		teamInfo.Players = append(teamInfo.Players, player)
 	})
 
-	err := c.Visit(teamURL)
-	if err != nil {
+	if err := c.Visit(teamURL); err != nil {
 		return nil, fmt.Errorf("failed to scrape ESPN team page: %v", err)
 	}
 
 	return teamInfo, nil
 }
 
-func (a *APIClient) ScrapeESPNLeagues() ([]ESPNLeague, error) {
-	c := colly.NewCollector(
-		colly.UserAgent("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"),
-	)
+func (a *APIClient) ScrapeESPNLeagues(ctx context.Context) ([]ESPNLeague, error) {
+	c, err := a.espnCollector(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	var leagues []ESPNLeague
 	c.OnHTML(".dropdown-menu.med li a", func(e *colly.HTMLElement) {
@@ -87,13 +115,12 @@ func (a *APIClient) ScrapeESPNLeagues() ([]ESPNLeague, error) {
 		leagues = append(leagues, ESPNLeague{
 			ID:   leagueID,
 			Name: e.Text,
-			URL:  "https://www.espn.com" + href,
+			URL:  a.providers.ESPN.BaseURL + href,
 		})
 	})
 
-	err := c.Visit("https://www.espn.com/rugby/standings")
-	if err != nil {
-		return nil, fmt.Errorf("failed to scrape ESPN leagues: %v", err)
+	if err := c.Visit(a.providers.ESPN.BaseURL + "/rugby/standings"); err != nil {
+		return nil, fmt.Errorf("failed to scrape ESPN leagues: %w", err)
 	}
 
 	return leagues, nil
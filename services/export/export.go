@@ -0,0 +1,178 @@
+// Package export streams reference data and results out of the database as
+// CSV, JSON Lines or Parquet, to any io.Writer or to the object store.
+package export
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"rugby-live-api/db"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrUnknownTable  = errors.New("unknown table")
+	ErrUnknownFormat = errors.New("unknown format")
+)
+
+type Format string
+
+const (
+	CSV     Format = "csv"
+	JSONL   Format = "jsonl"
+	Parquet Format = "parquet"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case CSV, JSONL, Parquet:
+		return f, nil
+	case "":
+		return CSV, nil
+	}
+	return "", fmt.Errorf("%w %q; expected csv, jsonl or parquet", ErrUnknownFormat, s)
+}
+
+func (f Format) Extension() string {
+	return string(f)
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case JSONL:
+		return "application/x-ndjson"
+	case Parquet:
+		return "application/vnd.apache.parquet"
+	}
+	return "text/csv"
+}
+
+// Write streams table to w in format and returns the number of rows written.
+func Write(ctx context.Context, store *db.Store, table Table, format Format, filter Filter, w io.Writer) (int, error) {
+	out, err := newRowWriter(format, table.Columns, w)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := store.DB.QueryContext(ctx, table.query, filter.SeasonID, filter.LeagueID)
+	if err != nil {
+		return 0, fmt.Errorf("querying %s: %v", table.Name, err)
+	}
+	defer rows.Close()
+
+	dest, read := scanTargets(table.Columns)
+	count := 0
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return count, fmt.Errorf("scanning %s: %v", table.Name, err)
+		}
+		if err := out.WriteRow(read()); err != nil {
+			return count, fmt.Errorf("writing %s: %v", table.Name, err)
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+	return count, out.Close()
+}
+
+// scanTargets returns scan destinations for columns and a function that reads
+// the current row back as plain values: string, int64, bool, float64,
+// time.Time or []string, with nil for NULL.
+func scanTargets(columns []Column) ([]interface{}, func() []interface{}) {
+	dest := make([]interface{}, len(columns))
+	for i, col := range columns {
+		switch col.Kind {
+		case Int:
+			dest[i] = new(sql.NullInt64)
+		case Bool:
+			dest[i] = new(sql.NullBool)
+		case Float:
+			dest[i] = new(sql.NullFloat64)
+		case Time:
+			dest[i] = new(sql.NullTime)
+		case StringList:
+			dest[i] = new(pq.StringArray)
+		default:
+			dest[i] = new(sql.NullString)
+		}
+	}
+
+	read := func() []interface{} {
+		values := make([]interface{}, len(dest))
+		for i, d := range dest {
+			switch v := d.(type) {
+			case *sql.NullString:
+				if v.Valid {
+					values[i] = v.String
+				}
+			case *sql.NullInt64:
+				if v.Valid {
+					values[i] = v.Int64
+				}
+			case *sql.NullBool:
+				if v.Valid {
+					values[i] = v.Bool
+				}
+			case *sql.NullFloat64:
+				if v.Valid {
+					values[i] = v.Float64
+				}
+			case *sql.NullTime:
+				if v.Valid {
+					values[i] = v.Time.UTC()
+				}
+			case *pq.StringArray:
+				if *v != nil {
+					values[i] = []string(*v)
+				}
+			}
+		}
+		return values
+	}
+	return dest, read
+}
+
+// Uploader stores an object and returns its public URL.
+type Uploader interface {
+	UploadObject(ctx context.Context, path string, contentType string, body io.Reader) (string, error)
+}
+
+// Result records one table written to the object store.
+type Result struct {
+	Table string `json:"table"`
+	Rows  int    `json:"rows"`
+	URL   string `json:"url"`
+}
+
+// ToStorage writes each table to the object store under prefix, streaming
+// rows straight into the upload.
+func ToStorage(ctx context.Context, store *db.Store, uploader Uploader, tables []Table, format Format, filter Filter, prefix string) ([]Result, error) {
+	var results []Result
+	for _, table := range tables {
+		path := fmt.Sprintf("%s/%s.%s", strings.TrimSuffix(prefix, "/"), table.Name, format.Extension())
+
+		pr, pw := io.Pipe()
+		done := make(chan int, 1)
+		go func() {
+			count, err := Write(ctx, store, table, format, filter, pw)
+			pw.CloseWithError(err)
+			done <- count
+		}()
+
+		url, err := uploader.UploadObject(ctx, path, format.ContentType(), pr)
+		// Unblock the writer if the upload gave up before reading everything.
+		pr.CloseWithError(err)
+		count := <-done
+		if err != nil {
+			return results, fmt.Errorf("uploading %s: %v", table.Name, err)
+		}
+		results = append(results, Result{Table: table.Name, Rows: count, URL: url})
+	}
+	return results, nil
+}
@@ -0,0 +1,206 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind is the type of an exported column.
+type Kind int
+
+const (
+	String Kind = iota
+	Int
+	Bool
+	Float
+	Time
+	StringList
+)
+
+type Column struct {
+	Name string
+	Kind Kind
+}
+
+// Table is an exportable table. Its query takes the season filter as $1 and
+// the league filter as $2, each empty when unset, and selects Columns in
+// order.
+type Table struct {
+	Name    string
+	Columns []Column
+	query   string
+}
+
+// Filter scopes an export to one season or league. Tables without a direct
+// link follow the matches in scope: teams that played them, those teams'
+// stadiums and countries, and the API mappings of every exported entity.
+type Filter struct {
+	SeasonID string
+	LeagueID string
+}
+
+const unfiltered = `($1::text = '' AND $2::text = '')`
+
+// matches.league_id holds a season ID for scraped fixtures and a league ID
+// for API Sports ones, so a league filter accepts either.
+const matchScope = `
+        ($1::text = '' OR m.league_id = $1)
+        AND ($2::text = '' OR m.league_id = $2 OR m.league_id IN (SELECT id FROM seasons WHERE league_id = $2))`
+
+const leagueScope = `
+        ($2::text = '' OR l.id = $2 OR l.parent_league_id = $2)
+        AND ($1::text = '' OR l.id IN (SELECT league_id FROM seasons WHERE id = $1))`
+
+const seasonScope = `
+        ($1::text = '' OR s.id = $1) AND ($2::text = '' OR s.league_id = $2)`
+
+var teamScope = unfiltered + ` OR t.id IN (
+        SELECT m.home_team_id FROM matches m WHERE ` + matchScope + `
+        UNION
+        SELECT m.away_team_id FROM matches m WHERE ` + matchScope + `)`
+
+var Tables = []Table{
+	{
+		Name: "countries",
+		Columns: []Column{
+			{"code", String}, {"name", String}, {"flag", String},
+			{"created_at", Time}, {"updated_at", Time},
+		},
+		query: `
+        SELECT c.code, c.name, c.flag, c.created_at, c.updated_at
+        FROM countries c
+        WHERE ` + unfiltered + ` OR c.code IN (
+            SELECT l.country_code FROM leagues l WHERE ` + leagueScope + `
+            UNION
+            SELECT t.country_code FROM teams t WHERE ` + teamScope + `)
+        ORDER BY c.code`,
+	},
+	{
+		Name: "leagues",
+		Columns: []Column{
+			{"id", String}, {"name", String}, {"country_code", String}, {"tier", Int},
+			{"format", String}, {"phases", StringList}, {"alt_names", StringList},
+			{"logo_url", String}, {"team_countries", StringList}, {"gender", String},
+			{"international", Bool}, {"parent_league_id", String}, {"successor_league_id", String},
+			{"all_time", Bool}, {"all_time_league_id", String},
+			{"created_at", Time}, {"updated_at", Time},
+		},
+		query: `
+        SELECT l.id, l.name, l.country_code, l.tier, l.format, l.phases, l.alt_names,
+               l.logo_url, l.team_countries, l.gender, l.international, l.parent_league_id,
+               l.successor_league_id, l.all_time, l.all_time_league_id, l.created_at, l.updated_at
+        FROM leagues l
+        WHERE ` + leagueScope + `
+        ORDER BY l.id`,
+	},
+	{
+		Name: "seasons",
+		Columns: []Column{
+			{"id", String}, {"league_id", String}, {"year", Int}, {"year_range", String},
+			{"current", Bool}, {"start_date", Time}, {"end_date", Time},
+			{"created_at", Time}, {"updated_at", Time},
+		},
+		query: `
+        SELECT s.id, s.league_id, s.year, s.year_range, s.current, s.start_date, s.end_date,
+               s.created_at, s.updated_at
+        FROM seasons s
+        WHERE ` + seasonScope + `
+        ORDER BY s.id`,
+	},
+	{
+		Name: "teams",
+		Columns: []Column{
+			{"id", String}, {"name", String}, {"country_code", String}, {"logo_url", String},
+			{"logo_source", String}, {"alternate_names", StringList},
+			{"created_at", Time}, {"updated_at", Time},
+		},
+		query: `
+        SELECT t.id, t.name, t.country_code, t.logo_url, t.logo_source, t.alternate_names,
+               t.created_at, t.updated_at
+        FROM teams t
+        WHERE ` + teamScope + `
+        ORDER BY t.id`,
+	},
+	{
+		Name: "stadiums",
+		Columns: []Column{
+			{"id", String}, {"name", String}, {"capacity", Int}, {"location", String},
+			{"country_code", String}, {"created_at", Time}, {"updated_at", Time},
+		},
+		query: `
+        SELECT st.id, st.name, st.capacity, st.location, st.country_code, st.created_at, st.updated_at
+        FROM stadiums st
+        WHERE ` + unfiltered + ` OR st.id IN (
+            SELECT ts.stadium_id FROM team_stadiums ts
+            JOIN teams t ON t.id = ts.team_id
+            WHERE ` + teamScope + `)
+        ORDER BY st.id`,
+	},
+	{
+		Name: "matches",
+		Columns: []Column{
+			{"id", String}, {"home_team_id", String}, {"away_team_id", String}, {"league_id", String},
+			{"home_score", Int}, {"away_score", Int}, {"status", String}, {"kick_off", Time},
+			{"venue_timezone", String}, {"venue", String}, {"created_at", Time}, {"updated_at", Time},
+		},
+		query: `
+        SELECT m.id, m.home_team_id, m.away_team_id, m.league_id, m.home_score, m.away_score,
+               m.status, m.kick_off, m.venue_timezone, m.venue, m.created_at, m.updated_at
+        FROM matches m
+        WHERE ` + matchScope + `
+        ORDER BY m.kick_off, m.id`,
+	},
+	{
+		Name: "api_mappings",
+		Columns: []Column{
+			{"id", Int}, {"entity_type", String}, {"entity_id", String}, {"api_name", String},
+			{"api_id", String}, {"is_active", Bool}, {"created_at", Time}, {"updated_at", Time},
+		},
+		query: `
+        SELECT a.id, a.entity_type, a.entity_id, a.api_name, a.api_id, a.is_active,
+               a.created_at, a.updated_at
+        FROM api_mappings a
+        WHERE ` + unfiltered + ` OR a.entity_id IN (
+            SELECT l.id FROM leagues l WHERE ` + leagueScope + `
+            UNION
+            SELECT s.id FROM seasons s WHERE ` + seasonScope + `
+            UNION
+            SELECT t.id FROM teams t WHERE ` + teamScope + `
+            UNION
+            SELECT m.id FROM matches m WHERE ` + matchScope + `)
+        ORDER BY a.id`,
+	},
+}
+
+// LookupTables resolves table names; an empty list selects every table.
+func LookupTables(names []string) ([]Table, error) {
+	if len(names) == 0 {
+		return Tables, nil
+	}
+	var tables []Table
+	for _, name := range names {
+		table, ok := lookupTable(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("%w %q; expected one of %s", ErrUnknownTable, name, strings.Join(TableNames(), ", "))
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+func TableNames() []string {
+	names := make([]string, len(Tables))
+	for i, t := range Tables {
+		names[i] = t.Name
+	}
+	return names
+}
+
+func lookupTable(name string) (Table, bool) {
+	for _, t := range Tables {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Table{}, false
+}
@@ -0,0 +1,156 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// listSeparator joins list columns in formats without a native list type.
+const listSeparator = "|"
+
+type rowWriter interface {
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+func newRowWriter(format Format, columns []Column, w io.Writer) (rowWriter, error) {
+	switch format {
+	case CSV:
+		return newCSVWriter(columns, w)
+	case JSONL:
+		return &jsonlWriter{columns: columns, w: bufio.NewWriter(w)}, nil
+	case Parquet:
+		return newParquetWriter(columns, w)
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(columns []Column, w io.Writer) (*csvWriter, error) {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	return &csvWriter{w: cw}, cw.Write(header)
+}
+
+func (c *csvWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case nil:
+		case string:
+			record[i] = v
+		case int64:
+			record[i] = strconv.FormatInt(v, 10)
+		case bool:
+			record[i] = strconv.FormatBool(v)
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		case time.Time:
+			record[i] = v.Format(time.RFC3339)
+		case []string:
+			record[i] = strings.Join(v, listSeparator)
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonlWriter writes one object per row, keeping the table's column order.
+type jsonlWriter struct {
+	columns []Column
+	w       *bufio.Writer
+	line    bytes.Buffer
+}
+
+func (j *jsonlWriter) WriteRow(values []interface{}) error {
+	j.line.Reset()
+	j.line.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			j.line.WriteByte(',')
+		}
+		key, _ := json.Marshal(j.columns[i].Name)
+		j.line.Write(key)
+		j.line.WriteByte(':')
+		value, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		j.line.Write(value)
+	}
+	j.line.WriteString("}\n")
+	_, err := j.w.Write(j.line.Bytes())
+	return err
+}
+
+func (j *jsonlWriter) Close() error {
+	return j.w.Flush()
+}
+
+type parquetWriter struct {
+	w *writer.CSVWriter
+}
+
+func newParquetWriter(columns []Column, w io.Writer) (*parquetWriter, error) {
+	schema := make([]string, len(columns))
+	for i, col := range columns {
+		var typ string
+		switch col.Kind {
+		case Int:
+			typ = "type=INT64"
+		case Bool:
+			typ = "type=BOOLEAN"
+		case Float:
+			typ = "type=DOUBLE"
+		case Time:
+			typ = "type=INT64, convertedtype=TIMESTAMP_MILLIS"
+		default:
+			typ = "type=BYTE_ARRAY, convertedtype=UTF8"
+		}
+		schema[i] = fmt.Sprintf("name=%s, %s, repetitiontype=OPTIONAL", col.Name, typ)
+	}
+
+	pw, err := writer.NewCSVWriterFromWriter(schema, w, 1)
+	if err != nil {
+		return nil, err
+	}
+	return &parquetWriter{w: pw}, nil
+}
+
+func (p *parquetWriter) WriteRow(values []interface{}) error {
+	record := make([]interface{}, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case time.Time:
+			record[i] = v.UnixMilli()
+		case []string:
+			record[i] = strings.Join(v, listSeparator)
+		default:
+			record[i] = v
+		}
+	}
+	return p.w.Write(record)
+}
+
+func (p *parquetWriter) Close() error {
+	return p.w.WriteStop()
+}
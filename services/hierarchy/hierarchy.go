@@ -0,0 +1,262 @@
+// Package hierarchy describes how leagues relate to one another: the
+// competitions played within a competition, such as the Bledisloe Cup within
+// the Rugby Championship, and the names a competition has been played under
+// over the years.
+package hierarchy
+
+import (
+	"context"
+	"rugby-live-api/db"
+	"rugby-live-api/models"
+	"strings"
+)
+
+// LeagueNode is a league and the competitions played within it.
+type LeagueNode struct {
+	League   models.League `json:"league"`
+	Children []LeagueNode  `json:"children"`
+}
+
+// LeagueTree places a league among its relatives. Ancestors runs from the
+// outermost competition down to the league's parent.
+type LeagueTree struct {
+	Ancestors []models.League `json:"ancestors"`
+	League    LeagueNode      `json:"league"`
+}
+
+// LineageEntry is one name a competition was played under. LeagueID is empty
+// for a name only recorded as a rename in league_transitions. Years are
+// inclusive and zero when unknown; the current name's ToYear is its latest
+// stored season. RenamedTo is the Name of the entry that followed, empty for
+// the current name.
+type LineageEntry struct {
+	LeagueID    string `json:"league_id,omitempty"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	FromYear    int    `json:"from_year,omitempty"`
+	ToYear      int    `json:"to_year,omitempty"`
+	RenamedTo   string `json:"renamed_to,omitempty"`
+}
+
+// LeagueLineage is a league's predecessors and successors, oldest first.
+type LeagueLineage struct {
+	LeagueID string         `json:"league_id"`
+	Entries  []LineageEntry `json:"entries"`
+}
+
+// Tree returns the league with id among its ancestors and descendants, or
+// nil when there is no such league.
+func Tree(ctx context.Context, store *db.Store, id string) (*LeagueTree, error) {
+	league, err := getLeague(ctx, store, id)
+	if league == nil || err != nil {
+		return nil, err
+	}
+
+	// seen guards against parent links that loop.
+	seen := map[string]bool{league.ID: true}
+	tree := &LeagueTree{
+		Ancestors: []models.League{},
+		League:    LeagueNode{League: *league, Children: []LeagueNode{}},
+	}
+	for parentID := league.ParentID; parentID != nil && !seen[*parentID]; {
+		seen[*parentID] = true
+		parent, err := getLeague(ctx, store, *parentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			break
+		}
+		tree.Ancestors = append([]models.League{*parent}, tree.Ancestors...)
+		parentID = parent.ParentID
+	}
+
+	// Load descendants a generation at a time.
+	level := []*LeagueNode{&tree.League}
+	for len(level) > 0 {
+		byID := make(map[string]*LeagueNode, len(level))
+		ids := make([]string, len(level))
+		for i, node := range level {
+			byID[node.League.ID] = node
+			ids[i] = node.League.ID
+		}
+		children, err := store.GetLeaguesByParentIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range children {
+			if seen[child.ID] {
+				continue
+			}
+			seen[child.ID] = true
+			parent := byID[*child.ParentID]
+			parent.Children = append(parent.Children, LeagueNode{League: child, Children: []LeagueNode{}})
+		}
+
+		var next []*LeagueNode
+		for _, node := range level {
+			for i := range node.Children {
+				next = append(next, &node.Children[i])
+			}
+		}
+		level = next
+	}
+	return tree, nil
+}
+
+// Lineage returns the chain of leagues linked to the league with id by
+// successor_league_id, with the renames recorded against them in
+// league_transitions, or nil when there is no such league. A league whose
+// seasons span a rename, such as the URC's stored Pro12 and Pro14 seasons, is
+// split at each rename so every entry shows the name used at the time.
+func Lineage(ctx context.Context, store *db.Store, id string) (*LeagueLineage, error) {
+	league, err := getLeague(ctx, store, id)
+	if league == nil || err != nil {
+		return nil, err
+	}
+
+	chain := []models.League{*league}
+	seen := map[string]bool{league.ID: true}
+	for frontier := []string{league.ID}; len(frontier) > 0; {
+		predecessors, err := store.GetLeaguesBySuccessorIDs(ctx, frontier)
+		if err != nil {
+			return nil, err
+		}
+		frontier = nil
+		for _, p := range predecessors {
+			if !seen[p.ID] {
+				seen[p.ID] = true
+				chain = append([]models.League{p}, chain...)
+				frontier = append(frontier, p.ID)
+			}
+		}
+	}
+	for successorID := league.SuccessorID; successorID != nil && !seen[*successorID]; {
+		seen[*successorID] = true
+		successor, err := getLeague(ctx, store, *successorID)
+		if err != nil {
+			return nil, err
+		}
+		if successor == nil {
+			break
+		}
+		chain = append(chain, *successor)
+		successorID = successor.SuccessorID
+	}
+
+	ids := make([]string, len(chain))
+	for i, l := range chain {
+		ids[i] = l.ID
+	}
+	seasons, err := store.GetSeasonsByLeagueIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	transitions, err := store.GetLeagueTransitionsBySuccessorIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return &LeagueLineage{LeagueID: league.ID, Entries: lineageEntries(chain, seasons, transitions)}, nil
+}
+
+// lineageEntries orders chain's names oldest first. transitions must be
+// ordered earliest first.
+func lineageEntries(chain []models.League, seasons []models.Season, transitions []models.LeagueTransition) []LineageEntry {
+	entries := make([]LineageEntry, len(chain))
+	byName := make(map[string]int, len(chain))
+	names := make(map[string]string, len(chain))
+	for i, l := range chain {
+		entries[i] = LineageEntry{LeagueID: l.ID, Name: l.Name, DisplayName: l.Name}
+		byName[strings.ToLower(l.Name)] = i
+		names[l.ID] = l.Name
+	}
+	for i, l := range chain {
+		if l.SuccessorID != nil {
+			entries[i].RenamedTo = names[*l.SuccessorID]
+		}
+	}
+	for _, s := range seasons {
+		for i := range entries {
+			e := &entries[i]
+			if e.LeagueID != s.LeagueID {
+				continue
+			}
+			if e.FromYear == 0 || s.Year < e.FromYear {
+				e.FromYear = s.Year
+			}
+			if s.Year > e.ToYear {
+				e.ToYear = s.Year
+			}
+		}
+	}
+
+	// unnamed tracks, per successor, the first year not yet covered by an
+	// earlier name; renamed collects names with no league of their own.
+	unnamed := make(map[string]int, len(entries))
+	for _, e := range entries {
+		unnamed[e.LeagueID] = e.FromYear
+	}
+	type rename struct {
+		entry       LineageEntry
+		successorID string
+	}
+	var renamed []rename
+	for _, t := range transitions {
+		renamedTo := t.RenamedTo
+		if renamedTo == "" {
+			renamedTo = names[t.SuccessorID]
+		}
+		if i, ok := byName[strings.ToLower(t.OldName)]; ok {
+			entries[i].DisplayName = t.DisplayName
+			entries[i].ToYear = t.Year - 1
+			entries[i].RenamedTo = renamedTo
+		} else {
+			from := unnamed[t.SuccessorID]
+			if from >= t.Year {
+				from = 0
+			}
+			renamed = append(renamed, rename{
+				entry:       LineageEntry{Name: t.OldName, DisplayName: t.DisplayName, FromYear: from, ToYear: t.Year - 1, RenamedTo: renamedTo},
+				successorID: t.SuccessorID,
+			})
+		}
+		unnamed[t.SuccessorID] = t.Year
+	}
+	for i := range entries {
+		if from := unnamed[entries[i].LeagueID]; from > entries[i].FromYear {
+			entries[i].FromYear = from
+		}
+	}
+
+	// A rename goes before the name it became, or else its successor, and
+	// before any league in the chain that only started once the old name was
+	// dropped.
+	for _, r := range renamed {
+		pos := 0
+		for i, e := range entries {
+			if e.LeagueID == r.successorID {
+				pos = i
+				break
+			}
+		}
+		for i, e := range entries {
+			if strings.EqualFold(e.Name, r.entry.RenamedTo) {
+				pos = i
+				break
+			}
+		}
+		for pos > 0 && entries[pos-1].FromYear > r.entry.ToYear {
+			pos--
+		}
+		entries = append(entries[:pos], append([]LineageEntry{r.entry}, entries[pos:]...)...)
+	}
+	return entries
+}
+
+func getLeague(ctx context.Context, store *db.Store, id string) (*models.League, error) {
+	leagues, err := store.GetLeaguesByIDs(ctx, []string{id})
+	if err != nil || len(leagues) == 0 {
+		return nil, err
+	}
+	return &leagues[0], nil
+}
@@ -0,0 +1,118 @@
+package hierarchy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"rugby-live-api/models"
+)
+
+const urcID = "EUR-UNITED-RUGBY-CHAMPIONSHIP"
+
+// urcTransitions are the URC's renames as seeded by migrations 0008 and
+// 0010, earliest first.
+var urcTransitions = []models.LeagueTransition{
+	{OldName: "Pro12", SuccessorID: urcID, Year: 2017, DisplayName: "Pro12", RenamedTo: "Pro14"},
+	{OldName: "Pro14", SuccessorID: urcID, Year: 2021, DisplayName: "Pro14"},
+}
+
+func urcSeasons(from, to int) []models.Season {
+	var seasons []models.Season
+	for year := from; year <= to; year++ {
+		seasons = append(seasons, models.Season{LeagueID: urcID, Year: year})
+	}
+	return seasons
+}
+
+// Walking renamed_to from the Pro12 reaches the URC through the Pro14.
+func TestLineageWalksFromPro12(t *testing.T) {
+	chain := []models.League{{ID: urcID, Name: "United Rugby Championship"}}
+	entries := lineageEntries(chain, urcSeasons(2011, 2024), urcTransitions)
+
+	byName := make(map[string]LineageEntry, len(entries))
+	for _, e := range entries {
+		byName[strings.ToLower(e.Name)] = e
+	}
+	type step struct {
+		name     string
+		from, to int
+	}
+	var walked []step
+	for e, ok := byName["pro12"]; ok; e, ok = byName[strings.ToLower(e.RenamedTo)] {
+		walked = append(walked, step{e.Name, e.FromYear, e.ToYear})
+		if len(walked) > len(entries) {
+			t.Fatalf("renamed_to loops: %+v", entries)
+		}
+	}
+	want := []step{
+		{"Pro12", 2011, 2016},
+		{"Pro14", 2017, 2020},
+		{"United Rugby Championship", 2021, 2024},
+	}
+	if !reflect.DeepEqual(walked, want) {
+		t.Errorf("walk from Pro12 = %+v, want %+v", walked, want)
+	}
+
+	var order []string
+	for _, e := range entries {
+		order = append(order, e.Name)
+	}
+	if want := []string{"Pro12", "Pro14", "United Rugby Championship"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("entries = %v, want %v", order, want)
+	}
+}
+
+func TestLineageEntries(t *testing.T) {
+	trc := "WLD-THE-RUGBY-CHAMPIONSHIP"
+	tests := []struct {
+		name        string
+		chain       []models.League
+		seasons     []models.Season
+		transitions []models.LeagueTransition
+		want        []LineageEntry
+	}{
+		{
+			name:    "rename without renamed_to goes to the successor",
+			chain:   []models.League{{ID: trc, Name: "The Rugby Championship"}},
+			seasons: []models.Season{{LeagueID: trc, Year: 2010}, {LeagueID: trc, Year: 2015}},
+			transitions: []models.LeagueTransition{
+				{OldName: "Tri Nations", SuccessorID: trc, Year: 2012, DisplayName: "Tri Nations"},
+			},
+			want: []LineageEntry{
+				{Name: "Tri Nations", DisplayName: "Tri Nations", FromYear: 2010, ToYear: 2011, RenamedTo: "The Rugby Championship"},
+				{LeagueID: trc, Name: "The Rugby Championship", DisplayName: "The Rugby Championship", FromYear: 2012, ToYear: 2015},
+			},
+		},
+		{
+			name: "league with its own row links to its successor",
+			chain: []models.League{
+				{ID: "OLD", Name: "Old Cup", SuccessorID: &trc},
+				{ID: trc, Name: "The Rugby Championship"},
+			},
+			seasons: []models.Season{{LeagueID: "OLD", Year: 2005}, {LeagueID: trc, Year: 2012}},
+			want: []LineageEntry{
+				{LeagueID: "OLD", Name: "Old Cup", DisplayName: "Old Cup", FromYear: 2005, ToYear: 2005, RenamedTo: "The Rugby Championship"},
+				{LeagueID: trc, Name: "The Rugby Championship", DisplayName: "The Rugby Championship", FromYear: 2012, ToYear: 2012},
+			},
+		},
+		{
+			name:        "chained renames",
+			chain:       []models.League{{ID: urcID, Name: "United Rugby Championship"}},
+			seasons:     urcSeasons(2018, 2022),
+			transitions: urcTransitions,
+			want: []LineageEntry{
+				{Name: "Pro12", DisplayName: "Pro12", ToYear: 2016, RenamedTo: "Pro14"},
+				{Name: "Pro14", DisplayName: "Pro14", FromYear: 2017, ToYear: 2020, RenamedTo: "United Rugby Championship"},
+				{LeagueID: urcID, Name: "United Rugby Championship", DisplayName: "United Rugby Championship", FromYear: 2021, ToYear: 2022},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lineageEntries(tt.chain, tt.seasons, tt.transitions); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lineageEntries() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
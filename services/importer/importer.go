@@ -0,0 +1,267 @@
+// Package importer loads historic results held in CSV or JSON files. Team and
+// league names are resolved the same way as scraped data, through alternate
+// names and TeamNameMapping, and every valid row is written in a single
+// transaction.
+package importer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"rugby-live-api/db"
+	"rugby-live-api/models"
+	"rugby-live-api/services/kickoff"
+	"rugby-live-api/services/reconcile"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rejected is a row that failed validation or did not resolve.
+type Rejected struct {
+	Line   int
+	Reason string
+}
+
+// Report summarises an import. In a dry run it describes what would have
+// been written.
+type Report struct {
+	DryRun     bool
+	Rows       int
+	Matches    int
+	NewSeasons []string
+	Rejected   []Rejected
+}
+
+// match is a validated row ready to be written.
+type match struct {
+	season *models.Season
+	match  models.Match
+}
+
+var seasonPattern = regexp.MustCompile(`^(\d{4})(?:\s*[-/]\s*(\d{2}|\d{4}))?$`)
+
+// Import validates rows, resolves their names and upserts the resulting
+// seasons and matches. Rows that fail are reported and skipped; the rest are
+// written in one transaction, or not at all when dryRun is set. Importing the
+// same file twice updates the same matches.
+func Import(ctx context.Context, store *db.Store, rows []Row, dryRun bool) (*Report, error) {
+	r, err := newResolver(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{DryRun: dryRun, Rows: len(rows)}
+	seasons := &seasonCache{store: store, byLeague: make(map[string]map[int]*models.Season), created: make(map[string]bool)}
+	seen := make(map[string]int) // match ID -> line
+	var matches []match
+
+	for _, row := range rows {
+		m, err := r.resolveRow(ctx, row, seasons)
+		if err == nil {
+			if line, ok := seen[m.match.ID]; ok {
+				err = fmt.Errorf("duplicate of line %d", line)
+			}
+		}
+		if err != nil {
+			report.Rejected = append(report.Rejected, Rejected{Line: row.Line, Reason: err.Error()})
+			continue
+		}
+		seen[m.match.ID] = row.Line
+		matches = append(matches, m)
+	}
+
+	for id := range seasons.created {
+		report.NewSeasons = append(report.NewSeasons, id)
+	}
+	sort.Strings(report.NewSeasons)
+	report.Matches = len(matches)
+
+	if dryRun || len(matches) == 0 {
+		return report, nil
+	}
+
+	err = store.WithTx(ctx, func(tx *db.Store) error {
+		written := make(map[string]bool)
+		byDate := make(map[string][]string)
+		batch := make([]*models.Match, len(matches))
+		for i := range matches {
+			m := &matches[i]
+			if seasons.created[m.season.ID] && !written[m.season.ID] {
+				if err := tx.UpsertSeason(ctx, m.season); err != nil {
+					return fmt.Errorf("failed to upsert season %s: %v", m.season.ID, err)
+				}
+				if err := tx.UpdateCurrentSeason(ctx, m.season.LeagueID); err != nil {
+					return fmt.Errorf("failed to update current season for league %s: %v", m.season.LeagueID, err)
+				}
+				written[m.season.ID] = true
+			}
+			batch[i] = &m.match
+			date := m.match.KickOff.Format("2006-01-02")
+			byDate[date] = append(byDate[date], m.match.ID)
+		}
+		if err := reconcile.RecordAll(ctx, tx, reconcile.SourceImport, batch); err != nil {
+			return fmt.Errorf("failed to upsert %d matches: %v", len(batch), err)
+		}
+		for date, ids := range byDate {
+			if err := tx.UpsertDailyMatches(ctx, date, ids); err != nil {
+				return fmt.Errorf("failed to index matches for %s: %v", date, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (r *resolver) resolveRow(ctx context.Context, row Row, seasons *seasonCache) (match, error) {
+	for _, f := range []struct{ name, value string }{
+		{"league", row.League}, {"kick_off", row.KickOff},
+		{"home_team", row.HomeTeam}, {"away_team", row.AwayTeam},
+		{"home_score", row.HomeScore}, {"away_score", row.AwayScore},
+	} {
+		if f.value == "" {
+			return match{}, fmt.Errorf("missing %s", f.name)
+		}
+	}
+
+	homeScore, err := parseScore(row.HomeScore)
+	if err != nil {
+		return match{}, fmt.Errorf("home_score: %v", err)
+	}
+	awayScore, err := parseScore(row.AwayScore)
+	if err != nil {
+		return match{}, fmt.Errorf("away_score: %v", err)
+	}
+
+	league, err := r.league(row.League)
+	if err != nil {
+		return match{}, err
+	}
+	home, err := r.team(row.HomeTeam)
+	if err != nil {
+		return match{}, err
+	}
+	away, err := r.team(row.AwayTeam)
+	if err != nil {
+		return match{}, err
+	}
+	if home.ID == away.ID {
+		return match{}, fmt.Errorf("%q and %q are the same team", row.HomeTeam, row.AwayTeam)
+	}
+
+	venueTimezone := row.Timezone
+	if venueTimezone == "" {
+		venueTimezone = kickoff.CountryTimezones[league.Country.Code]
+	}
+	kickOff, err := parseKickOff(row.KickOff, venueTimezone)
+	if err != nil {
+		return match{}, err
+	}
+
+	season, err := seasons.lookup(ctx, league, row.Season, kickOff, venueTimezone)
+	if err != nil {
+		return match{}, err
+	}
+
+	return match{
+		season: season,
+		match: models.Match{
+			ID:            fmt.Sprintf("%s-%s-%s-%s", season.ID, home.ID, away.ID, kickOff.Format("20060102")),
+			HomeTeamID:    home.ID,
+			AwayTeamID:    away.ID,
+			LeagueID:      season.ID,
+			HomeScore:     homeScore,
+			AwayScore:     awayScore,
+			Status:        models.StatusFinished,
+			KickOff:       kickOff,
+			VenueTimezone: venueTimezone,
+		},
+	}, nil
+}
+
+func parseScore(value string) (int, error) {
+	score, err := strconv.Atoi(value)
+	if err != nil || score < 0 {
+		return 0, fmt.Errorf("invalid score %q", value)
+	}
+	return score, nil
+}
+
+// parseKickOff accepts any kick-off format the providers use. Historic
+// spreadsheets often only hold the date, which is taken as midday at the
+// venue so the match stays on that date in every nearby timezone.
+func parseKickOff(value, venueTimezone string) (time.Time, error) {
+	loc, err := kickoff.LoadLocation(venueTimezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if day, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return day.Add(12 * time.Hour).UTC(), nil
+	}
+	return kickoff.Parse(value, venueTimezone)
+}
+
+// seasonCache holds each league's seasons, loaded on first use, along with
+// the seasons the import has to create.
+type seasonCache struct {
+	store    *db.Store
+	byLeague map[string]map[int]*models.Season // league ID -> year -> season
+	created  map[string]bool
+}
+
+// lookup finds the league's season for a row, creating it in memory when the
+// database has none. value is a year such as "2019" or a range such as
+// "2019-20"; when empty the kick-off's local year is used, so split-year
+// leagues should always give one.
+func (c *seasonCache) lookup(ctx context.Context, league models.League, value string, kickOff time.Time, venueTimezone string) (*models.Season, error) {
+	year, yearRange := 0, value
+	if value == "" {
+		loc, _ := kickoff.LoadLocation(venueTimezone)
+		year = kickOff.In(loc).Year()
+		yearRange = strconv.Itoa(year)
+	} else {
+		parts := seasonPattern.FindStringSubmatch(value)
+		if parts == nil {
+			return nil, fmt.Errorf("invalid season %q", value)
+		}
+		year, _ = strconv.Atoi(parts[1])
+		yearRange = strings.ReplaceAll(value, " ", "")
+	}
+
+	byYear, ok := c.byLeague[league.ID]
+	if !ok {
+		existing, err := c.store.GetSeasonsByLeagueIDs(ctx, []string{league.ID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load seasons for league %s: %v", league.ID, err)
+		}
+		byYear = make(map[int]*models.Season)
+		for i := range existing {
+			byYear[existing[i].Year] = &existing[i]
+		}
+		c.byLeague[league.ID] = byYear
+	}
+	if season, ok := byYear[year]; ok {
+		return season, nil
+	}
+
+	season := &models.Season{
+		ID:        fmt.Sprintf("%s-SEASON-%d", league.ID, year),
+		LeagueID:  league.ID,
+		Year:      year,
+		YearRange: yearRange,
+	}
+	if yearRange != strconv.Itoa(year) {
+		season.StartDate = time.Date(year, 8, 1, 0, 0, 0, 0, time.UTC)
+		season.EndDate = time.Date(year+1, 5, 31, 0, 0, 0, 0, time.UTC)
+	} else {
+		season.StartDate = time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+		season.EndDate = time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC)
+	}
+	byYear[year] = season
+	c.created[season.ID] = true
+	return season, nil
+}
@@ -0,0 +1,129 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Row is one result as read from a file. Names are resolved against the
+// database later, so they are kept exactly as written.
+type Row struct {
+	Line      int
+	League    string
+	Season    string
+	KickOff   string
+	Timezone  string
+	HomeTeam  string
+	AwayTeam  string
+	HomeScore string
+	AwayScore string
+}
+
+// Columns lists the fields a results file may carry. CSV files name them in
+// a header row; JSON files use them as object keys.
+var Columns = []string{"league", "season", "kick_off", "timezone", "home_team", "away_team", "home_score", "away_score"}
+
+var requiredColumns = []string{"league", "kick_off", "home_team", "away_team", "home_score", "away_score"}
+
+// ReadFile reads rows from r, choosing CSV or JSON from the file name's
+// extension.
+func ReadFile(name string, r io.Reader) ([]Row, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return ReadCSV(r)
+	case ".json":
+		return ReadJSON(r)
+	}
+	return nil, fmt.Errorf("unsupported file %q; expected .csv or .json", name)
+}
+
+// ReadCSV reads rows from a CSV file with a header row. Columns may appear in
+// any order and unknown columns are ignored.
+func ReadCSV(r io.Reader) ([]Row, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %v", err)
+	}
+	index := make(map[string]int)
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	var rows []Row
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		rows = append(rows, Row{
+			Line:      line,
+			League:    field("league"),
+			Season:    field("season"),
+			KickOff:   field("kick_off"),
+			Timezone:  field("timezone"),
+			HomeTeam:  field("home_team"),
+			AwayTeam:  field("away_team"),
+			HomeScore: field("home_score"),
+			AwayScore: field("away_score"),
+		})
+	}
+	return rows, nil
+}
+
+// ReadJSON reads rows from a JSON array of objects. Seasons and scores may be
+// written as numbers or strings. Line is the row's 1-based position in the
+// array.
+func ReadJSON(r io.Reader) ([]Row, error) {
+	var records []map[string]interface{}
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, fmt.Errorf("failed to decode results: %v", err)
+	}
+
+	rows := make([]Row, len(records))
+	for i, record := range records {
+		field := func(name string) string {
+			switch v := record[name].(type) {
+			case string:
+				return strings.TrimSpace(v)
+			case float64:
+				return strconv.FormatFloat(v, 'f', -1, 64)
+			}
+			return ""
+		}
+		rows[i] = Row{
+			Line:      i + 1,
+			League:    field("league"),
+			Season:    field("season"),
+			KickOff:   field("kick_off"),
+			Timezone:  field("timezone"),
+			HomeTeam:  field("home_team"),
+			AwayTeam:  field("away_team"),
+			HomeScore: field("home_score"),
+			AwayScore: field("away_score"),
+		}
+	}
+	return rows, nil
+}
@@ -0,0 +1,108 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"rugby-live-api/db"
+	"rugby-live-api/models"
+	"rugby-live-api/services"
+	"sort"
+	"strings"
+)
+
+// names indexes entities by every name they are known by: the canonical name,
+// alternate names and, for teams, the nickname in TeamNameMapping. Keys are
+// compared case-insensitively.
+type names[T any] struct {
+	byName map[string][]T
+	id     func(T) string
+}
+
+func newNames[T any](id func(T) string) *names[T] {
+	return &names[T]{byName: make(map[string][]T), id: id}
+}
+
+func (n *names[T]) add(item T, aliases ...string) {
+	for _, alias := range aliases {
+		key := nameKey(alias)
+		if key == "" {
+			continue
+		}
+		found := false
+		for _, existing := range n.byName[key] {
+			if n.id(existing) == n.id(item) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			n.byName[key] = append(n.byName[key], item)
+		}
+	}
+}
+
+// lookup resolves name, falling back to its standard name from
+// TeamNameMapping. A name shared by several entities is an error rather than
+// a guess.
+func (n *names[T]) lookup(kind, name string) (T, error) {
+	var zero T
+	candidates := n.byName[nameKey(name)]
+	if len(candidates) == 0 {
+		if standard, ok := services.TeamNameMapping[name]; ok {
+			candidates = n.byName[nameKey(standard)]
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return zero, fmt.Errorf("unknown %s %q", kind, name)
+	case 1:
+		return candidates[0], nil
+	}
+	ids := make([]string, len(candidates))
+	for i, c := range candidates {
+		ids[i] = n.id(c)
+	}
+	sort.Strings(ids)
+	return zero, fmt.Errorf("ambiguous %s %q matches %s", kind, name, strings.Join(ids, ", "))
+}
+
+func nameKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// resolver looks up the teams and leagues named in a results file.
+type resolver struct {
+	teams   *names[models.Team]
+	leagues *names[models.League]
+}
+
+func newResolver(ctx context.Context, store *db.Store) (*resolver, error) {
+	teams, err := store.GetTeamNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load teams: %v", err)
+	}
+	leagues, err := store.GetLeagueNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load leagues: %v", err)
+	}
+
+	r := &resolver{
+		teams:   newNames(func(t models.Team) string { return t.ID }),
+		leagues: newNames(func(l models.League) string { return l.ID }),
+	}
+	for _, team := range teams {
+		r.teams.add(team, append([]string{team.ID, team.Name, services.TeamNameMapping[team.Name]}, team.AltNames...)...)
+	}
+	for _, league := range leagues {
+		r.leagues.add(league, append([]string{league.ID, league.Name}, league.AltNames...)...)
+	}
+	return r, nil
+}
+
+func (r *resolver) team(name string) (models.Team, error) {
+	return r.teams.lookup("team", name)
+}
+
+func (r *resolver) league(name string) (models.League, error) {
+	return r.leagues.lookup("league", name)
+}
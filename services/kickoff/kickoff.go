@@ -0,0 +1,132 @@
+package kickoff
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // Venue zones must resolve in minimal containers
+)
+
+// layouts are the kick-off formats seen across providers. Layouts without an
+// offset are interpreted in the provider's timezone.
+var layouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05-07:00",
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02 15:04:05-07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+}
+
+// CountryTimezones maps our country codes to the IANA zone used for venues in
+// that country. Countries spanning several zones use their main rugby zone.
+var CountryTimezones = map[string]string{
+	"ARG": "America/Argentina/Buenos_Aires",
+	"AUS": "Australia/Sydney",
+	"BEL": "Europe/Brussels",
+	"BRA": "America/Sao_Paulo",
+	"CAN": "America/Toronto",
+	"CHL": "America/Santiago",
+	"ENG": "Europe/London",
+	"ESP": "Europe/Madrid",
+	"FJI": "Pacific/Fiji",
+	"FRA": "Europe/Paris",
+	"GEO": "Asia/Tbilisi",
+	"GER": "Europe/Berlin",
+	"HKG": "Asia/Hong_Kong",
+	"IRL": "Europe/Dublin",
+	"ITA": "Europe/Rome",
+	"JPN": "Asia/Tokyo",
+	"KEN": "Africa/Nairobi",
+	"NAM": "Africa/Windhoek",
+	"NIR": "Europe/London",
+	"NLD": "Europe/Amsterdam",
+	"NZL": "Pacific/Auckland",
+	"POR": "Europe/Lisbon",
+	"ROU": "Europe/Bucharest",
+	"RSA": "Africa/Johannesburg",
+	"SAM": "Pacific/Apia",
+	"SCO": "Europe/London",
+	"SWI": "Europe/Zurich",
+	"TGA": "Pacific/Tongatapu",
+	"UGA": "Africa/Kampala",
+	"UGY": "America/Montevideo",
+	"USA": "America/New_York",
+	"WAL": "Europe/London",
+	"ZIM": "Africa/Harare",
+}
+
+// Parse parses a provider kick-off and returns it in UTC. providerTZ is the
+// IANA zone the provider reports times in and is only used when the value
+// carries no offset; an empty providerTZ means UTC.
+func Parse(value string, providerTZ string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("empty kick-off")
+	}
+
+	loc, err := LoadLocation(providerTZ)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised kick-off format %q", value)
+}
+
+// VenueTimezone returns the IANA zone for a venue in countryCode. When the
+// country is unknown it falls back to the fixed offset the provider used, as
+// "+05:30", so the local kick-off time can still be reconstructed.
+func VenueTimezone(countryCode string, value string) string {
+	if tz, ok := CountryTimezones[strings.ToUpper(countryCode)]; ok {
+		return tz
+	}
+
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+	if err != nil {
+		return "UTC"
+	}
+	_, offset := t.Zone()
+	if offset == 0 {
+		return "UTC"
+	}
+	return t.Format("-07:00")
+}
+
+// LoadLocation resolves an IANA zone name, or a fixed offset such as
+// "+05:30" as VenueTimezone returns, treating an empty name as UTC.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" || strings.EqualFold(name, "UTC") {
+		return time.UTC, nil
+	}
+	if t, err := time.Parse("-07:00", name); err == nil {
+		_, offset := t.Zone()
+		return time.FixedZone(name, offset), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return loc, nil
+}
+
+// DayBounds returns the UTC instants that bound the calendar day date
+// (YYYY-MM-DD) as observed in loc. The end bound is exclusive.
+func DayBounds(date string, loc *time.Location) (time.Time, time.Time, error) {
+	day, err := time.ParseInLocation("2006-01-02", date, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+	}
+	return day.UTC(), day.AddDate(0, 0, 1).UTC(), nil
+}
+
+// Today returns the current date (YYYY-MM-DD) as observed in loc.
+func Today(loc *time.Location) string {
+	return time.Now().In(loc).Format("2006-01-02")
+}
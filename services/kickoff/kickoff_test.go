@@ -0,0 +1,84 @@
+package kickoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVenueTimezone(t *testing.T) {
+	tests := []struct {
+		country, value, want string
+	}{
+		{"NZL", "2025-03-01T19:35:00+13:00", "Pacific/Auckland"},
+		{"nzl", "not a time", "Pacific/Auckland"},
+		{"", "2025-03-01T19:35:00+02:00", "+02:00"},
+		{"", "2025-03-01T19:35:00+05:30", "+05:30"},
+		{"IND", "2025-03-01T19:35:00+05:30", "+05:30"},
+		{"", "2025-03-01T19:35:00+09:30", "+09:30"},
+		{"", "2025-03-01T19:35:00+12:45", "+12:45"},
+		{"", "2025-03-01T19:35:00-03:30", "-03:30"},
+		{"", "2025-03-01T19:35:00Z", "UTC"},
+		{"", "2025-03-01T19:35:00+00:00", "UTC"},
+		{"", "2025-03-01 19:35", "UTC"},
+	}
+	for _, tt := range tests {
+		if got := VenueTimezone(tt.country, tt.value); got != tt.want {
+			t.Errorf("VenueTimezone(%q, %q) = %q, want %q", tt.country, tt.value, got, tt.want)
+		}
+	}
+}
+
+// A kick-off read back in the fallback zone keeps its local time.
+func TestVenueTimezoneRoundTrip(t *testing.T) {
+	for _, value := range []string{
+		"2025-03-01T19:35:00+05:30",
+		"2025-03-01T19:35:00+12:45",
+		"2025-03-01T19:35:00-03:30",
+		"2025-03-01T19:35:00-10:00",
+	} {
+		kickOff, err := Parse(value, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		loc, err := LoadLocation(VenueTimezone("", value))
+		if err != nil {
+			t.Errorf("LoadLocation(VenueTimezone(%q)): %v", value, err)
+			continue
+		}
+		if got := kickOff.In(loc).Format(time.RFC3339); got != value {
+			t.Errorf("%s read back in its venue zone is %s", value, got)
+		}
+	}
+}
+
+func TestLoadLocation(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset int // Seconds east of UTC on 1 March 2025
+		ok     bool
+	}{
+		{"", 0, true},
+		{"utc", 0, true},
+		{"Europe/London", 0, true},
+		{"Pacific/Auckland", 13 * 3600, true},
+		{"Etc/GMT-2", 2 * 3600, true},
+		{"+05:30", 5*3600 + 1800, true},
+		{"-03:30", -(3*3600 + 1800), true},
+		{"Mars/Olympus_Mons", 0, false},
+		{"+5:30", 0, false},
+	}
+	for _, tt := range tests {
+		loc, err := LoadLocation(tt.name)
+		if (err == nil) != tt.ok {
+			t.Errorf("LoadLocation(%q) error = %v, want ok %v", tt.name, err, tt.ok)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		_, offset := time.Date(2025, 3, 1, 12, 0, 0, 0, loc).Zone()
+		if offset != tt.offset {
+			t.Errorf("LoadLocation(%q) has offset %d, want %d", tt.name, offset, tt.offset)
+		}
+	}
+}
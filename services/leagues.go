@@ -1,22 +1,23 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"rugby-live-api/db"
 	"time"
 )
 
-func (a *APIClient) GetLeagues(store *db.Store) error {
+func (a *APIClient) GetLeagues(ctx context.Context, store *db.Store) error {
 	// Get current year leagues from RugbyDB
 	currentYear := time.Now().Year()
-	rugbyDBLeagues, err := a.GetLeaguesByYear(store, fmt.Sprintf("%d", currentYear), false)
+	rugbyDBLeagues, err := a.GetLeaguesByYear(ctx, store, fmt.Sprintf("%d", currentYear), false, db.NewIngestionReport())
 	if err != nil {
 		return fmt.Errorf("failed to get RugbyDB leagues: %v", err)
 	}
 
 	// Store the leagues
 	for _, league := range rugbyDBLeagues {
-		if err := store.UpsertLeague(&league); err != nil {
+		if err := store.UpsertLeague(ctx, &league); err != nil {
 			return fmt.Errorf("failed to store league %s: %v", league.Name, err)
 		}
 	}
@@ -0,0 +1,375 @@
+// Package live pushes match changes to WebSocket clients. A client sends
+// subscribe and unsubscribe messages naming match, league and team IDs; it is
+// sent a snapshot of the matching matches straight away and a delta for every
+// later score, status or team change that concerns them.
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"rugby-live-api/db"
+	"rugby-live-api/events"
+	"rugby-live-api/models"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// SnapshotWindow bounds the matches a league or team subscription's
+	// snapshot includes: those kicking off this long either side of now.
+	SnapshotWindow = 24 * time.Hour
+
+	pingInterval = 30 * time.Second
+	pongWait     = 2 * pingInterval
+	writeWait    = 10 * time.Second
+	maxMessage   = 4 << 10
+
+	// sendBuffer is how many messages may wait for a client. A client that
+	// falls this far behind is disconnected and should reconnect and
+	// resubscribe for a fresh snapshot.
+	sendBuffer = 64
+)
+
+// Client message actions.
+const (
+	ActionSubscribe   = "subscribe"
+	ActionUnsubscribe = "unsubscribe"
+)
+
+// ClientMessage is sent by a client to change what it hears about. League
+// IDs cover the league's seasons as well.
+type ClientMessage struct {
+	Action    string   `json:"action"`
+	MatchIDs  []string `json:"match_ids,omitempty"`
+	LeagueIDs []string `json:"league_ids,omitempty"`
+	TeamIDs   []string `json:"team_ids,omitempty"`
+}
+
+// Server message types.
+const (
+	TypeSnapshot = "snapshot"
+	TypeDelta    = "delta"
+	TypeError    = "error"
+)
+
+// ServerMessage is sent to clients. A snapshot lists Matches; a delta names
+// the Event and carries the Match and what it showed before, or the Team for
+// team.updated.
+type ServerMessage struct {
+	Type     string             `json:"type"`
+	Event    string             `json:"event,omitempty"`
+	Matches  []models.Match     `json:"matches,omitempty"`
+	Match    *models.Match      `json:"match,omitempty"`
+	Previous *events.MatchState `json:"previous,omitempty"`
+	Team     *models.Team       `json:"team,omitempty"`
+	Error    string             `json:"error,omitempty"`
+}
+
+// Hub tracks connected clients and fans events out to them.
+type Hub struct {
+	store    *db.Store
+	log      *slog.Logger
+	upgrader websocket.Upgrader
+
+	mu      sync.RWMutex
+	clients map[*client]struct{}
+}
+
+func NewHub(store *db.Store) *Hub {
+	return &Hub{
+		store: store,
+		log:   store.Logger(),
+		upgrader: websocket.Upgrader{
+			// The feed is public and read-only, so second-screen apps may
+			// connect from any origin.
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+		clients: make(map[*client]struct{}),
+	}
+}
+
+// Handle sends e to every client subscribed to what it concerns. It is meant
+// to be passed to events.Subscribe and never blocks on a client.
+func (h *Hub) Handle(e events.Event) {
+	var msg ServerMessage
+	switch data := e.Data.(type) {
+	case events.MatchChange:
+		match := data.Match
+		msg = ServerMessage{Type: TypeDelta, Event: e.Type, Match: &match, Previous: data.Previous}
+	case models.Team:
+		team := data
+		msg = ServerMessage{Type: TypeDelta, Event: e.Type, Team: &team}
+	default:
+		return
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		h.log.Error("Error encoding live message", "event_type", e.Type, "error", err)
+		return
+	}
+
+	var slow []*client
+	h.mu.RLock()
+	for c := range h.clients {
+		if c.wants(msg) && !c.send(payload) {
+			slow = append(slow, c)
+		}
+	}
+	h.mu.RUnlock()
+	for _, c := range slow {
+		h.unregister(c)
+	}
+}
+
+func (h *Hub) unregister(c *client) {
+	h.mu.Lock()
+	delete(h.clients, c)
+	h.mu.Unlock()
+}
+
+// ServeWS upgrades the request to a WebSocket and serves the client until it
+// disconnects.
+func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already answered the request.
+		return
+	}
+	c := &client{
+		hub:     h,
+		conn:    conn,
+		out:     make(chan []byte, sendBuffer),
+		closed:  make(chan struct{}),
+		matches: make(map[string]bool),
+		leagues: make(map[string]bool),
+		teams:   make(map[string]bool),
+	}
+
+	h.mu.Lock()
+	h.clients[c] = struct{}{}
+	h.mu.Unlock()
+
+	go c.writeLoop()
+	c.readLoop(r.Context())
+
+	h.unregister(c)
+	c.close(websocket.CloseNormalClosure, "")
+}
+
+// Close disconnects every client, telling them the server is going away so
+// they reconnect elsewhere.
+func (h *Hub) Close() {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for c := range h.clients {
+		c.close(websocket.CloseGoingAway, "server shutting down")
+	}
+}
+
+// snapshot returns the current state of the matches msg subscribes to.
+// leagueKeys are the league and season IDs msg's leagues resolve to.
+func (h *Hub) snapshot(ctx context.Context, msg ClientMessage, leagueKeys []string) ([]models.Match, error) {
+	matches := []models.Match{}
+	seen := make(map[string]bool)
+	add := func(found []models.Match) {
+		for _, m := range found {
+			if !seen[m.ID] {
+				seen[m.ID] = true
+				matches = append(matches, m)
+			}
+		}
+	}
+
+	if len(msg.MatchIDs) > 0 {
+		found, err := h.store.GetMatchesByIDs(ctx, msg.MatchIDs)
+		if err != nil {
+			return nil, err
+		}
+		add(found)
+	}
+	if len(leagueKeys) > 0 || len(msg.TeamIDs) > 0 {
+		now := time.Now()
+		found, err := h.store.GetMatchesAround(ctx, leagueKeys, msg.TeamIDs, now.Add(-SnapshotWindow), now.Add(SnapshotWindow))
+		if err != nil {
+			return nil, err
+		}
+		add(found)
+	}
+	return matches, nil
+}
+
+// leagueKeys returns the league IDs along with their seasons' IDs, since the
+// matches.league_id column holds a season ID for most fixtures.
+func (h *Hub) leagueKeys(ctx context.Context, leagueIDs []string) ([]string, error) {
+	if len(leagueIDs) == 0 {
+		return nil, nil
+	}
+	seasons, err := h.store.GetSeasonsByLeagueIDs(ctx, leagueIDs)
+	if err != nil {
+		return nil, err
+	}
+	keys := append([]string{}, leagueIDs...)
+	for _, s := range seasons {
+		keys = append(keys, s.ID)
+	}
+	return keys, nil
+}
+
+type client struct {
+	hub  *Hub
+	conn *websocket.Conn
+	out  chan []byte
+
+	// closed tells writeLoop to send a close frame with closeCode and
+	// closeReason and shut the connection.
+	closeOnce   sync.Once
+	closed      chan struct{}
+	closeCode   int
+	closeReason string
+
+	mu      sync.RWMutex
+	matches map[string]bool
+	leagues map[string]bool // league and season IDs
+	teams   map[string]bool
+}
+
+func (c *client) wants(msg ServerMessage) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if m := msg.Match; m != nil {
+		return c.matches[m.ID] || c.leagues[m.LeagueID] || c.teams[m.HomeTeamID] || c.teams[m.AwayTeamID]
+	}
+	if t := msg.Team; t != nil {
+		return c.teams[t.ID]
+	}
+	return false
+}
+
+// send queues payload. It disconnects the client and returns false if its
+// buffer is full.
+func (c *client) send(payload []byte) bool {
+	select {
+	case c.out <- payload:
+		return true
+	default:
+		c.close(websocket.CloseTryAgainLater, "client too slow")
+		return false
+	}
+}
+
+func (c *client) sendMessage(msg ServerMessage) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		c.hub.log.Error("Error encoding live message", "type", msg.Type, "error", err)
+		return
+	}
+	c.send(payload)
+}
+
+// close has writeLoop send a close frame and shut the connection. It does no
+// I/O itself, so it is safe to call while holding the hub's lock; only the
+// first call's code and reason are sent.
+func (c *client) close(code int, reason string) {
+	c.closeOnce.Do(func() {
+		c.closeCode, c.closeReason = code, reason
+		close(c.closed)
+	})
+}
+
+// readLoop applies subscription messages until the connection fails or
+// stops answering pings. Lookups for a message run under ctx.
+func (c *client) readLoop(ctx context.Context) {
+	c.conn.SetReadLimit(maxMessage)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var msg ClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.sendMessage(ServerMessage{Type: TypeError, Error: "invalid message: " + err.Error()})
+			continue
+		}
+		c.apply(ctx, msg)
+	}
+}
+
+func (c *client) apply(ctx context.Context, msg ClientMessage) {
+	keys, err := c.hub.leagueKeys(ctx, msg.LeagueIDs)
+	if err != nil {
+		c.sendMessage(ServerMessage{Type: TypeError, Error: "failed to look up leagues"})
+		c.hub.log.Error("Error resolving live league subscription", "error", err)
+		return
+	}
+
+	switch msg.Action {
+	case ActionSubscribe:
+		c.mu.Lock()
+		for _, id := range msg.MatchIDs {
+			c.matches[id] = true
+		}
+		for _, id := range keys {
+			c.leagues[id] = true
+		}
+		for _, id := range msg.TeamIDs {
+			c.teams[id] = true
+		}
+		c.mu.Unlock()
+
+		matches, err := c.hub.snapshot(ctx, msg, keys)
+		if err != nil {
+			c.sendMessage(ServerMessage{Type: TypeError, Error: "failed to load snapshot"})
+			c.hub.log.Error("Error loading live snapshot", "error", err)
+			return
+		}
+		c.sendMessage(ServerMessage{Type: TypeSnapshot, Matches: matches})
+	case ActionUnsubscribe:
+		c.mu.Lock()
+		for _, id := range msg.MatchIDs {
+			delete(c.matches, id)
+		}
+		for _, id := range keys {
+			delete(c.leagues, id)
+		}
+		for _, id := range msg.TeamIDs {
+			delete(c.teams, id)
+		}
+		c.mu.Unlock()
+	default:
+		c.sendMessage(ServerMessage{Type: TypeError, Error: `action must be "subscribe" or "unsubscribe"`})
+	}
+}
+
+// writeLoop sends queued messages and pings until the connection closes.
+func (c *client) writeLoop() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case payload := <-c.out:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				c.conn.Close()
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				c.conn.Close()
+				return
+			}
+		case <-c.closed:
+			c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(c.closeCode, c.closeReason), time.Now().Add(writeWait))
+			c.conn.Close()
+			return
+		}
+	}
+}
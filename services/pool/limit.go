@@ -0,0 +1,131 @@
+package pool
+
+import (
+	"io"
+	"net/http"
+	"rugby-live-api/config"
+	"rugby-live-api/metrics"
+	"rugby-live-api/services/breaker"
+	"sync"
+	"time"
+)
+
+// NewHTTPClient returns a client for the provider p configures: its requests
+// time out after p.Timeout, are recorded by metrics.Transport and are held to
+// p's quota by Limit, behind the circuit breaker of p's host.
+func NewHTTPClient(p config.Provider) *http.Client {
+	return &http.Client{
+		Timeout:   p.Timeout,
+		Transport: NewTransport(p, metrics.Transport("", nil)),
+	}
+}
+
+// NewTransport wraps next in p's quota and circuit breaker. The breaker comes
+// first, so requests it refuses don't wait for a place.
+func NewTransport(p config.Provider, next http.RoundTripper) http.RoundTripper {
+	return breaker.Transport(Limit(next, p.Concurrency, p.RateLimit), breaker.Settings{
+		Threshold: p.BreakerThreshold,
+		Cooldown:  p.BreakerCooldown,
+	})
+}
+
+// Limit returns a RoundTripper that lets at most concurrency requests to each
+// host through next at once, and starts at most rate of them per second. A
+// zero rate leaves the rate unlimited. A request holds its place until its
+// response body is closed, and stops waiting for one when its context is
+// done.
+func Limit(next http.RoundTripper, concurrency int, rate float64) http.RoundTripper {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var interval time.Duration
+	if rate > 0 {
+		interval = time.Duration(float64(time.Second) / rate)
+	}
+	return &limiter{next: next, concurrency: concurrency, interval: interval, hosts: make(map[string]*hostLimit)}
+}
+
+type limiter struct {
+	next        http.RoundTripper
+	concurrency int
+	interval    time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostLimit
+}
+
+// hostLimit is the quota for one host.
+type hostLimit struct {
+	slots chan struct{}
+
+	mu        sync.Mutex
+	nextStart time.Time
+}
+
+func (l *limiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	h := l.host(req.URL.Host)
+	ctx := req.Context()
+	if err := acquire(ctx, h.slots); err != nil {
+		return nil, err
+	}
+	release := sync.OnceFunc(func() { <-h.slots })
+
+	if delay := h.reserve(l.interval); delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			release()
+			return nil, ctx.Err()
+		}
+	}
+
+	resp, err := l.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+func (l *limiter) host(name string) *hostLimit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	h, ok := l.hosts[name]
+	if !ok {
+		h = &hostLimit{slots: make(chan struct{}, l.concurrency)}
+		l.hosts[name] = h
+	}
+	return h
+}
+
+// reserve books the next start time interval after the last one, and
+// returns how long until it comes round.
+func (h *hostLimit) reserve(interval time.Duration) time.Duration {
+	if interval == 0 {
+		return 0
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	start := h.nextStart
+	if start.Before(now) {
+		start = now
+	}
+	h.nextStart = start.Add(interval)
+	return start.Sub(now)
+}
+
+// releasingBody gives up its request's place once closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
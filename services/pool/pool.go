@@ -0,0 +1,58 @@
+// Package pool runs provider fetches that fan out over many items, such as
+// every country's teams, concurrently while keeping each provider host within
+// its quota.
+package pool
+
+import (
+	"context"
+	"sync"
+)
+
+// Result is the outcome of calling Map's fn on one item.
+type Result[R any] struct {
+	Value R
+	Err   error
+}
+
+// Map calls fn on each item, with up to workers calls in flight, and returns
+// the results in the order of items. One item failing does not stop the
+// others. Once ctx is done, items not yet started fail with its error.
+func Map[T, R any](ctx context.Context, items []T, workers int, fn func(context.Context, T) (R, error)) []Result[R] {
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]Result[R], len(items))
+	slots := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		if err := acquire(ctx, slots); err != nil {
+			for j := i; j < len(items); j++ {
+				results[j].Err = err
+			}
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			results[i].Value, results[i].Err = fn(ctx, item)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// acquire takes one of slots, or returns ctx's error if it is done first.
+func acquire(ctx context.Context, slots chan struct{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -2,20 +2,24 @@ package rapidapi
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
-	"os"
+	"rugby-live-api/config"
 	"rugby-live-api/db"
+	"rugby-live-api/metrics"
 	"rugby-live-api/models"
+	"rugby-live-api/services/retry"
 	"time"
 )
 
 type Client struct {
-	client *http.Client
-	apiKey string
+	client *retry.Client
+	cfg    config.Provider
+	log    *slog.Logger
 }
 
 // Add this map at package level
@@ -75,21 +79,34 @@ var defaultLeagues = map[string]models.League{
 	},
 }
 
-func NewClient() *Client {
+func NewClient(cfg config.Provider, logger *slog.Logger) *Client {
 	return &Client{
-		client: &http.Client{},
-		apiKey: os.Getenv("RAPID_API_KEY"),
+		client: retry.NewProviderClient(cfg),
+		cfg:    cfg,
+		log:    logger,
 	}
 }
 
-func (c *Client) GetCompetitions() ([]models.RapidAPICompetition, error) {
-	req, err := http.NewRequest("GET", "https://rugby-live-data.p.rapidapi.com/competitions", nil)
+// newRequest builds a GET of path on rugby-live-data, authenticated with the
+// configured key.
+func (c *Client) newRequest(ctx context.Context, path string) (*http.Request, error) {
+	if err := c.cfg.Require("rapid_api"); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", c.cfg.BaseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("X-RapidAPI-Host", req.URL.Host)
+	req.Header.Add("X-RapidAPI-Key", c.cfg.APIKey)
+	return req, nil
+}
+
+func (c *Client) GetCompetitions(ctx context.Context) ([]models.RapidAPICompetition, error) {
+	req, err := c.newRequest(ctx, "/competitions")
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("RapidAPI Key: %s", c.apiKey)
-	req.Header.Add("X-RapidAPI-Host", "rugby-live-data.p.rapidapi.com")
-	req.Header.Add("X-RapidAPI-Key", c.apiKey)
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -97,11 +114,11 @@ func (c *Client) GetCompetitions() ([]models.RapidAPICompetition, error) {
 	}
 	defer resp.Body.Close()
 
-	// Read and log the raw response
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %v", err)
 	}
+	c.log.Debug("Fetched RapidAPI competitions", "status", resp.StatusCode, "bytes", len(body))
 
 	// Create new reader for JSON decoding
 	var result struct {
@@ -114,8 +131,11 @@ func (c *Client) GetCompetitions() ([]models.RapidAPICompetition, error) {
 	return result.Results, nil
 }
 
-func (c *Client) MapCompetitionsToLeagues(store *db.Store) ([]CompetitionMapping, error) {
-	competitions, err := c.GetCompetitions()
+// MapCompetitionsToLeagues matches rugby-live-data's competitions to stored
+// leagues and stores each matched league's seasons and mappings, recording
+// every league in report.
+func (c *Client) MapCompetitionsToLeagues(ctx context.Context, store *db.Store, report *db.IngestionReport) ([]CompetitionMapping, error) {
+	competitions, err := c.GetCompetitions(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -136,7 +156,7 @@ func (c *Client) MapCompetitionsToLeagues(store *db.Store) ([]CompetitionMapping
 			UpdatedAt:    time.Now(),
 		}
 
-		league, err := store.GetLeagueByName(cleanName)
+		league, err := store.GetLeagueByName(ctx, cleanName)
 		if err == nil {
 			season.LeagueID = league.ID
 
@@ -165,18 +185,19 @@ func (c *Client) MapCompetitionsToLeagues(store *db.Store) ([]CompetitionMapping
 
 	// Create mappings
 	var mappings []CompetitionMapping
+	matched := 0
 	for name, group := range compsByName {
-		league, err := store.GetLeagueByName(name)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		league, err := store.GetLeagueByName(ctx, name)
+		createLeague := false
 		if err != nil {
 			// Check if we should auto-create this league
 			if defaultLeague, exists := defaultLeagues[name]; exists {
-				if err := store.UpsertLeague(&defaultLeague); err != nil {
-					log.Printf("Error creating default league %s: %v", name, err)
-				} else {
-					league = &defaultLeague
-					err = nil // Clear the error since we created the league
-					log.Printf("Created default league: %s", name)
-				}
+				league = &defaultLeague
+				createLeague = true
+				err = nil // Clear the error since the league will be created
 			}
 		}
 		mapping := CompetitionMapping{
@@ -186,68 +207,79 @@ func (c *Client) MapCompetitionsToLeagues(store *db.Store) ([]CompetitionMapping
 			Reason:           "no_match",
 		}
 
-		// Only process database operations if we have a matching league
+		// Only process database operations if we have a matching league. The
+		// league, its seasons and their mappings are stored as one unit.
 		if err == nil {
 			mapping.Reason = "direct_match"
-			log.Printf("Found matching league: %s (ID: %s)", name, league.ID)
+			c.log.Debug("Found matching league", "league", name, "league_id", league.ID)
 
-			// Add/update league API mapping
-			leagueMapping := &models.APIMapping{
-				APIName:    "rapid_api",
-				APIID:      fmt.Sprintf("%d", group.RapidAPIID),
-				EntityID:   league.ID,
-				EntityType: "league",
-				IsActive:   activeLeagues[name],
-			}
-			if err := store.UpsertRapidAPIMapping(leagueMapping); err != nil {
-				log.Printf("Error upserting league mapping: %v", err)
-			} else {
-				log.Printf("Successfully upserted league mapping: %s -> %s", leagueMapping.APIID, leagueMapping.EntityID)
-			}
-			mapping.APIMappings = append(mapping.APIMappings, leagueMapping)
-
-			// Process seasons for matched leagues only
-			for _, season := range group.Seasons {
-				log.Printf("Processing season %d for league %s", season.Year, name)
-
-				var seasonID string
-				// First check and create season if needed
-				existingSeason, err := store.GetSeasonByYear(league.ID, season.Year)
-				if err != nil {
-					log.Printf("Season %d not found, creating new season", season.Year)
-					if err := store.UpsertSeason(&season); err != nil {
-						log.Printf("Error upserting season: %v", err)
-						continue
+			err := store.Unit(ctx, report, db.UnitLeague, fmt.Sprintf("%d", group.RapidAPIID), func(tx *db.Store) error {
+				mapping.APIMappings = nil
+				if createLeague {
+					if err := tx.UpsertLeague(ctx, league); err != nil {
+						return db.Step("league", err)
 					}
-					log.Printf("Successfully created season: %s (Year: %d, RapidAPIYear: %d)",
-						season.ID, season.Year, season.RapidAPIYear)
-					seasonID = season.ID
-				} else {
-					log.Printf("Season %d already exists", season.Year)
-					seasonID = existingSeason.ID
 				}
 
-				// Now create/update the season API mapping using the correct season ID
-				seasonMapping := &models.APIMapping{
+				// Add/update league API mapping
+				leagueMapping := &models.APIMapping{
 					APIName:    "rapid_api",
-					APIID:      fmt.Sprintf("%d-%d", group.RapidAPIID, season.RapidAPIYear),
-					EntityID:   seasonID,
-					EntityType: "league_season",
+					APIID:      fmt.Sprintf("%d", group.RapidAPIID),
+					EntityID:   league.ID,
+					EntityType: "league",
 					IsActive:   activeLeagues[name],
 				}
+				if err := tx.UpsertRapidAPIMapping(ctx, leagueMapping); err != nil {
+					return db.Step("league mapping", err)
+				}
+				mapping.APIMappings = append(mapping.APIMappings, leagueMapping)
+
+				// Process seasons for matched leagues only
+				for _, season := range group.Seasons {
+					c.log.Debug("Processing season", "league", name, "year", season.Year)
 
-				log.Printf("Creating mapping for season: %+v", seasonMapping)
-				if err := store.UpsertRapidAPIMapping(seasonMapping); err != nil {
-					log.Printf("Error upserting season mapping: %v", err)
-				} else {
-					log.Printf("Successfully upserted season mapping: %s -> %s (ID: %s)",
-						seasonMapping.APIID, seasonMapping.EntityID, seasonID)
+					var seasonID string
+					// First check and create season if needed
+					existingSeason, err := tx.GetSeasonByYear(ctx, league.ID, season.Year)
+					if err != nil {
+						if err := tx.UpsertSeason(ctx, &season); err != nil {
+							return db.Step(fmt.Sprintf("season %d", season.Year), err)
+						}
+						seasonID = season.ID
+					} else {
+						seasonID = existingSeason.ID
+					}
+
+					// Now create/update the season API mapping using the correct season ID
+					seasonMapping := &models.APIMapping{
+						APIName:    "rapid_api",
+						APIID:      fmt.Sprintf("%d-%d", group.RapidAPIID, season.RapidAPIYear),
+						EntityID:   seasonID,
+						EntityType: "league_season",
+						IsActive:   activeLeagues[name],
+					}
+					if err := tx.UpsertRapidAPIMapping(ctx, seasonMapping); err != nil {
+						return db.Step(fmt.Sprintf("season %d mapping", season.Year), err)
+					}
+					mapping.APIMappings = append(mapping.APIMappings, seasonMapping)
 				}
-				mapping.APIMappings = append(mapping.APIMappings, seasonMapping)
+				return nil
+			})
+			if err != nil {
+				mapping.Matched = false
+				mapping.Reason = "store_failed"
+				mapping.Error = err.Error()
+				mapping.APIMappings = nil
+			} else if createLeague {
+				c.log.Info("Created default league", "league", name)
 			}
 		}
 		mappings = append(mappings, mapping)
+		if mapping.Matched {
+			matched++
+		}
 	}
+	metrics.ObserveMapping("rapidapi_competitions", matched, len(mappings))
 
 	return mappings, nil
 }
@@ -0,0 +1,274 @@
+package rapidapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"rugby-live-api/db"
+	"rugby-live-api/models"
+	"rugby-live-api/services/kickoff"
+	"rugby-live-api/services/reconcile"
+	"strconv"
+	"strings"
+)
+
+// get fetches path from rugby-live-data and decodes its results array into out.
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	req, err := c.newRequest(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("rugby-live-data returned status %d for %s", resp.StatusCode, path)
+	}
+
+	result := struct {
+		Results interface{} `json:"results"`
+	}{Results: out}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
+	}
+	return nil
+}
+
+// GetFixtures returns fixtures and results for params.CompetitionID and
+// params.Season, or every fixture on params.Date (YYYY-MM-DD) when it is set.
+func (c *Client) GetFixtures(ctx context.Context, params APIParams) ([]Fixture, error) {
+	path := fmt.Sprintf("/fixtures/%s/%s", url.PathEscape(params.CompetitionID), url.PathEscape(params.Season))
+	if params.Date != "" {
+		path = "/fixtures-by-date/" + url.PathEscape(params.Date)
+	}
+	var fixtures []Fixture
+	return fixtures, c.get(ctx, path, &fixtures)
+}
+
+// GetLiveScores returns every match currently in play.
+func (c *Client) GetLiveScores(ctx context.Context) ([]Fixture, error) {
+	var fixtures []Fixture
+	return fixtures, c.get(ctx, "/livescores", &fixtures)
+}
+
+// activeSeason is a league_season mapping that is polled for fixtures.
+type activeSeason struct {
+	params   APIParams
+	seasonID string
+}
+
+// SyncMatches pulls fixtures, results and, when live is set, live scores for
+// every league season whose rapid_api mapping is active, and merges them into
+// matches. Match IDs follow the API-Sports scheme, so a match both providers
+// report is stored once.
+func (c *Client) SyncMatches(ctx context.Context, store *db.Store, live bool) (*SyncResult, error) {
+	mappings, err := store.GetAPIMappingsByType(ctx, "rapid_api", "league_season")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get season mappings: %v", err)
+	}
+
+	var seasons []activeSeason
+	latest := make(map[string]activeSeason) // competition ID -> most recent season
+	for _, m := range mappings {
+		if !m.IsActive {
+			continue
+		}
+		// Season mappings are keyed "<competition ID>-<RapidAPI year>".
+		i := strings.LastIndex(m.APIID, "-")
+		if i < 0 {
+			c.log.Warn("Skipping malformed rapid_api season mapping", "api_id", m.APIID)
+			continue
+		}
+		s := activeSeason{
+			params:   APIParams{CompetitionID: m.APIID[:i], Season: m.APIID[i+1:]},
+			seasonID: m.EntityID,
+		}
+		seasons = append(seasons, s)
+		if prev, ok := latest[s.params.CompetitionID]; !ok || s.params.Season > prev.params.Season {
+			latest[s.params.CompetitionID] = s
+		}
+	}
+
+	s := &syncer{store: store, log: c.log, result: &SyncResult{Seasons: []string{}, Matches: []models.Match{}, Skipped: []SkippedFixture{}}}
+	if live {
+		fixtures, err := c.GetLiveScores(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get live scores: %v", err)
+		}
+		polled := make(map[string]bool)
+		for _, f := range fixtures {
+			season, ok := latest[strconv.Itoa(f.CompID)]
+			if !ok {
+				continue
+			}
+			if !polled[season.seasonID] {
+				s.result.Seasons = append(s.result.Seasons, season.seasonID)
+				polled[season.seasonID] = true
+			}
+			if err := s.merge(ctx, season.seasonID, f); err != nil {
+				return s.result, err
+			}
+		}
+	} else {
+		for _, season := range seasons {
+			fixtures, err := c.GetFixtures(ctx, season.params)
+			if err != nil {
+				return s.result, fmt.Errorf("failed to get fixtures for season %s: %v", season.seasonID, err)
+			}
+			s.result.Seasons = append(s.result.Seasons, season.seasonID)
+			for _, f := range fixtures {
+				if err := s.merge(ctx, season.seasonID, f); err != nil {
+					return s.result, err
+				}
+			}
+		}
+	}
+
+	for date, ids := range s.byDate {
+		if err := store.UpsertDailyMatches(ctx, date, ids); err != nil {
+			c.log.Error("Error updating daily matches", "date", date, "error", err)
+		}
+	}
+	return s.result, nil
+}
+
+// syncer merges fixtures into the store, caching what it has looked up.
+type syncer struct {
+	store     *db.Store
+	log       *slog.Logger
+	result    *SyncResult
+	teams     map[string]string // rugby-live-data team ID -> team ID
+	countries map[string]string // season ID -> league country code
+	byDate    map[string][]string
+}
+
+func (s *syncer) merge(ctx context.Context, seasonID string, f Fixture) error {
+	skip := func(reason string) {
+		s.result.Skipped = append(s.result.Skipped, SkippedFixture{SeasonID: seasonID, Fixture: f, Reason: reason})
+	}
+
+	homeID, err := s.team(ctx, f.HomeID, f.Home)
+	if err != nil {
+		skip(err.Error())
+		return nil
+	}
+	awayID, err := s.team(ctx, f.AwayID, f.Away)
+	if err != nil {
+		skip(err.Error())
+		return nil
+	}
+	kickOff, err := kickoff.Parse(f.Date, "")
+	if err != nil {
+		skip(err.Error())
+		return nil
+	}
+	country, err := s.country(ctx, seasonID)
+	if err != nil {
+		return err
+	}
+
+	match := models.Match{
+		ID:            fmt.Sprintf("%s-%s-%s-%s", seasonID, homeID, awayID, kickOff.Format("20060102")),
+		HomeTeamID:    homeID,
+		AwayTeamID:    awayID,
+		LeagueID:      seasonID,
+		Status:        f.MatchStatus(),
+		KickOff:       kickOff,
+		Date:          kickOff.Format("2006-01-02"),
+		Time:          kickOff.Format("15:04"),
+		VenueTimezone: kickoff.VenueTimezone(country, f.Date),
+		Venue:         f.Venue,
+	}
+	if f.HomeScore != nil {
+		match.HomeScore = *f.HomeScore
+	}
+	if f.AwayScore != nil {
+		match.AwayScore = *f.AwayScore
+	}
+
+	if err := reconcile.Record(ctx, s.store, reconcile.SourceRapidAPI, &match); err != nil {
+		return fmt.Errorf("failed to upsert match %s: %v", match.ID, err)
+	}
+	if err := s.store.UpsertAPIMapping(ctx, &models.APIMapping{
+		EntityID:   match.ID,
+		APIName:    "rapid_api",
+		APIID:      strconv.Itoa(f.ID),
+		EntityType: "match",
+	}); err != nil {
+		s.log.Error("Error creating API mapping for match", "match_id", match.ID, "error", err)
+	}
+
+	if s.byDate == nil {
+		s.byDate = make(map[string][]string)
+	}
+	s.byDate[match.Date] = append(s.byDate[match.Date], match.ID)
+	s.result.Matches = append(s.result.Matches, match)
+	return nil
+}
+
+// team resolves a rugby-live-data team through its rapid_api mapping, falling
+// back to a unique match on name or alternate name, which is then mapped so
+// later syncs skip the name lookup.
+func (s *syncer) team(ctx context.Context, apiID int, name string) (string, error) {
+	if s.teams == nil {
+		mappings, err := s.store.GetAPIMappingsByType(ctx, "rapid_api", "team")
+		if err != nil {
+			return "", fmt.Errorf("failed to get team mappings: %v", err)
+		}
+		s.teams = make(map[string]string)
+		for _, m := range mappings {
+			s.teams[m.APIID] = m.EntityID
+		}
+	}
+
+	key := strconv.Itoa(apiID)
+	if id, ok := s.teams[key]; ok {
+		return id, nil
+	}
+
+	teams, err := s.store.FindTeamsByName(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up team %q: %v", name, err)
+	}
+	if len(teams) != 1 {
+		return "", fmt.Errorf("%d teams named %q", len(teams), name)
+	}
+	if err := s.store.UpsertAPIMapping(ctx, &models.APIMapping{
+		EntityID:   teams[0].ID,
+		APIName:    "rapid_api",
+		APIID:      key,
+		EntityType: "team",
+	}); err != nil {
+		s.log.Error("Error creating API mapping for team", "team_id", teams[0].ID, "error", err)
+	}
+	s.teams[key] = teams[0].ID
+	return teams[0].ID, nil
+}
+
+// country returns the country code of the season's league, which decides the
+// venue timezone.
+func (s *syncer) country(ctx context.Context, seasonID string) (string, error) {
+	if code, ok := s.countries[seasonID]; ok {
+		return code, nil
+	}
+	if s.countries == nil {
+		s.countries = make(map[string]string)
+	}
+
+	season, err := s.store.GetSeasonByID(ctx, seasonID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get season %s: %v", seasonID, err)
+	}
+	league, err := s.store.GetLeagueByID(ctx, season.LeagueID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get league %s: %v", season.LeagueID, err)
+	}
+	s.countries[seasonID] = league.Country.Code
+	return league.Country.Code, nil
+}
@@ -1,9 +1,11 @@
 package rapidapi
 
 import (
+	"log/slog"
 	"rugby-live-api/models"
 )
 
+// APIParams selects fixtures: a competition and season, or a single date.
 type APIParams struct {
 	CompetitionID string
 	Season        string
@@ -35,4 +37,46 @@ type CompetitionMapping struct {
 	Matched          bool                 `json:"matched"`
 	Reason           string               `json:"reason"`
 	APIMappings      []*models.APIMapping `json:"api_mappings,omitempty"`
+	Error            string               `json:"error,omitempty"`
+}
+
+// Fixture is a match as reported by rugby-live-data. Scores are null until
+// the match kicks off.
+type Fixture struct {
+	ID        int    `json:"id"`
+	CompID    int    `json:"comp_id"`
+	Date      string `json:"date"`
+	HomeID    int    `json:"home_id"`
+	Home      string `json:"home"`
+	HomeScore *int   `json:"home_score"`
+	AwayID    int    `json:"away_id"`
+	Away      string `json:"away"`
+	AwayScore *int   `json:"away_score"`
+	Status    string `json:"status"`
+	Venue     string `json:"venue"`
+}
+
+// MatchStatus maps the feed's status onto the stored status. Unknown
+// statuses are logged and, since the feed lists them among live scores,
+// treated as in play.
+func (f Fixture) MatchStatus() models.MatchStatus {
+	if status, ok := models.ProviderStatus("rapid_api", f.Status); ok {
+		return status
+	}
+	slog.Warn("Unknown rugby-live-data status", "status", f.Status, "fixture_id", f.ID)
+	return models.StatusFirstHalf
+}
+
+// SkippedFixture is a fixture that could not be merged, usually because a
+// team did not resolve.
+type SkippedFixture struct {
+	SeasonID string  `json:"season_id"`
+	Fixture  Fixture `json:"fixture"`
+	Reason   string  `json:"reason"`
+}
+
+type SyncResult struct {
+	Seasons []string         `json:"seasons"`
+	Matches []models.Match   `json:"matches"`
+	Skipped []SkippedFixture `json:"skipped"`
 }
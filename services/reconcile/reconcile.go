@@ -0,0 +1,434 @@
+// Package reconcile decides whose score and status a match shows when more
+// than one provider reports it. Every provider's latest report is kept; the
+// winner is picked by source priority and freshness, and matches whose
+// providers disagree on a settled result are flagged for review.
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"rugby-live-api/config"
+	"rugby-live-api/db"
+	"rugby-live-api/events"
+	"rugby-live-api/models"
+	"slices"
+	"time"
+)
+
+// Sources that report match scores.
+const (
+	SourceAPISports = "api_sports"
+	SourceRapidAPI  = "rapid_api"
+	SourceRugbyDB   = "rugbydatabase"
+	SourceImport    = "import"
+
+	// SourceOverride marks a score entered by hand.
+	SourceOverride = "override"
+)
+
+// Policy ranks providers. Priority lists sources most trusted first; sources
+// not listed rank below all of them. An unsettled report (scheduled or in
+// play) more than Freshness older than the newest report for the match is ignored
+// when picking a winner, so a stale feed cannot hold back a live score.
+// Settled results are never stale. Zero Freshness makes priority absolute.
+type Policy struct {
+	Priority  []string
+	Freshness time.Duration
+}
+
+var DefaultPolicy = Policy{
+	Priority:  []string{SourceAPISports, SourceRapidAPI, SourceRugbyDB, SourceImport},
+	Freshness: 10 * time.Minute,
+}
+
+var policy = DefaultPolicy
+
+// Configure sets the policy used by Record and the override functions. Call
+// it once at startup.
+func Configure(p Policy) {
+	policy = p
+}
+
+// PolicyFromConfig returns the policy cfg describes, keeping DefaultPolicy's
+// source order when cfg gives none.
+func PolicyFromConfig(cfg config.Score) Policy {
+	p := Policy{Priority: DefaultPolicy.Priority, Freshness: cfg.Freshness}
+	if len(cfg.SourcePriority) > 0 {
+		p.Priority = cfg.SourcePriority
+	}
+	return p
+}
+
+// Result is the score and status a match should show.
+type Result struct {
+	HomeScore int
+	AwayScore int
+	Status    models.MatchStatus
+	Source    string
+	Conflict  bool
+}
+
+// Decide picks the winning report, or the override when there is one.
+func (p Policy) Decide(reports []models.SourceReport, override *models.ScoreOverride) Result {
+	result := Result{Conflict: conflicting(reports)}
+	if override != nil {
+		result.HomeScore, result.AwayScore, result.Status = override.HomeScore, override.AwayScore, override.Status
+		result.Source = SourceOverride
+		return result
+	}
+
+	var newest time.Time
+	for _, r := range reports {
+		if r.ReportedAt.After(newest) {
+			newest = r.ReportedAt
+		}
+	}
+
+	var winner *models.SourceReport
+	for i := range reports {
+		r := &reports[i]
+		if p.Freshness > 0 && !r.Status.Settled() && newest.Sub(r.ReportedAt) > p.Freshness {
+			continue
+		}
+		if winner == nil || p.rank(r.Source) < p.rank(winner.Source) ||
+			(p.rank(r.Source) == p.rank(winner.Source) && r.ReportedAt.After(winner.ReportedAt)) {
+			winner = r
+		}
+	}
+	if winner != nil {
+		result.HomeScore, result.AwayScore, result.Status = winner.HomeScore, winner.AwayScore, winner.Status
+		result.Source = winner.Source
+	}
+	return result
+}
+
+func (p Policy) rank(source string) int {
+	for i, s := range p.Priority {
+		if s == source {
+			return i
+		}
+	}
+	return len(p.Priority)
+}
+
+// conflicting reports whether two providers disagree on a settled result.
+// Providers poll at different times, so scores of matches still in play are
+// expected to differ and are not conflicts.
+func conflicting(reports []models.SourceReport) bool {
+	var first *models.SourceReport
+	for i := range reports {
+		r := &reports[i]
+		if !r.Status.Settled() {
+			continue
+		}
+		if first == nil {
+			first = r
+			continue
+		}
+		if r.Status != first.Status || r.HomeScore != first.HomeScore || r.AwayScore != first.AwayScore {
+			return true
+		}
+	}
+	return false
+}
+
+// Record stores source's report of match and upserts the match with the
+// reconciled score and status. match is updated to what was stored. A status
+// change the match's lifecycle does not allow, such as finished back to in
+// play, is not applied; the report is still kept and the score still moves.
+func Record(ctx context.Context, store *db.Store, source string, match *models.Match) error {
+	report := newReport(source, match)
+
+	return store.WithTx(ctx, func(tx *db.Store) error {
+		// Lock the match before reading its reports, so a concurrent report
+		// from another provider is reconciled after this one, not beside it.
+		stored, err := tx.LockMatch(ctx, match.ID)
+		if err != nil {
+			return err
+		}
+		reports, override, err := load(ctx, tx, match.ID)
+		if err != nil {
+			return err
+		}
+
+		change, matchEvents := apply(tx.Logger(), match, stored, reports, override, report)
+		if err := tx.UpsertMatch(ctx, match); err != nil {
+			return err
+		}
+		if err := tx.UpsertSourceReport(ctx, &report); err != nil {
+			return fmt.Errorf("failed to store %s report: %v", source, err)
+		}
+		if change != nil {
+			if err := tx.InsertStatusChange(ctx, change); err != nil {
+				return fmt.Errorf("failed to record status change for %s: %v", match.ID, err)
+			}
+		}
+		for _, e := range matchEvents {
+			tx.Publish(e)
+		}
+		return nil
+	})
+}
+
+// RecordAll records source's reports of matches as Record would, in one
+// transaction and with a few statements for the lot rather than several per
+// match, for backfills. Every match is locked until the transaction ends.
+// When a match appears more than once, the last report of it is recorded.
+func RecordAll(ctx context.Context, store *db.Store, source string, matches []*models.Match) error {
+	last := make(map[string]int, len(matches))
+	for i, match := range matches {
+		last[match.ID] = i
+	}
+	var ids []string
+	var recorded []*models.Match
+	for i, match := range matches {
+		if last[match.ID] == i {
+			ids = append(ids, match.ID)
+			recorded = append(recorded, match)
+		}
+	}
+	if len(recorded) == 0 {
+		return nil
+	}
+
+	return store.WithTx(ctx, func(tx *db.Store) error {
+		stored, err := tx.LockMatches(ctx, ids)
+		if err != nil {
+			return err
+		}
+		allReports, err := tx.GetSourceReportsByMatchIDs(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("failed to load reports: %v", err)
+		}
+		overrides, err := tx.GetScoreOverridesByMatchIDs(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("failed to load overrides: %v", err)
+		}
+
+		reports := make([]*models.SourceReport, len(recorded))
+		var changes []*models.StatusChange
+		var pending []events.Event
+		for i, match := range recorded {
+			report := newReport(source, match)
+			reports[i] = &report
+			var previous *models.Match
+			if m, ok := stored[match.ID]; ok {
+				previous = &m
+			}
+			var override *models.ScoreOverride
+			if o, ok := overrides[match.ID]; ok {
+				override = &o
+			}
+			change, matchEvents := apply(tx.Logger(), match, previous, allReports[match.ID], override, report)
+			if change != nil {
+				changes = append(changes, change)
+			}
+			pending = append(pending, matchEvents...)
+		}
+
+		if err := tx.UpsertMatches(ctx, recorded); err != nil {
+			return err
+		}
+		if err := tx.UpsertSourceReports(ctx, reports); err != nil {
+			return fmt.Errorf("failed to store %s reports: %v", source, err)
+		}
+		if err := tx.InsertStatusChanges(ctx, changes); err != nil {
+			return fmt.Errorf("failed to record status changes: %v", err)
+		}
+		for _, e := range pending {
+			tx.Publish(e)
+		}
+		return nil
+	})
+}
+
+func newReport(source string, match *models.Match) models.SourceReport {
+	return models.SourceReport{
+		MatchID:    match.ID,
+		Source:     source,
+		HomeScore:  match.HomeScore,
+		AwayScore:  match.AwayScore,
+		Status:     match.Status,
+		ReportedAt: time.Now().UTC(),
+	}
+}
+
+// apply reconciles match with report in place of its source's earlier one,
+// setting match to what should be stored. stored is the match as stored, or
+// nil if it is new. It returns the status change to record, if any, and the
+// events to publish once the match is written.
+func apply(log *slog.Logger, match, stored *models.Match, reports []models.SourceReport, override *models.ScoreOverride, report models.SourceReport) (*models.StatusChange, []events.Event) {
+	reports = slices.Clone(reports)
+	replaced := false
+	for i := range reports {
+		if reports[i].Source == report.Source {
+			reports[i] = report
+			replaced = true
+		}
+	}
+	if !replaced {
+		reports = append(reports, report)
+	}
+
+	var current models.MatchStatus
+	var previous *events.MatchState
+	if stored != nil {
+		current = stored.Status
+		previous = &events.MatchState{HomeScore: stored.HomeScore, AwayScore: stored.AwayScore, Status: current}
+	}
+
+	result := policy.Decide(reports, override)
+	if result.Source != SourceOverride && !models.CanTransition(current, result.Status) {
+		log.Warn("Ignoring disallowed status change",
+			"source", report.Source, "match_id", match.ID, "from", current, "to", result.Status)
+		result.Status = current
+	}
+	match.HomeScore, match.AwayScore, match.Status = result.HomeScore, result.AwayScore, result.Status
+	match.ScoreSource, match.ScoreConflict = result.Source, result.Conflict
+
+	return statusChange(match.ID, current, result), events.MatchEvents(previous, *match)
+}
+
+// Override fixes a match's result by hand, winning over every provider until
+// cleared.
+func Override(ctx context.Context, store *db.Store, override *models.ScoreOverride) (*models.Match, error) {
+	var match *models.Match
+	err := store.WithTx(ctx, func(tx *db.Store) error {
+		if err := tx.UpsertScoreOverride(ctx, override); err != nil {
+			return err
+		}
+		var err error
+		match, err = refresh(ctx, tx, override.MatchID)
+		return err
+	})
+	return match, err
+}
+
+// ClearOverride removes a match's override and restores the provider result.
+// It returns nil when the match had no override.
+func ClearOverride(ctx context.Context, store *db.Store, matchID string) (*models.Match, error) {
+	var match *models.Match
+	err := store.WithTx(ctx, func(tx *db.Store) error {
+		deleted, err := tx.DeleteScoreOverride(ctx, matchID)
+		if err != nil || !deleted {
+			return err
+		}
+		match, err = refresh(ctx, tx, matchID)
+		return err
+	})
+	return match, err
+}
+
+// refresh re-reconciles a stored match and returns it as written. Overrides
+// are corrections, so their status changes are not checked against the
+// match's lifecycle.
+func refresh(ctx context.Context, tx *db.Store, matchID string) (*models.Match, error) {
+	stored, err := tx.LockMatch(ctx, matchID)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, fmt.Errorf("match %s not found", matchID)
+	}
+	reports, override, err := load(ctx, tx, matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	match := *stored
+	result := policy.Decide(reports, override)
+	if result.Source == "" {
+		// No reports and no override: leave the stored result alone.
+		return &match, nil
+	}
+	if err := tx.SetMatchResult(ctx, matchID, result.HomeScore, result.AwayScore, result.Status, result.Source, result.Conflict); err != nil {
+		return nil, err
+	}
+	if err := recordStatusChange(ctx, tx, matchID, match.Status, result); err != nil {
+		return nil, err
+	}
+	previous := &events.MatchState{HomeScore: match.HomeScore, AwayScore: match.AwayScore, Status: match.Status}
+	match.HomeScore, match.AwayScore, match.Status = result.HomeScore, result.AwayScore, result.Status
+	match.ScoreSource, match.ScoreConflict = result.Source, result.Conflict
+	for _, e := range events.MatchEvents(previous, match) {
+		tx.Publish(e)
+	}
+	return &match, nil
+}
+
+// statusChange is the entry for the match's status history when result moves
+// it on from the stored status, or nil when it doesn't.
+func statusChange(matchID string, from models.MatchStatus, result Result) *models.StatusChange {
+	if result.Status == from {
+		return nil
+	}
+	return &models.StatusChange{MatchID: matchID, From: from, To: result.Status, Source: result.Source}
+}
+
+// recordStatusChange adds to the match's status history when result moves it
+// on from the stored status.
+func recordStatusChange(ctx context.Context, tx *db.Store, matchID string, from models.MatchStatus, result Result) error {
+	change := statusChange(matchID, from, result)
+	if change == nil {
+		return nil
+	}
+	if err := tx.InsertStatusChange(ctx, change); err != nil {
+		return fmt.Errorf("failed to record status change for %s: %v", matchID, err)
+	}
+	return nil
+}
+
+func load(ctx context.Context, tx *db.Store, matchID string) ([]models.SourceReport, *models.ScoreOverride, error) {
+	reports, err := tx.GetSourceReportsByMatchIDs(ctx, []string{matchID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load reports for %s: %v", matchID, err)
+	}
+	overrides, err := tx.GetScoreOverridesByMatchIDs(ctx, []string{matchID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load override for %s: %v", matchID, err)
+	}
+	var override *models.ScoreOverride
+	if o, ok := overrides[matchID]; ok {
+		override = &o
+	}
+	return reports[matchID], override, nil
+}
+
+// Conflict is a match whose providers disagree, with every report and any
+// override.
+type Conflict struct {
+	Match    models.Match          `json:"match"`
+	Reports  []models.SourceReport `json:"reports"`
+	Override *models.ScoreOverride `json:"override,omitempty"`
+}
+
+// Conflicts lists matches whose providers disagree. Those settled by an
+// override are included only when includeResolved is set.
+func Conflicts(ctx context.Context, store *db.Store, includeResolved bool) ([]Conflict, error) {
+	matches, err := store.GetConflictedMatches(ctx, includeResolved)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(matches))
+	for i, m := range matches {
+		ids[i] = m.ID
+	}
+	reports, err := store.GetSourceReportsByMatchIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := store.GetScoreOverridesByMatchIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := make([]Conflict, len(matches))
+	for i, m := range matches {
+		conflicts[i] = Conflict{Match: m, Reports: reports[m.ID]}
+		if o, ok := overrides[m.ID]; ok {
+			conflicts[i].Override = &o
+		}
+	}
+	return conflicts, nil
+}
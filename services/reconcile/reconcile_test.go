@@ -0,0 +1,231 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"rugby-live-api/config"
+	"rugby-live-api/db"
+	"rugby-live-api/events"
+	"rugby-live-api/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDecide(t *testing.T) {
+	now := time.Date(2024, time.March, 9, 15, 0, 0, 0, time.UTC)
+	report := func(source string, home, away int, status models.MatchStatus, age time.Duration) models.SourceReport {
+		return models.SourceReport{Source: source, HomeScore: home, AwayScore: away, Status: status, ReportedAt: now.Add(-age)}
+	}
+	policy := Policy{Priority: []string{SourceAPISports, SourceRapidAPI, SourceRugbyDB}, Freshness: 10 * time.Minute}
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		reports  []models.SourceReport
+		override *models.ScoreOverride
+		want     Result
+	}{
+		{
+			name: "no reports",
+		},
+		{
+			name:    "higher priority wins",
+			reports: []models.SourceReport{report(SourceRugbyDB, 10, 3, models.StatusSecondHalf, 0), report(SourceAPISports, 7, 3, models.StatusSecondHalf, time.Minute)},
+			want:    Result{HomeScore: 7, AwayScore: 3, Status: models.StatusSecondHalf, Source: SourceAPISports},
+		},
+		{
+			name:    "unlisted source ranks last",
+			reports: []models.SourceReport{report("other", 3, 0, models.StatusFirstHalf, 0), report(SourceRugbyDB, 0, 0, models.StatusFirstHalf, 0)},
+			want:    Result{Status: models.StatusFirstHalf, Source: SourceRugbyDB},
+		},
+		{
+			name:    "stale unsettled report loses to a fresh one",
+			reports: []models.SourceReport{report(SourceAPISports, 7, 0, models.StatusFirstHalf, 20*time.Minute), report(SourceRapidAPI, 14, 3, models.StatusSecondHalf, 0)},
+			want:    Result{HomeScore: 14, AwayScore: 3, Status: models.StatusSecondHalf, Source: SourceRapidAPI},
+		},
+		{
+			name:    "settled report is never stale",
+			reports: []models.SourceReport{report(SourceAPISports, 21, 17, models.StatusFinished, time.Hour), report(SourceRapidAPI, 21, 14, models.StatusSecondHalf, 0)},
+			want:    Result{HomeScore: 21, AwayScore: 17, Status: models.StatusFinished, Source: SourceAPISports},
+		},
+		{
+			name:    "zero freshness makes priority absolute",
+			policy:  Policy{Priority: policy.Priority},
+			reports: []models.SourceReport{report(SourceAPISports, 7, 0, models.StatusFirstHalf, time.Hour), report(SourceRapidAPI, 14, 3, models.StatusSecondHalf, 0)},
+			want:    Result{HomeScore: 7, Status: models.StatusFirstHalf, Source: SourceAPISports},
+		},
+		{
+			name:    "settled results that disagree conflict",
+			reports: []models.SourceReport{report(SourceAPISports, 21, 17, models.StatusFinished, 0), report(SourceRugbyDB, 21, 18, models.StatusFinished, 0)},
+			want:    Result{HomeScore: 21, AwayScore: 17, Status: models.StatusFinished, Source: SourceAPISports, Conflict: true},
+		},
+		{
+			name:    "scores in play that differ don't conflict",
+			reports: []models.SourceReport{report(SourceAPISports, 7, 0, models.StatusFirstHalf, 0), report(SourceRapidAPI, 10, 0, models.StatusFirstHalf, 0)},
+			want:    Result{HomeScore: 7, Status: models.StatusFirstHalf, Source: SourceAPISports},
+		},
+		{
+			name:     "override wins and keeps the conflict flag",
+			reports:  []models.SourceReport{report(SourceAPISports, 21, 17, models.StatusFinished, 0), report(SourceRugbyDB, 21, 18, models.StatusFinished, 0)},
+			override: &models.ScoreOverride{HomeScore: 21, AwayScore: 18, Status: models.StatusFinished},
+			want:     Result{HomeScore: 21, AwayScore: 18, Status: models.StatusFinished, Source: SourceOverride, Conflict: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.policy
+			if p.Priority == nil {
+				p = policy
+			}
+			if got := p.Decide(tt.reports, tt.override); got != tt.want {
+				t.Errorf("Decide() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// apply is shared by Record and RecordAll, so both store the same result,
+// status history and events for a report.
+func TestApply(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	now := time.Now().UTC()
+	report := func(source string, home, away int, status models.MatchStatus) models.SourceReport {
+		return models.SourceReport{MatchID: "m", Source: source, HomeScore: home, AwayScore: away, Status: status, ReportedAt: now}
+	}
+	tests := []struct {
+		name       string
+		stored     *models.Match
+		reports    []models.SourceReport
+		report     models.SourceReport
+		wantMatch  [3]interface{}
+		wantChange *models.StatusChange
+		wantEvents []string
+	}{
+		{
+			name:       "new result",
+			report:     report(SourceRugbyDB, 20, 10, models.StatusFinished),
+			wantMatch:  [3]interface{}{20, 10, models.StatusFinished},
+			wantChange: &models.StatusChange{MatchID: "m", To: models.StatusFinished, Source: SourceRugbyDB},
+		},
+		{
+			name:       "replaces the source's earlier report",
+			stored:     &models.Match{ID: "m", Status: models.StatusScheduled},
+			reports:    []models.SourceReport{report(SourceRugbyDB, 0, 0, models.StatusScheduled)},
+			report:     report(SourceRugbyDB, 20, 10, models.StatusFinished),
+			wantMatch:  [3]interface{}{20, 10, models.StatusFinished},
+			wantChange: &models.StatusChange{MatchID: "m", From: models.StatusScheduled, To: models.StatusFinished, Source: SourceRugbyDB},
+			wantEvents: []string{events.MatchScore, events.MatchStatus, events.MatchFinal},
+		},
+		{
+			name:       "disallowed status change keeps the stored status",
+			stored:     &models.Match{ID: "m", HomeScore: 20, AwayScore: 10, Status: models.StatusFinished},
+			reports:    []models.SourceReport{report(SourceRugbyDB, 20, 10, models.StatusFinished)},
+			report:     report(SourceAPISports, 21, 10, models.StatusSecondHalf),
+			wantMatch:  [3]interface{}{21, 10, models.StatusFinished},
+			wantEvents: []string{events.MatchScore},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := &models.Match{ID: "m", HomeScore: tt.report.HomeScore, AwayScore: tt.report.AwayScore, Status: tt.report.Status}
+			change, raised := apply(logger, match, tt.stored, tt.reports, nil, tt.report)
+			if got := [3]interface{}{match.HomeScore, match.AwayScore, match.Status}; got != tt.wantMatch {
+				t.Errorf("match = %v, want %v", got, tt.wantMatch)
+			}
+			if (change == nil) != (tt.wantChange == nil) || (change != nil && *change != *tt.wantChange) {
+				t.Errorf("change = %+v, want %+v", change, tt.wantChange)
+			}
+			var types []string
+			for _, e := range raised {
+				types = append(types, e.Type)
+			}
+			if !slices.Equal(types, tt.wantEvents) {
+				t.Errorf("events = %v, want %v", types, tt.wantEvents)
+			}
+		})
+	}
+}
+
+// BenchmarkRecordAll compares recording benchRows results a match at a time,
+// each in its own transaction as backfills did, with recording them in one
+// RecordAll. It needs a migrated database at TEST_DATABASE_URL:
+//
+//	TEST_DATABASE_URL=postgres://localhost/rugby_test?sslmode=disable go test -run - -bench RecordAll ./services/reconcile
+//
+// The rows it writes are deleted when it finishes.
+func BenchmarkRecordAll(b *testing.B) {
+	const benchRows = 1000
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		b.Skip("TEST_DATABASE_URL not set")
+	}
+	store, err := db.NewStore(config.Database{
+		URL:          url,
+		MaxOpenConns: 2,
+		MaxIdleConns: 2,
+		QueryTimeout: time.Minute,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		b.Fatal(err)
+	}
+	ctx := context.Background()
+	b.Cleanup(func() {
+		for _, query := range []string{
+			`DELETE FROM matches WHERE id LIKE 'bench-%'`,
+			`DELETE FROM teams WHERE id LIKE 'bench-%'`,
+			`DELETE FROM countries WHERE code = 'ZZ'`,
+		} {
+			if _, err := store.DB.ExecContext(ctx, query); err != nil {
+				b.Error(err)
+			}
+		}
+		store.Close()
+	})
+
+	country := models.Country{Code: "ZZ", Name: "Benchmark"}
+	home := &models.Team{ID: "bench-home", Name: "Bench Home", Country: country}
+	away := &models.Team{ID: "bench-away", Name: "Bench Away", Country: country}
+	if err := store.UpsertTeams(ctx, []*models.Team{home, away}); err != nil {
+		b.Fatal(err)
+	}
+	results := func(method string, i int) []*models.Match {
+		kickOff := time.Date(2015, time.January, 1, 15, 0, 0, 0, time.UTC)
+		matches := make([]*models.Match, benchRows)
+		for j := range matches {
+			matches[j] = &models.Match{
+				ID:         fmt.Sprintf("bench-%s-%d-%d", method, i, j),
+				HomeTeamID: home.ID,
+				AwayTeamID: away.ID,
+				LeagueID:   "bench",
+				HomeScore:  j % 40,
+				AwayScore:  j % 30,
+				Status:     models.StatusFinished,
+				KickOff:    kickOff.AddDate(0, 0, j),
+			}
+		}
+		return matches
+	}
+
+	b.Run("single", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, match := range results("single", i) {
+				if err := Record(ctx, store, SourceRugbyDB, match); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+		b.ReportMetric(float64(b.N*benchRows)/b.Elapsed().Seconds(), "rows/s")
+	})
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := RecordAll(ctx, store, SourceRugbyDB, results("batch", i)); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(b.N*benchRows)/b.Elapsed().Seconds(), "rows/s")
+	})
+}
@@ -0,0 +1,194 @@
+// Package retry resends provider requests that fail in ways another try may
+// fix: transport errors, and 429, 502, 503 and 504 responses. Retries back
+// off exponentially with jitter, honour Retry-After, and stop once the
+// policy's time budget is spent.
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"rugby-live-api/config"
+	"rugby-live-api/services/breaker"
+	"rugby-live-api/services/pool"
+	"strconv"
+	"time"
+)
+
+// Policy decides how often, and how long apart, a request is retried.
+type Policy struct {
+	// Attempts is the most times a request is sent.
+	Attempts int
+	// BaseDelay is the pause before the first retry. It doubles for each
+	// retry after, up to MaxDelay, and is jittered so clients that failed
+	// together don't retry together.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Budget bounds the time from the first attempt to the start of the
+	// last. A retry that would start later is not made.
+	Budget time.Duration
+}
+
+// Default is the policy for object storage, which has no settings of its own.
+var Default = Policy{
+	Attempts:  4,
+	BaseDelay: 500 * time.Millisecond,
+	MaxDelay:  10 * time.Second,
+	Budget:    time.Minute,
+}
+
+// ForProvider returns Default with p's retries and budget.
+func ForProvider(p config.Provider) Policy {
+	policy := Default
+	policy.Attempts = p.Retries + 1
+	policy.Budget = p.RetryBudget
+	return policy
+}
+
+// Client is an http.Client whose Do retries under Policy.
+type Client struct {
+	*http.Client
+	Policy Policy
+}
+
+// NewClient returns a Client sending through c under policy.
+func NewClient(c *http.Client, policy Policy) *Client {
+	return &Client{Client: c, Policy: policy}
+}
+
+// NewProviderClient returns a Client for the provider p configures, held to
+// its quota by pool.NewHTTPClient and retried under ForProvider(p).
+func NewProviderClient(p config.Provider) *Client {
+	return NewClient(pool.NewHTTPClient(p), ForProvider(p))
+}
+
+// StatusError is returned when a request still got a retryable status on
+// its last attempt.
+type StatusError struct {
+	Method     string
+	URL        string // Without the query, which may carry credentials
+	StatusCode int
+	Attempts   int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s returned status %d after %d attempts", e.Method, e.URL, e.StatusCode, e.Attempts)
+}
+
+// Do sends req, retrying as c.Policy allows. A request body is rebuilt with
+// req.GetBody for each retry; a request whose body can't be rebuilt is sent
+// once. Responses with statuses that aren't retried are returned as they
+// are, for the caller to check.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	attempts := c.Policy.Attempts
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		attempts = 1
+	}
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		try := req
+		if attempt > 1 {
+			try = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, fmt.Errorf("failed to rebuild request body: %v", err)
+				}
+				try.Body = body
+			}
+		}
+
+		resp, err := c.Client.Do(try)
+		if err != nil && (ctx.Err() != nil || errors.Is(err, breaker.ErrOpen)) {
+			return nil, err
+		}
+		if err == nil && !Retryable(resp.StatusCode) {
+			return resp, nil
+		}
+
+		wait := c.Policy.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				wait = after
+			}
+			// Drain the body so the connection, and its place in the
+			// provider's quota, is given back.
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if attempt >= attempts || time.Since(start)+wait > c.Policy.Budget {
+			if err != nil {
+				return nil, fmt.Errorf("after %d attempts: %w", attempt, err)
+			}
+			return nil, &StatusError{
+				Method:     req.Method,
+				URL:        req.URL.Host + req.URL.Path,
+				StatusCode: resp.StatusCode,
+				Attempts:   attempt,
+			}
+		}
+
+		reason := []any{"error", err}
+		if resp != nil {
+			reason = []any{"status", resp.StatusCode}
+		}
+		slog.InfoContext(ctx, "Provider request failed; retrying",
+			append([]any{"method", req.Method, "host", req.URL.Host, "path", req.URL.Path, "attempt", attempt, "retry_in", wait}, reason...)...)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Retryable reports whether a response with status is worth retrying.
+func Retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff returns the jittered pause after the given attempt: at least half
+// of BaseDelay doubled attempt-1 times, capped at MaxDelay.
+func (p Policy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryAfter parses a Retry-After header, given either in seconds or as an
+// HTTP date, into a wait from now.
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := at.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,213 @@
+package retry
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusBadRequest, false},
+		{http.StatusUnauthorized, false},
+		{http.StatusNotFound, false},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, false},
+		{http.StatusBadGateway, true},
+		{http.StatusServiceUnavailable, true},
+		{http.StatusGatewayTimeout, true},
+	}
+	for _, tt := range tests {
+		if got := Retryable(tt.status); got != tt.want {
+			t.Errorf("Retryable(%d) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := Policy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{1, 50 * time.Millisecond, 100 * time.Millisecond},
+		{2, 100 * time.Millisecond, 200 * time.Millisecond},
+		{3, 200 * time.Millisecond, 400 * time.Millisecond},
+		{4, 400 * time.Millisecond, 800 * time.Millisecond},
+		{5, 500 * time.Millisecond, time.Second},
+		{20, 500 * time.Millisecond, time.Second},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if got := policy.backoff(tt.attempt); got < tt.min || got > tt.max {
+				t.Fatalf("backoff(%d) = %v, want between %v and %v", tt.attempt, got, tt.min, tt.max)
+			}
+		}
+	}
+	if got := (Policy{}).backoff(3); got != 0 {
+		t.Errorf("backoff with no delay = %v, want 0", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 3, 1, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{"30", 30 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+	}
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDo(t *testing.T) {
+	policy := Policy{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, Budget: time.Second}
+	tests := []struct {
+		name         string
+		statuses     []int // Returned in turn, the last repeating
+		wantStatus   int   // Zero when a StatusError is expected
+		wantAttempts int32
+	}{
+		{"success", []int{200}, 200, 1},
+		{"retried to success", []int{503, 429, 200}, 200, 3},
+		{"not retryable", []int{404}, 404, 1},
+		{"server error not retried", []int{500}, 500, 1},
+		{"attempts spent", []int{502}, 0, 3},
+	}
+	for _, tt := range tests {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := int(calls.Add(1))
+			w.WriteHeader(tt.statuses[min(n, len(tt.statuses))-1])
+		}))
+		client := NewClient(server.Client(), policy)
+
+		req, _ := http.NewRequest("GET", server.URL+"/games?key=secret", nil)
+		resp, err := client.Do(req)
+		if tt.wantStatus != 0 {
+			if err != nil || resp.StatusCode != tt.wantStatus {
+				t.Errorf("%s: Do = %v, %v, want status %d", tt.name, resp, err, tt.wantStatus)
+			} else {
+				resp.Body.Close()
+			}
+		} else {
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) || statusErr.Attempts != int(tt.wantAttempts) {
+				t.Errorf("%s: Do error = %v, want a StatusError after %d attempts", tt.name, err, tt.wantAttempts)
+			} else if strings.Contains(statusErr.Error(), "secret") {
+				t.Errorf("%s: StatusError %q includes the query", tt.name, statusErr)
+			}
+		}
+		if got := calls.Load(); got != tt.wantAttempts {
+			t.Errorf("%s: server saw %d requests, want %d", tt.name, got, tt.wantAttempts)
+		}
+		server.Close()
+	}
+}
+
+// A Retry-After beyond the budget ends the retries at once.
+func TestDoStopsAtBudget(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.Client(), Policy{Attempts: 5, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, Budget: time.Second})
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("Do succeeded, want a StatusError")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("server saw %d requests, want 1", got)
+	}
+}
+
+// A Retry-After given as an HTTP date holds the retry back until then.
+func TestDoRetryAfterDate(t *testing.T) {
+	// HTTP dates have whole seconds, so aim for a second boundary at least a
+	// second away.
+	until := time.Now().Truncate(time.Second).Add(2 * time.Second)
+	var calls atomic.Int32
+	var retriedAt atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", until.UTC().Format(http.TimeFormat))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		retriedAt.Store(time.Now().UnixNano())
+	}))
+	defer server.Close()
+
+	client := NewClient(server.Client(), Policy{Attempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, Budget: 5 * time.Second})
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := time.Unix(0, retriedAt.Load()); got.Before(until) {
+		t.Errorf("retried at %s, before Retry-After %s", got.Format(time.StampMilli), until.Format(time.StampMilli))
+	}
+}
+
+// A POST is retried only when its body can be sent again, and then the whole
+// body goes out each time.
+func TestDoPost(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         func() io.Reader
+		wantAttempts int
+	}{
+		{"rebuildable body", func() io.Reader { return strings.NewReader("payload") }, 3},
+		{"one-shot body", func() io.Reader { return io.MultiReader(strings.NewReader("payload")) }, 1},
+	}
+	for _, tt := range tests {
+		var bodies []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		client := NewClient(server.Client(), Policy{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, Budget: time.Second})
+
+		req, _ := http.NewRequest("POST", server.URL, tt.body())
+		var statusErr *StatusError
+		if _, err := client.Do(req); !errors.As(err, &statusErr) || statusErr.Attempts != tt.wantAttempts {
+			t.Errorf("%s: Do error = %v, want a StatusError after %d attempts", tt.name, err, tt.wantAttempts)
+		}
+		server.Close()
+		if len(bodies) != tt.wantAttempts {
+			t.Errorf("%s: server saw %d requests, want %d", tt.name, len(bodies), tt.wantAttempts)
+		}
+		for i, body := range bodies {
+			if body != "payload" {
+				t.Errorf("%s: request %d body = %q, want %q", tt.name, i+1, body, "payload")
+			}
+		}
+	}
+}
@@ -1,31 +1,38 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
-	"os"
+	"rugby-live-api/config"
+	"rugby-live-api/services/retry"
 )
 
 type RugbyLiveAPI struct {
-	client *http.Client
+	client *retry.Client
+	cfg    config.Provider
 }
 
-func NewRugbyLiveAPI() *RugbyLiveAPI {
+func NewRugbyLiveAPI(cfg config.Provider) *RugbyLiveAPI {
 	return &RugbyLiveAPI{
-		client: &http.Client{},
+		client: retry.NewProviderClient(cfg),
+		cfg:    cfg,
 	}
 }
 
-func (a *RugbyLiveAPI) GetCompetitions() (map[string]interface{}, error) {
-	url := "https://rugby-live-data.p.rapidapi.com/competitions"
+func (a *RugbyLiveAPI) GetCompetitions(ctx context.Context) (map[string]interface{}, error) {
+	if err := a.cfg.Require("rapid_api"); err != nil {
+		return nil, err
+	}
+	url := a.cfg.BaseURL + "/competitions"
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("x-rapidapi-key", os.Getenv("RAPID_API_KEY"))
-	req.Header.Add("x-rapidapi-host", "rugby-live-data.p.rapidapi.com")
+	req.Header.Add("x-rapidapi-key", a.cfg.APIKey)
+	req.Header.Add("x-rapidapi-host", req.URL.Host)
 
 	resp, err := a.client.Do(req)
 	if err != nil {
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 
 	"rugby-live-api/db"
 	"rugby-live-api/models"
+	"rugby-live-api/services/pool"
 	"rugby-live-api/services/rugbydb"
 
 	"github.com/PuerkitoBio/goquery"
@@ -146,8 +148,11 @@ func normalizeCountryName(name string) string {
 	return normalized
 }
 
-func (a *APIClient) GetRugbyDBTeams(store *db.Store, priorityTeams []string, countryFilter string) ([]RugbyDBTeam, error) {
-	url := "https://www.rugbydatabase.co.nz/teams.php"
+func (a *APIClient) GetRugbyDBTeams(ctx context.Context, store *db.Store, priorityTeams []string, countryFilter string) ([]RugbyDBTeam, error) {
+	if err := a.providers.RugbyDB.Require("rugbydb"); err != nil {
+		return nil, err
+	}
+	url := a.providers.RugbyDB.BaseURL + "/teams.php"
 	var matchedTeams []RugbyDBTeam
 	var unmatchedTeams []string
 	type Match struct {
@@ -161,12 +166,10 @@ func (a *APIClient) GetRugbyDBTeams(store *db.Store, priorityTeams []string, cou
 	for _, name := range priorityTeams {
 		priorityMap[name] = true
 	}
-	done := make(chan bool)
 
 	// Get all existing rugbydatabase team mappings
-	existingMappings, err := store.GetAPIMappingsByType("rugbydatabase", "team")
+	existingMappings, err := store.GetAPIMappingsByType(ctx, "rugbydatabase", "team")
 	if err != nil {
-		fmt.Printf("Error getting existing mappings: %v\n", err)
 		return nil, err
 	}
 
@@ -174,22 +177,22 @@ func (a *APIClient) GetRugbyDBTeams(store *db.Store, priorityTeams []string, cou
 	existingTeamMappings := make(map[string]string) // map[apiID]entityID
 	for _, mapping := range existingMappings {
 		if mapping.APIID == "" {
-			fmt.Printf("Warning: Found mapping with empty API ID: %+v\n", mapping)
+			a.log.Warn("Skipping team mapping with empty API ID", "team_id", mapping.EntityID)
 			continue
 		}
 		existingTeamMappings[mapping.APIID] = mapping.EntityID
 	}
 
-	fmt.Printf("\nFetching teams from RugbyDB...\n")
+	a.log.Info("Fetching teams from RugbyDB", "url", url)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	// req.Header.Set("User-Agent", "RugbyLiveAPI/1.0")
 
-	resp, err := a.makeRequestWithRetries(req, 3)
+	resp, err := a.rugbyDB.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -200,24 +203,19 @@ func (a *APIClient) GetRugbyDBTeams(store *db.Store, priorityTeams []string, cou
 		return nil, err
 	}
 
-	// Print the HTML structure
-	html, err := doc.Html()
-	if err != nil {
-		return nil, err
-	}
-	fmt.Printf("HTML Structure:\n%s\n", html)
-
-	// Also print each competition element separately
-	doc.Find(".competition").Each(func(i int, s *goquery.Selection) {
-		html, _ := s.Html()
-		fmt.Printf("\nCompetition %d HTML:\n%s\n", i, html)
-	})
+	a.log.Debug("Fetched RugbyDB page", "url", url, "competitions", doc.Find(".competition").Length())
 
 	currentCountry := ""
 	var rugbyDBTeams []RugbyDBTeam
+	// Updates to matched teams and their mappings are written together once
+	// the page is read. A team matched again meanwhile is taken from updated,
+	// so the alternate names it already gained are kept.
+	updated := make(map[string]*models.Team)
+	var updatedTeams []*models.Team
+	var teamMappings []*models.APIMapping
 	doc.Find("h3, .wrapper").Each(func(i int, s *goquery.Selection) {
 		select {
-		case <-done:
+		case <-ctx.Done():
 			return
 		default:
 		}
@@ -243,7 +241,7 @@ func (a *APIClient) GetRugbyDBTeams(store *db.Store, priorityTeams []string, cou
 				if strings.HasPrefix(imgSrc, "http") {
 					logoURL = imgSrc
 				} else {
-					logoURL = "https://www.rugbydatabase.co.nz/" + strings.TrimPrefix(imgSrc, "/")
+					logoURL = a.providers.RugbyDB.BaseURL + "/" + strings.TrimPrefix(imgSrc, "/")
 				}
 			}
 			teamLink, _ := s.Find(".playerLink a").Attr("href")
@@ -271,10 +269,10 @@ func (a *APIClient) GetRugbyDBTeams(store *db.Store, priorityTeams []string, cou
 			// fmt.Printf("Mappings: %+v\n", rugbyDBTeams)
 
 			// First check if we already have an API mapping
-			mapping, _ := store.GetAPIMappingByAPIID("rugbydatabase", team.TeamID, "team")
+			mapping, _ := store.GetAPIMappingByAPIID(ctx, "rugbydatabase", team.TeamID, "team")
 			if mapping != nil {
 				// fmt.Printf("Found mapping for %s\n", team.Name)
-				matchingTeam, _ := store.GetTeamByID(mapping.EntityID)
+				matchingTeam, _ := store.GetTeamByID(ctx, mapping.EntityID)
 				if matchingTeam != nil {
 					team.InternalID = matchingTeam.ID
 					matchedTeams = append(matchedTeams, team)
@@ -287,7 +285,10 @@ func (a *APIClient) GetRugbyDBTeams(store *db.Store, priorityTeams []string, cou
 			}
 
 			// Try to find matching team in our database
-			if matchingTeam, err := a.FindMatchingTeam(store, team); err == nil {
+			if matchingTeam, err := a.FindMatchingTeam(ctx, store, team); err == nil {
+				if pending, ok := updated[matchingTeam.ID]; ok {
+					matchingTeam = pending
+				}
 				team.InternalID = matchingTeam.ID
 				matchedTeams = append(matchedTeams, team)
 				matches = append(matches, Match{
@@ -325,9 +326,9 @@ func (a *APIClient) GetRugbyDBTeams(store *db.Store, priorityTeams []string, cou
 					// Check if file already exists
 					if _, err := os.Stat(destPath); os.IsNotExist(err) {
 						// Download and store the image
-						uploadedURL, err := a.downloadAndStoreImage(team.LogoURL, destPath)
+						uploadedURL, err := a.downloadAndStoreImage(ctx, team.LogoURL, destPath)
 						if err == nil {
-							fmt.Printf("- Success! New URL: %s\n", uploadedURL)
+							a.log.Debug("Stored team logo", "team_id", matchingTeam.ID, "logo_url", uploadedURL)
 							matchingTeam.LogoURL = uploadedURL
 							matchingTeam.LogoSource = "rugbydatabase"
 							needsUpdate = true
@@ -335,34 +336,29 @@ func (a *APIClient) GetRugbyDBTeams(store *db.Store, priorityTeams []string, cou
 							// fmt.Printf("- Failed to upload: %v\n", err)
 						}
 					} else {
-						fmt.Printf("- Image already exists at %s\n", destPath)
+						a.log.Debug("Team logo already stored", "team_id", matchingTeam.ID, "path", destPath)
 					}
 				}
 
 				// Update team if either logo or alternate names changed
-				if needsUpdate {
-					if err := store.UpsertTeam(matchingTeam); err != nil {
-						fmt.Printf("Error updating team %s: %v\n", matchingTeam.ID, err)
-					}
+				if _, ok := updated[matchingTeam.ID]; needsUpdate && !ok {
+					updated[matchingTeam.ID] = matchingTeam
+					updatedTeams = append(updatedTeams, matchingTeam)
 				}
 
-				// Create API mapping
-				mapping := &models.APIMapping{
+				teamMappings = append(teamMappings, &models.APIMapping{
 					EntityID:   matchingTeam.ID,
 					APIName:    "rugbydatabase",
 					APIID:      team.TeamID,
 					EntityType: "team",
-				}
-				if err := store.UpsertAPIMapping(mapping); err != nil {
-					fmt.Printf("Error creating API mapping for team %s: %v\n", team.Name, err)
-				}
+				})
 
 				return
 			} else {
 				// If this was a priority team, create it
 				if isPriority {
 					// Create team regardless of logo
-					newTeam, err := a.createTeamFromRugbyDB(store, team)
+					newTeam, err := a.createTeamFromRugbyDB(ctx, store, team)
 					if err == nil {
 						team.InternalID = newTeam.ID
 						matchedTeams = append(matchedTeams, team)
@@ -370,9 +366,9 @@ func (a *APIClient) GetRugbyDBTeams(store *db.Store, priorityTeams []string, cou
 							RugbyDBTeam: team,
 							OurTeam:     newTeam,
 						})
-						fmt.Printf("Created new team for priority match: %s\n", team.Name)
+						a.log.Info("Created priority team", "team", team.Name, "team_id", newTeam.ID)
 					} else {
-						fmt.Printf("Failed to create priority team %s: %v\n", team.Name, err)
+						a.log.Error("Failed to create priority team", "team", team.Name, "error", err)
 					}
 				}
 				unmatchedTeams = append(unmatchedTeams, fmt.Sprintf("%s (%s)", name, currentCountry))
@@ -380,21 +376,24 @@ func (a *APIClient) GetRugbyDBTeams(store *db.Store, priorityTeams []string, cou
 		}
 	})
 
-	// Print all matches at the end
-	fmt.Printf("\n=== All Matches ===\n")
-	for i, match := range matches {
-		fmt.Printf("\n%d. MATCHED: %s\n", i+1, match.RugbyDBTeam.Name)
-		fmt.Printf("RugbyDB Team: %s (%s)\n", match.RugbyDBTeam.Name, match.RugbyDBTeam.Country)
-		fmt.Printf("RugbyDB ID: %s\n", match.RugbyDBTeam.TeamID)
-		fmt.Printf("Our Team: %s (%s)\n", match.OurTeam.Name, match.OurTeam.Country.Name)
-		fmt.Printf("Internal ID: %s\n", match.OurTeam.ID)
-		fmt.Printf("Current Alternate Names: %v\n", match.OurTeam.AltNames)
-		if nickname := TeamNameMapping[match.OurTeam.Name]; nickname == match.RugbyDBTeam.Name {
-			fmt.Printf("Trying to add nickname: %s\n", nickname)
-		}
-		fmt.Printf("===================\n")
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := store.UpsertTeams(ctx, updatedTeams); err != nil {
+		return nil, fmt.Errorf("failed to update %d matched teams: %v", len(updatedTeams), err)
+	}
+	if err := store.UpsertAPIMappings(ctx, teamMappings); err != nil {
+		return nil, fmt.Errorf("failed to create API mappings for %d teams: %v", len(teamMappings), err)
 	}
-	fmt.Printf("\nTotal Matches: %d\n", len(matches))
+
+	for _, match := range matches {
+		a.log.Debug("Matched RugbyDB team",
+			"rugbydb_team", match.RugbyDBTeam.Name,
+			"rugbydb_id", match.RugbyDBTeam.TeamID,
+			"team", match.OurTeam.Name,
+			"team_id", match.OurTeam.ID)
+	}
+	a.log.Info("Matched RugbyDB teams", "matched", len(matches), "unmatched", len(unmatchedTeams))
 
 	if len(unmatchedTeams) > 0 {
 		// Use fixed filename
@@ -424,20 +423,20 @@ func (a *APIClient) GetRugbyDBTeams(store *db.Store, priorityTeams []string, cou
 		}
 		fmt.Fprintf(f, "  ]\n}")
 
-		fmt.Printf("\nUnmatched teams written to: %s\n", filename)
+		a.log.Info("Wrote unmatched teams", "path", filename)
 	}
 
 	return matchedTeams, nil
 }
 
-func (a *APIClient) FindMatchingTeam(store *db.Store, rugbyDBTeam RugbyDBTeam) (*models.Team, error) {
-	country, err := store.GetCountryByName(normalizeCountryName(rugbyDBTeam.Country))
+func (a *APIClient) FindMatchingTeam(ctx context.Context, store *db.Store, rugbyDBTeam RugbyDBTeam) (*models.Team, error) {
+	country, err := store.GetCountryByName(ctx, normalizeCountryName(rugbyDBTeam.Country))
 	if err != nil {
 		return nil, err
 	}
 
 	// Get teams for this country directly
-	countryTeams, err := store.GetTeamsByCountryCode(country.Code)
+	countryTeams, err := store.GetTeamsByCountryCode(ctx, country.Code)
 	if err != nil {
 		return nil, err
 	}
@@ -485,18 +484,12 @@ func (a *APIClient) FindMatchingTeam(store *db.Store, rugbyDBTeam RugbyDBTeam) (
 		for _, suffix := range []string{" Women (W)", " (W)", " Women"} {
 			if strings.HasSuffix(compareTeamName, suffix) {
 				compareTeamName = strings.TrimSuffix(compareTeamName, suffix) + " W"
-				if strings.Contains(team.Name, "Blues") {
-					fmt.Printf("Standardized DB name: %s\n", compareTeamName)
-				}
 				break // Only replace one suffix
 			}
 		}
 		for _, suffix := range []string{" Women (W)", " (W)", " Women"} {
 			if strings.HasSuffix(compareRugbyDBName, suffix) {
 				compareRugbyDBName = strings.TrimSuffix(compareRugbyDBName, suffix) + " W"
-				if strings.Contains(rugbyDBTeam.Name, "Blues") {
-					fmt.Printf("Standardized RugbyDB name: %s\n", compareRugbyDBName)
-				}
 				break // Only replace one suffix
 			}
 		}
@@ -534,8 +527,8 @@ func (a *APIClient) FindMatchingTeam(store *db.Store, rugbyDBTeam RugbyDBTeam) (
 				}
 				if !exists {
 					team.AltNames = append(team.AltNames, rugbyDBTeam.Name)
-					if err := store.UpsertTeam(team); err != nil {
-						fmt.Printf("Error updating team alternate names for %s: %v\n", team.Name, err)
+					if err := store.UpsertTeam(ctx, team); err != nil {
+						a.log.Error("Error updating team alternate names", "team", team.Name, "error", err)
 					}
 				}
 			}
@@ -546,9 +539,9 @@ func (a *APIClient) FindMatchingTeam(store *db.Store, rugbyDBTeam RugbyDBTeam) (
 	return nil, fmt.Errorf("no matching team found for %s (%s)", rugbyDBTeam.Name, rugbyDBTeam.Country)
 }
 
-func (a *APIClient) createTeamFromRugbyDB(store *db.Store, rugbyDBTeam RugbyDBTeam) (*models.Team, error) {
+func (a *APIClient) createTeamFromRugbyDB(ctx context.Context, store *db.Store, rugbyDBTeam RugbyDBTeam) (*models.Team, error) {
 	// First ensure country exists
-	country, err := store.GetCountryByName(rugbyDBTeam.Country)
+	country, err := store.GetCountryByName(ctx, rugbyDBTeam.Country)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get country: %v", err)
 	}
@@ -573,14 +566,14 @@ func (a *APIClient) createTeamFromRugbyDB(store *db.Store, rugbyDBTeam RugbyDBTe
 			strings.TrimPrefix(internalID, country.Code+"-"),
 			rugbyDBTeam.Name,
 		)
-		if uploadedURL, err := a.downloadAndStoreImage(rugbyDBTeam.LogoURL, destPath); err == nil {
+		if uploadedURL, err := a.downloadAndStoreImage(ctx, rugbyDBTeam.LogoURL, destPath); err == nil {
 			newTeam.LogoURL = uploadedURL
 			newTeam.LogoSource = "rugbydatabase"
 		}
 	}
 
 	// Save team to database
-	if err := store.UpsertTeam(newTeam); err != nil {
+	if err := store.UpsertTeam(ctx, newTeam); err != nil {
 		return nil, fmt.Errorf("failed to create team: %v", err)
 	}
 
@@ -591,8 +584,8 @@ func (a *APIClient) createTeamFromRugbyDB(store *db.Store, rugbyDBTeam RugbyDBTe
 		APIID:      rugbyDBTeam.TeamID,
 		EntityType: "team",
 	}
-	if err := store.UpsertAPIMapping(mapping); err != nil {
-		fmt.Printf("Warning: failed to create API mapping for team %s: %v\n", newTeam.Name, err)
+	if err := store.UpsertAPIMapping(ctx, mapping); err != nil {
+		a.log.Warn("Failed to create API mapping for team", "team", newTeam.Name, "error", err)
 	}
 
 	return newTeam, nil
@@ -603,7 +596,10 @@ type TeamCreateRequest struct {
 	Country string   `json:"country"`
 }
 
-func (a *APIClient) GetLeaguesByYear(store *db.Store, year string, dryRun bool) ([]models.League, error) {
+func (a *APIClient) GetLeaguesByYear(ctx context.Context, store *db.Store, year string, dryRun bool, report *db.IngestionReport) ([]models.League, error) {
+	if err := a.providers.RugbyDB.Require("rugbydb"); err != nil {
+		return nil, err
+	}
 	// Check if year is in format "2024" or "2024-2025"
 	parts := strings.Split(year, "-")
 	var url string
@@ -616,7 +612,7 @@ func (a *APIClient) GetLeaguesByYear(store *db.Store, year string, dryRun bool)
 		if err != nil {
 			return nil, fmt.Errorf("invalid year format: %v", err)
 		}
-		url = fmt.Sprintf("https://www.rugbydatabase.co.nz/competitions.php?year=%d-%d", singleYear-1, singleYear)
+		url = fmt.Sprintf("%s/competitions.php?year=%d-%d", a.providers.RugbyDB.BaseURL, singleYear-1, singleYear)
 		seasonYear = singleYear
 		yearRange = year // Use the full range as provided
 	} else {
@@ -625,28 +621,28 @@ func (a *APIClient) GetLeaguesByYear(store *db.Store, year string, dryRun bool)
 		if err != nil {
 			return nil, fmt.Errorf("invalid end year format: %v", err)
 		}
-		url = fmt.Sprintf("https://www.rugbydatabase.co.nz/competitions.php?year=%s", year)
+		url = fmt.Sprintf("%s/competitions.php?year=%s", a.providers.RugbyDB.BaseURL, year)
 		seasonYear = endYear
 		yearRange = fmt.Sprintf("%d", endYear) // Just use the single year
 	}
 
-	leagues, err := a.scrapeLeaguesFromURL(url, seasonYear, yearRange, store, dryRun)
+	leagues, err := a.scrapeLeaguesFromURL(ctx, url, seasonYear, yearRange, store, dryRun, report)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Printf("Scraped leagues for year: %s\n", year)
+	a.log.Info("Scraped leagues", "year", year, "count", len(leagues))
 
 	return leagues, nil
 }
 
 type LeagueProcessed struct {
 	Name   string
-	Status string // "existing", "new", "unmapped"
-	Reason string // reason for unmapped status, if any
+	Status string // "existing", "new", "unmapped", "failed"
+	Reason string // reason for unmapped or failed status, if any
 }
 
-func (a *APIClient) scrapeLeaguesFromURL(url string, year int, yearRange string, store *db.Store, dryRun bool) ([]models.League, error) {
-	req, err := http.NewRequest("GET", url, nil)
+func (a *APIClient) scrapeLeaguesFromURL(ctx context.Context, url string, year int, yearRange string, store *db.Store, dryRun bool, report *db.IngestionReport) ([]models.League, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -655,9 +651,9 @@ func (a *APIClient) scrapeLeaguesFromURL(url string, year int, yearRange string,
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
 	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
 	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
-	req.Header.Set("Referer", "https://www.rugbydatabase.co.nz/")
+	req.Header.Set("Referer", a.providers.RugbyDB.BaseURL+"/")
 
-	resp, err := a.makeRequestWithRetries(req, 3)
+	resp, err := a.rugbyDB.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -668,34 +664,32 @@ func (a *APIClient) scrapeLeaguesFromURL(url string, year int, yearRange string,
 		return nil, err
 	}
 
-	// Print the HTML structure
-	html, err := doc.Html()
-	if err != nil {
-		return nil, err
-	}
-	fmt.Printf("HTML Structure:\n%s\n", html)
-
-	// Also print each competition element separately
-	doc.Find(".competition").Each(func(i int, s *goquery.Selection) {
-		html, _ := s.Html()
-		fmt.Printf("\nCompetition %d HTML:\n%s\n", i, html)
-	})
+	a.log.Debug("Fetched RugbyDB page", "url", url, "competitions", doc.Find(".competition").Length())
 
 	var leagues []models.League
 	var processed []LeagueProcessed
 
 	doc.Find(".competition").Each(func(i int, s *goquery.Selection) {
+		if ctx.Err() != nil {
+			return
+		}
 		name := strings.TrimSpace(s.Find("h2").Text())
 		if name == "" {
 			name = strings.TrimSpace(s.Find("a").Text())
 		}
 		name = rugbydb.CleanLeagueName(name)
 
+		// Tours name their own countries, so they don't need a mapping or
+		// their parent series to be stored first
+		tour, isTour := rugbydb.ParseTour(name, year)
+
 		// First check if this is a child league
 		var countryInfo rugbydb.LeagueInfo
-		if parentName, isChild := rugbydb.LeagueParentMap[name]; isChild {
+		if isTour {
+			countryInfo = tour.LeagueInfo()
+		} else if parentName, isChild := rugbydb.LeagueParentMap[name]; isChild {
 			// Try to find the parent league
-			parentLeague, err := store.GetLeagueByName(parentName)
+			parentLeague, err := store.GetLeagueByName(ctx, parentName)
 			if err != nil {
 				// Parent league doesn't exist yet, mark as unmapped
 				processed = append(processed, LeagueProcessed{
@@ -729,14 +723,14 @@ func (a *APIClient) scrapeLeaguesFromURL(url string, year int, yearRange string,
 		}
 
 		// Get country details from database
-		countryDetails, err := store.GetCountryByCode(countryInfo.Country)
+		countryDetails, err := store.GetCountryByCode(ctx, countryInfo.Country)
 		if err != nil {
 			processed = append(processed, LeagueProcessed{
 				Name:   name,
 				Status: "unmapped",
 				Reason: fmt.Sprintf("country %s not found in database", countryInfo.Country),
 			})
-			fmt.Printf("Error getting country %s from database: %v\n", countryInfo.Country, err)
+			a.log.Error("Error getting country", "country_code", countryInfo.Country, "error", err)
 			return
 		}
 
@@ -749,24 +743,19 @@ func (a *APIClient) scrapeLeaguesFromURL(url string, year int, yearRange string,
 		}
 
 		// Create league ID from name and country
-		fmt.Printf("Country: %s\n", countryDetails.Name)
 		id := fmt.Sprintf("%s-%s",
 			countryDetails.Code,
 			strings.ToUpper(strings.ReplaceAll(name, " ", "-")),
 		)
 
 		// Try to find existing league by name
-		existingLeague, err := store.GetLeagueByName(name)
+		existingLeague, err := store.GetLeagueByName(ctx, name)
 		var leagueID string
+		var newLeague *models.League
 
 		if err == nil {
 			// Use existing league
 			leagueID = existingLeague.ID
-			leagues = append(leagues, *existingLeague)
-			processed = append(processed, LeagueProcessed{
-				Name:   name,
-				Status: "existing",
-			})
 		} else {
 			// Create new league...
 			// Get competition format (League, Cup, etc.)
@@ -775,13 +764,17 @@ func (a *APIClient) scrapeLeaguesFromURL(url string, year int, yearRange string,
 			if formatInfo, exists := rugbydb.LeagueFormats[name]; exists {
 				format = formatInfo.Format
 				structure = formatInfo.Phases
+			} else if isTour {
+				format = "Tour"
 			} else if strings.Contains(strings.ToLower(name), "cup") {
 				format = "Cup"
 			}
 
 			// Determine gender
 			gender := "Men" // Default
-			if strings.Contains(name, "(W)") || strings.Contains(strings.ToLower(name), "women") {
+			if isTour {
+				gender = tour.Gender
+			} else if strings.Contains(name, "(W)") || strings.Contains(strings.ToLower(name), "women") {
 				gender = "Women"
 			}
 
@@ -792,18 +785,19 @@ func (a *APIClient) scrapeLeaguesFromURL(url string, year int, yearRange string,
 				if strings.HasPrefix(imgSrc, "http") {
 					logoURL = imgSrc
 				} else {
-					logoURL = "https://www.rugbydatabase.co.nz/" + strings.TrimPrefix(imgSrc, "/")
+					logoURL = a.providers.RugbyDB.BaseURL + "/" + strings.TrimPrefix(imgSrc, "/")
 				}
 
 				// Download and store the image
 				if !dryRun && logoURL != "" {
 					newLogoURL, err := a.downloadAndStoreImage(
+						ctx,
 						logoURL,
 						fmt.Sprintf("logos/leagues/%s/logo.png", id),
 					)
 					if err != nil {
 						if !strings.Contains(err.Error(), "409") {
-							fmt.Printf("Error downloading logo for league %s: %v\n", name, err)
+							a.log.Error("Error downloading league logo", "league", name, "error", err)
 						}
 					} else {
 						logoURL = newLogoURL
@@ -814,7 +808,7 @@ func (a *APIClient) scrapeLeaguesFromURL(url string, year int, yearRange string,
 			// Convert country codes to Country objects
 			var teamCountries []models.Country
 			for _, code := range countryInfo.Countries {
-				country, err := store.GetCountryByCode(code)
+				country, err := store.GetCountryByCode(ctx, code)
 				if err == nil {
 					teamCountries = append(teamCountries, *country)
 				}
@@ -837,7 +831,7 @@ func (a *APIClient) scrapeLeaguesFromURL(url string, year int, yearRange string,
 				Format:        format,
 				Phases:        structure,
 				Gender:        gender,
-				International: rugbydb.InternationalCompetitions[name],
+				International: rugbydb.InternationalCompetitions[name] || (isTour && tour.International),
 				LogoURL:       logoURL,
 				LogoSource:    logoSource,
 				ParentID:      nil, // Default to nil
@@ -864,11 +858,19 @@ func (a *APIClient) scrapeLeaguesFromURL(url string, year int, yearRange string,
 				season.EndDate = time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC)
 			}
 
+			if isTour {
+				league.Tour = tour.LeagueTour()
+			}
+
 			// Check if this league has a parent
 			if parentName, hasParent := rugbydb.LeagueParentMap[name]; hasParent {
 				// Try to find the parent league
-				parentLeague, err := store.GetLeagueByName(parentName)
-				if err == nil {
+				parentLeague, err := store.GetLeagueByName(ctx, parentName)
+				if err == nil && isTour {
+					// A tour keeps its own countries and format
+					league.ParentID = &parentLeague.ID
+					league.Tier = parentLeague.Tier
+				} else if err == nil {
 					league.ParentID = &parentLeague.ID
 					// Inherit properties from parent
 					league.Format = parentLeague.Format
@@ -883,29 +885,17 @@ func (a *APIClient) scrapeLeaguesFromURL(url string, year int, yearRange string,
 					// - ID (unique to child)
 					// - LogoURL (may be different)
 				} else {
-					fmt.Printf("Warning: Parent league %s not found for %s\n", parentName, name)
+					a.log.Warn("Parent league not found", "league", name, "parent", parentName)
 				}
 			}
 
 			// Check for any transitions in the database
-			if transition, err := store.GetLeagueTransition(name, year); err == nil {
+			if transition, err := store.GetLeagueTransition(ctx, name, year); err == nil {
 				league.SuccessorID = &transition.SuccessorID
-				if err := store.UpsertLeague(&league); err != nil {
-					fmt.Printf("Error updating league successor %s: %v\n", league.ID, err)
-				}
-			}
-
-			if err := store.UpsertLeague(&league); err != nil {
-				fmt.Printf("Error creating league %s: %v\n", league.ID, err)
-				return
 			}
 
 			leagueID = league.ID
-			leagues = append(leagues, league)
-			processed = append(processed, LeagueProcessed{
-				Name:   name,
-				Status: "new",
-			})
+			newLeague = &league
 		}
 
 		// Create season
@@ -934,31 +924,60 @@ func (a *APIClient) scrapeLeaguesFromURL(url string, year int, yearRange string,
 			season.EndDate = time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC)
 		}
 
-		if err := store.UpsertSeason(&season); err != nil {
-			fmt.Printf("Error creating season %s: %v\n", seasonID, err)
-			return
-		}
-
-		// Update current season flag
-		if err := store.UpdateCurrentSeason(leagueID); err != nil {
-			fmt.Printf("Error updating current season for league %s: %v\n", leagueID, err)
-		}
-
-		// Create season API mapping
-		seasonMapping := &models.APIMapping{
-			EntityID:   seasonID,
-			APIName:    "rugbydatabase",
-			APIID:      rugbyDBID,
-			EntityType: "league_season",
+		// A new league, its season and the season's mapping are stored as
+		// one unit.
+		key := rugbyDBID
+		if key == "" {
+			key = name
 		}
-		if err := store.UpsertAPIMapping(seasonMapping); err != nil {
-			fmt.Printf("Error creating API mapping for season %s: %v\n", seasonID, err)
+		err = store.Unit(ctx, report, db.UnitLeague, key, func(tx *db.Store) error {
+			if newLeague != nil {
+				if err := tx.UpsertLeague(ctx, newLeague); err != nil {
+					return db.Step("league", err)
+				}
+			}
+			if err := tx.UpsertSeason(ctx, &season); err != nil {
+				return db.Step("season", err)
+			}
+			if err := tx.UpdateCurrentSeason(ctx, leagueID); err != nil {
+				return db.Step("current season", err)
+			}
+			seasonMapping := &models.APIMapping{
+				EntityID:   seasonID,
+				APIName:    "rugbydatabase",
+				APIID:      rugbyDBID,
+				EntityType: "league_season",
+			}
+			return db.Step("season mapping", tx.UpsertAPIMapping(ctx, seasonMapping))
+		})
+		switch {
+		case err != nil:
+			processed = append(processed, LeagueProcessed{
+				Name:   name,
+				Status: "failed",
+				Reason: err.Error(),
+			})
+		case newLeague != nil:
+			leagues = append(leagues, *newLeague)
+			processed = append(processed, LeagueProcessed{
+				Name:   name,
+				Status: "new",
+			})
+		default:
+			leagues = append(leagues, *existingLeague)
+			processed = append(processed, LeagueProcessed{
+				Name:   name,
+				Status: "existing",
+			})
 		}
 	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	// Write league statuses to file
 	if err := a.writeLeaguesToFile(processed, yearRange); err != nil {
-		fmt.Printf("Warning: failed to write leagues to file: %v\n", err)
+		a.log.Warn("Failed to write leagues to file", "error", err)
 	}
 
 	return leagues, nil
@@ -997,10 +1016,13 @@ type CompetitionGroup struct {
 	Name string `json:"name"`
 }
 
-func (a *APIClient) getCompetitionHTML(competitionID string) ([]CompetitionGroup, error) {
-	url := fmt.Sprintf("https://www.rugbydatabase.co.nz/competition/index.php?competitionId=%s", competitionID)
+func (a *APIClient) getCompetitionHTML(ctx context.Context, competitionID string) ([]CompetitionGroup, error) {
+	if err := a.providers.RugbyDB.Require("rugbydb"); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/competition/index.php?competitionId=%s", a.providers.RugbyDB.BaseURL, competitionID)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1009,9 +1031,9 @@ func (a *APIClient) getCompetitionHTML(competitionID string) ([]CompetitionGroup
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
 	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
 	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
-	req.Header.Set("Referer", "https://www.rugbydatabase.co.nz/")
+	req.Header.Set("Referer", a.providers.RugbyDB.BaseURL+"/")
 
-	resp, err := a.makeRequestWithRetries(req, 3)
+	resp, err := a.rugbyDB.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -1050,9 +1072,10 @@ type LeagueIDMapping struct {
 	AllTimeID         string               `json:"all_time_id,omitempty"`
 	NewAllTimeLeague  *models.League       `json:"new_all_time_league,omitempty"`
 	NewAllTimeMapping *models.APIMapping   `json:"new_all_time_mapping,omitempty"`
+	Error             string               `json:"error,omitempty"`
 }
 
-func (a *APIClient) GetLeagueIDsByYear(year string, store *db.Store) ([]LeagueIDMapping, error) {
+func (a *APIClient) GetLeagueIDsByYear(ctx context.Context, year string, store *db.Store) ([]LeagueIDMapping, error) {
 	query := `
 		SELECT m.api_id, m.entity_id, l.name, l.all_time, l.all_time_league_id, l.id,
 			   alt.id as all_time_league_id,
@@ -1066,7 +1089,7 @@ func (a *APIClient) GetLeagueIDsByYear(year string, store *db.Store) ([]LeagueID
 		AND m.entity_type = 'league_season'
 		AND s.year = $1`
 
-	rows, err := store.DB.Query(query, year)
+	rows, err := store.DB.QueryContext(ctx, query, year)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query league mappings: %v", err)
 	}
@@ -1101,18 +1124,35 @@ func (a *APIClient) GetLeagueIDsByYear(year string, store *db.Store) ([]LeagueID
 		}
 
 		mapping.Year = year
-
-		// Get groups for this competition
-		groups, err := a.getCompetitionHTML(mapping.CompetitionID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get groups for competition %s: %v", mapping.CompetitionID, err)
+		mappings = append(mappings, mapping)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read league mappings: %v", err)
+	}
+	rows.Close()
+
+	// Fetch every competition's groups up front, a few pages at a time, then
+	// store them in order. A competition whose page can't be fetched is
+	// reported on its mapping rather than failing the rest.
+	groupResults := pool.Map(ctx, mappings, a.providers.RugbyDB.Concurrency,
+		func(ctx context.Context, mapping LeagueIDMapping) ([]CompetitionGroup, error) {
+			return a.getCompetitionHTML(ctx, mapping.CompetitionID)
+		})
+
+	for i := range mappings {
+		mapping := &mappings[i]
+		if err := groupResults[i].Err; err != nil {
+			a.log.Error("Failed to get groups for competition", "competition_id", mapping.CompetitionID, "error", err)
+			mapping.Error = fmt.Sprintf("failed to get groups for competition %s: %v", mapping.CompetitionID, err)
+			continue
 		}
+		groups := groupResults[i].Value
 		mapping.Groups = groups
 
 		// When processing each mapping, create both regular and all-time mappings
 		if len(groups) > 1 && mapping.AllTimeID == "" {
 			// Get original league first
-			originalLeague, err := store.GetLeagueByID(mapping.LeagueID)
+			originalLeague, err := store.GetLeagueByID(ctx, mapping.LeagueID)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get original league: %v", err)
 			}
@@ -1150,13 +1190,13 @@ func (a *APIClient) GetLeagueIDsByYear(year string, store *db.Store) ([]LeagueID
 			}
 
 			// Store mappings and add to response
-			if err := store.UpsertAPIMapping(regularMapping); err != nil {
+			if err := store.UpsertAPIMapping(ctx, regularMapping); err != nil {
 				return nil, fmt.Errorf("failed to insert regular league mapping: %v", err)
 			}
-			if err := store.UpsertAPIMapping(allTimeMapping); err != nil {
+			if err := store.UpsertAPIMapping(ctx, allTimeMapping); err != nil {
 				return nil, fmt.Errorf("failed to insert all-time mapping: %v", err)
 			}
-			if err := store.UpsertLeague(allTimeLeague); err != nil {
+			if err := store.UpsertLeague(ctx, allTimeLeague); err != nil {
 				return nil, fmt.Errorf("failed to insert all-time league: %v", err)
 			}
 
@@ -1174,12 +1214,13 @@ func (a *APIClient) GetLeagueIDsByYear(year string, store *db.Store) ([]LeagueID
 			}
 			mapping.GroupMappings = append(mapping.GroupMappings, regularMapping)
 
-			if err := store.UpsertAPIMapping(regularMapping); err != nil {
+			if err := store.UpsertAPIMapping(ctx, regularMapping); err != nil {
 				return nil, fmt.Errorf("failed to insert regular league mapping: %v", err)
 			}
 		}
-
-		mappings = append(mappings, mapping)
+	}
+	if err := ctx.Err(); err != nil {
+		return mappings, err
 	}
 
 	return mappings, nil
@@ -0,0 +1,213 @@
+package rugbydb
+
+import (
+	"regexp"
+	"rugby-live-api/models"
+	"strings"
+)
+
+// Tour is a one-off series RugbyDB names "<tourists> in <hosts>", such as
+// "All Blacks in Europe" or "Fiji in Australia (W)".
+type Tour struct {
+	Name             string
+	Tourists         []string // Touring sides as named
+	TouristCountries []string
+	Hosts            []string // Host countries or regions as named
+	HostCountries    []string // Empty when the hosts are regions, as which countries are visited isn't named
+	Country          string   // Code the competition is filed under: the host country or region, or WLD
+	Year             int
+	Gender           string
+	International    bool // False when a touring side is a club or provincial team
+}
+
+type tourPlace struct {
+	Code      string
+	Countries []string
+}
+
+// tourCountries maps the countries named in tours to their codes.
+var tourCountries = map[string]string{
+	"Argentina":     "ARG",
+	"Australia":     "AUS",
+	"Belgium":       "BEL",
+	"Brazil":        "BRA",
+	"Canada":        "CAN",
+	"Chile":         "CHL",
+	"England":       "ENG",
+	"Fiji":          "FJI",
+	"France":        "FRA",
+	"Georgia":       "GEO",
+	"Germany":       "GER",
+	"Hong Kong":     "HKG",
+	"Ireland":       "IRL",
+	"Italy":         "ITA",
+	"Japan":         "JPN",
+	"Kenya":         "KEN",
+	"Namibia":       "NAM",
+	"Netherlands":   "NLD",
+	"New Zealand":   "NZL",
+	"Portugal":      "POR",
+	"Romania":       "ROU",
+	"Samoa":         "SAM",
+	"Scotland":      "SCO",
+	"South Africa":  "RSA",
+	"Spain":         "ESP",
+	"Switzerland":   "SWI",
+	"Tonga":         "TGA",
+	"Uganda":        "UGA",
+	"United States": "USA",
+	"Uruguay":       "UGY",
+	"Wales":         "WAL",
+	"Zimbabwe":      "ZIM",
+}
+
+// tourRegions maps the regions tours are hosted in to the code their
+// competitions are filed under, and to their countries where the name
+// settles them.
+var tourRegions = map[string]tourPlace{
+	"Europe":                {Code: "EUR"},
+	"United Kingdom":        {Code: "EUR", Countries: []string{"ENG", "SCO", "WAL"}},
+	"Pacific Islands":       {Code: "OCE"},
+	"Africa":                {Code: "WLD"},
+	"Asia":                  {Code: "WLD"},
+	"North America":         {Code: "WLD"},
+	"South America":         {Code: "WLD"},
+	"North & South America": {Code: "WLD"},
+}
+
+type touringSide struct {
+	Countries     []string
+	International bool
+}
+
+// touringSides maps touring teams not named after their country.
+var touringSides = map[string]touringSide{
+	"All Blacks":               {Countries: []string{"NZL"}, International: true},
+	"All Blacks XV":            {Countries: []string{"NZL"}, International: true},
+	"Black Ferns":              {Countries: []string{"NZL"}, International: true},
+	"Maori All Blacks":         {Countries: []string{"NZL"}, International: true},
+	"British & Irish Lions":    {Countries: []string{"ENG", "IRL", "SCO", "WAL"}, International: true},
+	"New Zealand Heartland XV": {Countries: []string{"NZL"}},
+	"Reds":                     {Countries: []string{"AUS"}},
+}
+
+// tourSuffix matches the gender and edition markers RugbyDB appends, as in
+// "Fiji in Europe (2)".
+var tourSuffix = regexp.MustCompile(`\s*\((W|\d+)\)$`)
+
+// ParseTour parses a competition name of the form "<tourists> in <hosts>",
+// where tourists and hosts may list several names joined by "&". It reports
+// false when the name isn't a tour or names a side or place it doesn't know.
+func ParseTour(name string, year int) (Tour, bool) {
+	tour := Tour{Name: name, Year: year, Gender: "Men"}
+
+	base := name
+	for {
+		m := tourSuffix.FindStringSubmatch(base)
+		if m == nil {
+			break
+		}
+		if m[1] == "W" {
+			tour.Gender = "Women"
+		}
+		base = base[:len(base)-len(m[0])]
+	}
+
+	tourists, hosts, ok := strings.Cut(base, " in ")
+	if !ok {
+		return Tour{}, false
+	}
+
+	tour.International = true
+	for _, side := range splitTourNames(strings.TrimSpace(tourists), func(s string) bool { _, ok := touringSides[s]; return ok }) {
+		t, ok := touringSides[side]
+		if !ok {
+			t, ok = nationalSide(side)
+		}
+		if !ok {
+			return Tour{}, false
+		}
+		tour.Tourists = append(tour.Tourists, side)
+		tour.TouristCountries = appendCodes(tour.TouristCountries, t.Countries...)
+		tour.International = tour.International && t.International
+	}
+
+	var codes []string
+	for _, place := range splitTourNames(strings.TrimSpace(hosts), func(s string) bool { _, ok := tourRegions[s]; return ok }) {
+		if code, ok := tourCountries[place]; ok {
+			codes = appendCodes(codes, code)
+			tour.HostCountries = appendCodes(tour.HostCountries, code)
+		} else if region, ok := tourRegions[place]; ok {
+			codes = appendCodes(codes, region.Code)
+			tour.HostCountries = appendCodes(tour.HostCountries, region.Countries...)
+		} else {
+			return Tour{}, false
+		}
+		tour.Hosts = append(tour.Hosts, place)
+	}
+
+	tour.Country = "WLD"
+	if len(codes) == 1 {
+		tour.Country = codes[0]
+	}
+	return tour, true
+}
+
+// LeagueInfo returns the tour's country mapping, its countries being those
+// of the tourists and of the hosts where known.
+func (t Tour) LeagueInfo() LeagueInfo {
+	return LeagueInfo{
+		Country:   t.Country,
+		Countries: appendCodes(append([]string{}, t.TouristCountries...), t.HostCountries...),
+	}
+}
+
+// LeagueTour returns who is touring and where, as stored on the league.
+func (t Tour) LeagueTour() *models.Tour {
+	return &models.Tour{
+		Tourists:         t.Tourists,
+		TouristCountries: t.TouristCountries,
+		Hosts:            t.Hosts,
+		HostCountries:    t.HostCountries,
+	}
+}
+
+// nationalSide resolves a country's national team, including its A and XV
+// sides.
+func nationalSide(name string) (touringSide, bool) {
+	for _, suffix := range []string{"", " A", " XV"} {
+		if code, ok := tourCountries[strings.TrimSuffix(name, suffix)]; ok && (suffix == "" || strings.HasSuffix(name, suffix)) {
+			return touringSide{Countries: []string{code}, International: true}, true
+		}
+	}
+	return touringSide{}, false
+}
+
+// splitTourNames splits names joined by "&", unless whole is a single name
+// that contains one, such as "British & Irish Lions".
+func splitTourNames(names string, whole func(string) bool) []string {
+	if whole(names) {
+		return []string{names}
+	}
+	parts := strings.Split(names, "&")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+func appendCodes(codes []string, add ...string) []string {
+	for _, code := range add {
+		found := false
+		for _, c := range codes {
+			if c == code {
+				found = true
+				break
+			}
+		}
+		if !found {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
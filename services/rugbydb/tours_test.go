@@ -0,0 +1,78 @@
+package rugbydb
+
+import (
+	"reflect"
+	"rugby-live-api/services/kickoff"
+	"testing"
+)
+
+func TestParseTour(t *testing.T) {
+	tests := []struct {
+		name string
+		want Tour // Name and Year are filled in from the test
+		ok   bool
+	}{
+		{"All Blacks in Europe", Tour{
+			Tourists: []string{"All Blacks"}, TouristCountries: []string{"NZL"},
+			Hosts: []string{"Europe"}, Country: "EUR", Gender: "Men", International: true,
+		}, true},
+		{"Fiji in Australia (W)", Tour{
+			Tourists: []string{"Fiji"}, TouristCountries: []string{"FJI"},
+			Hosts: []string{"Australia"}, HostCountries: []string{"AUS"}, Country: "AUS", Gender: "Women", International: true,
+		}, true},
+		{"Fiji in Europe (2)", Tour{
+			Tourists: []string{"Fiji"}, TouristCountries: []string{"FJI"},
+			Hosts: []string{"Europe"}, Country: "EUR", Gender: "Men", International: true,
+		}, true},
+		{"British & Irish Lions in South Africa", Tour{
+			Tourists: []string{"British & Irish Lions"}, TouristCountries: []string{"ENG", "IRL", "SCO", "WAL"},
+			Hosts: []string{"South Africa"}, HostCountries: []string{"RSA"}, Country: "RSA", Gender: "Men", International: true,
+		}, true},
+		{"Samoa & Tonga in United Kingdom", Tour{
+			Tourists: []string{"Samoa", "Tonga"}, TouristCountries: []string{"SAM", "TGA"},
+			Hosts: []string{"United Kingdom"}, HostCountries: []string{"ENG", "SCO", "WAL"}, Country: "EUR", Gender: "Men", International: true,
+		}, true},
+		{"Zimbabwe in Asia", Tour{
+			Tourists: []string{"Zimbabwe"}, TouristCountries: []string{"ZIM"},
+			Hosts: []string{"Asia"}, Country: "WLD", Gender: "Men", International: true,
+		}, true},
+		{"England A in Chile & Uruguay", Tour{
+			Tourists: []string{"England A"}, TouristCountries: []string{"ENG"},
+			Hosts: []string{"Chile", "Uruguay"}, HostCountries: []string{"CHL", "UGY"}, Country: "WLD", Gender: "Men", International: true,
+		}, true},
+		{"Reds in Japan", Tour{
+			Tourists: []string{"Reds"}, TouristCountries: []string{"AUS"},
+			Hosts: []string{"Japan"}, HostCountries: []string{"JPN"}, Country: "JPN", Gender: "Men",
+		}, true},
+		{"Six Nations", Tour{}, false},
+		{"Atlantis in Europe", Tour{}, false},
+		{"Fiji in Atlantis", Tour{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseTour(tt.name, 2024)
+		want := tt.want
+		if tt.ok {
+			want.Name, want.Year = tt.name, 2024
+		}
+		if ok != tt.ok || !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseTour(%q) = %+v, %v, want %+v, %v", tt.name, got, ok, want, tt.ok)
+		}
+	}
+}
+
+// Every country a tour names must have a venue timezone, or its matches
+// can't be placed on a local day.
+func TestTourCountriesHaveTimezones(t *testing.T) {
+	for name, code := range tourCountries {
+		if _, ok := kickoff.CountryTimezones[code]; !ok {
+			t.Errorf("%s (%s) has no entry in kickoff.CountryTimezones", name, code)
+		}
+	}
+	for name, side := range touringSides {
+		for _, code := range side.Countries {
+			if _, ok := kickoff.CountryTimezones[code]; !ok {
+				t.Errorf("%s (%s) has no entry in kickoff.CountryTimezones", name, code)
+			}
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"rugby-live-api/db"
+	"rugby-live-api/models"
+	"rugby-live-api/services/kickoff"
+	"rugby-live-api/services/reconcile"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// RugbyDBFixture is one fixture row from a RugbyDB competition page. Team IDs
+// are RugbyDB's; scores are nil until the match has been played.
+type RugbyDBFixture struct {
+	MatchID    string `json:"match_id"`
+	HomeTeamID string `json:"home_team_id"`
+	HomeTeam   string `json:"home_team"`
+	AwayTeamID string `json:"away_team_id"`
+	AwayTeam   string `json:"away_team"`
+	Date       string `json:"date"`
+	HomeScore  *int   `json:"home_score,omitempty"`
+	AwayScore  *int   `json:"away_score,omitempty"`
+	Venue      string `json:"venue,omitempty"`
+}
+
+// SkippedRugbyDBFixture is a fixture the scraper could not or would not store.
+type SkippedRugbyDBFixture struct {
+	SeasonID string         `json:"season_id"`
+	Fixture  RugbyDBFixture `json:"fixture"`
+	Reason   string         `json:"reason"`
+}
+
+type RugbyDBMatchesResult struct {
+	Seasons []string                `json:"seasons"`
+	Matches []models.Match          `json:"matches"`
+	Skipped []SkippedRugbyDBFixture `json:"skipped"`
+}
+
+var (
+	rugbyDBScorePattern = regexp.MustCompile(`(\d+)\s*[-–]\s*(\d+)`)
+	rugbyDBDateLayouts  = []string{"Mon 2 Jan 2006", "Mon 02 Jan 2006", "2 Jan 2006", "02 Jan 2006", "2 January 2006", "02/01/2006", "2006-01-02"}
+)
+
+// ScrapeRugbyDBMatches walks every season mapped to a RugbyDB competition and
+// stores its fixtures and results with rugbydatabase match mappings. year and
+// seasonID narrow the seasons walked when set. Matches API-Sports already
+// covers are left alone, so RugbyDB only fills in seasons it has no data for.
+func (a *APIClient) ScrapeRugbyDBMatches(ctx context.Context, store *db.Store, year int, seasonID string, dryRun bool) (*RugbyDBMatchesResult, error) {
+	seasonMappings, err := store.GetAPIMappingsByType(ctx, "rugbydatabase", "league_season")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get season mappings: %v", err)
+	}
+	competitionIDs := make(map[string]string) // season ID -> RugbyDB competition ID
+	var seasonIDs []string
+	for _, m := range seasonMappings {
+		if seasonID != "" && m.EntityID != seasonID {
+			continue
+		}
+		if _, ok := competitionIDs[m.EntityID]; !ok {
+			seasonIDs = append(seasonIDs, m.EntityID)
+		}
+		competitionIDs[m.EntityID] = m.APIID
+	}
+
+	seasons, err := store.GetSeasonsByIDs(ctx, seasonIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seasons: %v", err)
+	}
+	var leagueIDs []string
+	for _, s := range seasons {
+		leagueIDs = append(leagueIDs, s.LeagueID)
+	}
+	leagues, err := store.GetLeaguesByIDs(ctx, leagueIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leagues: %v", err)
+	}
+	leagueCountries := make(map[string]string)
+	for _, l := range leagues {
+		leagueCountries[l.ID] = l.Country.Code
+	}
+
+	teamMappings, err := store.GetAPIMappingsByType(ctx, "rugbydatabase", "team")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team mappings: %v", err)
+	}
+	teams := make(map[string]string) // RugbyDB team ID -> team ID
+	for _, m := range teamMappings {
+		teams[m.APIID] = m.EntityID
+	}
+
+	apiSportsMappings, err := store.GetAPIMappingsByType(ctx, "api_sports", "match")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API-Sports match mappings: %v", err)
+	}
+	apiSportsMatches := make(map[string]bool)
+	for _, m := range apiSportsMappings {
+		apiSportsMatches[m.EntityID] = true
+	}
+
+	result := &RugbyDBMatchesResult{Seasons: []string{}, Matches: []models.Match{}, Skipped: []SkippedRugbyDBFixture{}}
+	byDate := make(map[string][]string)
+	// A season's matches are stored together once its fixtures are read,
+	// followed by their mappings.
+	var pending []*models.Match
+	var mappings []*models.APIMapping
+	flush := func() error {
+		if len(pending) > 0 {
+			if err := reconcile.RecordAll(ctx, store, reconcile.SourceRugbyDB, pending); err != nil {
+				return fmt.Errorf("failed to upsert %d matches: %v", len(pending), err)
+			}
+			for _, m := range pending {
+				byDate[m.Date] = append(byDate[m.Date], m.ID)
+			}
+		}
+		if len(mappings) > 0 {
+			if err := store.UpsertAPIMappings(ctx, mappings); err != nil {
+				return fmt.Errorf("failed to create API mappings for %d matches: %v", len(mappings), err)
+			}
+		}
+		pending, mappings = nil, nil
+		return nil
+	}
+	for _, season := range seasons {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if year != 0 && season.Year != year {
+			continue
+		}
+		result.Seasons = append(result.Seasons, season.ID)
+
+		fixtures, err := a.getRugbyDBFixtures(ctx, competitionIDs[season.ID])
+		if err != nil {
+			return result, fmt.Errorf("failed to get fixtures for season %s: %v", season.ID, err)
+		}
+		venueTimezone := kickoff.CountryTimezones[leagueCountries[season.LeagueID]]
+
+		// API-Sports matches are stored under the season, or the league for
+		// today's games.
+		seasonMatches, err := store.GetMatchesBySeasonIDs(ctx, []string{season.ID, season.LeagueID})
+		if err != nil {
+			return result, fmt.Errorf("failed to get stored matches for season %s: %v", season.ID, err)
+		}
+		covered := make(apiSportsCoverage)
+		for _, m := range seasonMatches {
+			if apiSportsMatches[m.ID] {
+				covered.add(m)
+			}
+		}
+
+		for _, f := range fixtures {
+			skip := func(reason string) {
+				result.Skipped = append(result.Skipped, SkippedRugbyDBFixture{SeasonID: season.ID, Fixture: f, Reason: reason})
+			}
+
+			homeID, ok := teams[f.HomeTeamID]
+			if !ok {
+				skip(fmt.Sprintf("unmapped RugbyDB team %s (%s)", f.HomeTeam, f.HomeTeamID))
+				continue
+			}
+			awayID, ok := teams[f.AwayTeamID]
+			if !ok {
+				skip(fmt.Sprintf("unmapped RugbyDB team %s (%s)", f.AwayTeam, f.AwayTeamID))
+				continue
+			}
+			kickOff, err := parseRugbyDBDate(f.Date, venueTimezone)
+			if err != nil {
+				skip(err.Error())
+				continue
+			}
+
+			if covered.covers(homeID, awayID, kickOff) {
+				skip("covered by API-Sports")
+				continue
+			}
+			matchID := fmt.Sprintf("%s-%s-%s-%s", season.ID, homeID, awayID, kickOff.Format("20060102"))
+
+			match := models.Match{
+				ID:            matchID,
+				HomeTeamID:    homeID,
+				AwayTeamID:    awayID,
+				LeagueID:      season.ID,
+				Status:        models.StatusScheduled,
+				KickOff:       kickOff,
+				Date:          kickOff.Format("2006-01-02"),
+				Time:          kickOff.Format("15:04"),
+				VenueTimezone: venueTimezone,
+				Venue:         f.Venue,
+			}
+			if f.HomeScore != nil && f.AwayScore != nil {
+				match.HomeScore = *f.HomeScore
+				match.AwayScore = *f.AwayScore
+				match.Status = models.StatusFinished
+			}
+			result.Matches = append(result.Matches, match)
+
+			if dryRun {
+				continue
+			}
+			pending = append(pending, &match)
+			if f.MatchID != "" {
+				mappings = append(mappings, &models.APIMapping{
+					EntityID:   matchID,
+					APIName:    "rugbydatabase",
+					APIID:      f.MatchID,
+					EntityType: "match",
+				})
+			}
+		}
+		if err := flush(); err != nil {
+			return result, fmt.Errorf("failed to store season %s: %v", season.ID, err)
+		}
+	}
+
+	for date, ids := range byDate {
+		if err := store.UpsertDailyMatches(ctx, date, ids); err != nil {
+			a.log.Error("Error updating daily matches", "date", date, "error", err)
+		}
+	}
+	return result, nil
+}
+
+// coverWindow is how far apart an API-Sports kick-off and a RugbyDB fixture's
+// can be for the two to be the same match. RugbyDB gives dates only, read as
+// midday at the venue, so this allows the real kick-off a day either side:
+// an evening game in New Zealand or Australia falls on the previous UTC day.
+const coverWindow = 36 * time.Hour
+
+// apiSportsCoverage holds the kick-offs of one season's API-Sports matches
+// by home and away team.
+type apiSportsCoverage map[[2]string][]time.Time
+
+func (c apiSportsCoverage) add(m models.Match) {
+	key := [2]string{m.HomeTeamID, m.AwayTeamID}
+	c[key] = append(c[key], m.KickOff)
+}
+
+// covers reports whether API-Sports has the match between homeID and awayID
+// kicking off around kickOff.
+func (c apiSportsCoverage) covers(homeID, awayID string, kickOff time.Time) bool {
+	for _, t := range c[[2]string{homeID, awayID}] {
+		if d := t.Sub(kickOff); d <= coverWindow && d >= -coverWindow {
+			return true
+		}
+	}
+	return false
+}
+
+// getRugbyDBFixtures scrapes the fixtures and results listed on a RugbyDB
+// competition page.
+func (a *APIClient) getRugbyDBFixtures(ctx context.Context, competitionID string) ([]RugbyDBFixture, error) {
+	if err := a.providers.RugbyDB.Require("rugbydb"); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/competition/index.php?competitionId=%s", a.providers.RugbyDB.BaseURL, competitionID)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add browser-like headers
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
+	req.Header.Set("Referer", a.providers.RugbyDB.BaseURL+"/")
+
+	resp, err := a.rugbyDB.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("RugbyDB returned status %d", resp.StatusCode)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return parseRugbyDBFixtures(doc), nil
+}
+
+// parseRugbyDBFixtures reads fixture rows. Each is a table row holding a
+// match link (matchId=) whose text is the score once played, two team links
+// (teamId=), a date cell and, when known, a venue link.
+func parseRugbyDBFixtures(doc *goquery.Document) []RugbyDBFixture {
+	var fixtures []RugbyDBFixture
+	doc.Find("tr").Each(func(i int, row *goquery.Selection) {
+		teamLinks := row.Find("a[href*='teamId=']")
+		if teamLinks.Length() < 2 {
+			return
+		}
+		home, away := teamLinks.Eq(0), teamLinks.Eq(1)
+
+		f := RugbyDBFixture{
+			HomeTeamID: queryValue(home, "teamId"),
+			HomeTeam:   strings.TrimSpace(home.Text()),
+			AwayTeamID: queryValue(away, "teamId"),
+			AwayTeam:   strings.TrimSpace(away.Text()),
+			Venue:      strings.TrimSpace(row.Find("a[href*='venueId=']").First().Text()),
+		}
+
+		if link := row.Find("a[href*='matchId=']").First(); link.Length() > 0 {
+			f.MatchID = queryValue(link, "matchId")
+			if score := rugbyDBScorePattern.FindStringSubmatch(link.Text()); score != nil {
+				homeScore, _ := strconv.Atoi(score[1])
+				awayScore, _ := strconv.Atoi(score[2])
+				f.HomeScore, f.AwayScore = &homeScore, &awayScore
+			}
+		}
+
+		row.Find("td").EachWithBreak(func(j int, cell *goquery.Selection) bool {
+			text := strings.Join(strings.Fields(cell.Text()), " ")
+			for _, layout := range rugbyDBDateLayouts {
+				if _, err := time.Parse(layout, text); err == nil {
+					f.Date = text
+					return false
+				}
+			}
+			return true
+		})
+
+		fixtures = append(fixtures, f)
+	})
+	return fixtures
+}
+
+// queryValue returns the value of key in the link's href query string.
+func queryValue(link *goquery.Selection, key string) string {
+	href, _ := link.Attr("href")
+	parts := strings.SplitN(href, key+"=", 2)
+	if len(parts) < 2 {
+		return ""
+	}
+	return strings.SplitN(parts[1], "&", 2)[0]
+}
+
+// parseRugbyDBDate parses a fixture date. RugbyDB lists dates without
+// kick-off times, so the match is placed at midday at the venue to keep it on
+// the right calendar day.
+func parseRugbyDBDate(value string, venueTimezone string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("missing date")
+	}
+	loc, err := kickoff.LoadLocation(venueTimezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, layout := range rugbyDBDateLayouts {
+		if day, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return day.Add(12 * time.Hour).UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date %q", value)
+}
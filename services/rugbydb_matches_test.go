@@ -0,0 +1,42 @@
+package services
+
+import (
+	"rugby-live-api/models"
+	"testing"
+	"time"
+)
+
+func TestAPISportsCoverage(t *testing.T) {
+	// An evening kick-off in Auckland falls on the previous UTC day.
+	auckland, err := time.LoadLocation("Pacific/Auckland")
+	if err != nil {
+		t.Skip(err)
+	}
+	covered := make(apiSportsCoverage)
+	covered.add(models.Match{HomeTeamID: "BLUES", AwayTeamID: "CHIEFS", KickOff: time.Date(2024, time.March, 9, 19, 35, 0, 0, auckland).UTC()})
+
+	tests := []struct {
+		name       string
+		home, away string
+		date       string
+		want       bool
+	}{
+		{"same local date", "BLUES", "CHIEFS", "9 Mar 2024", true},
+		{"a day early", "BLUES", "CHIEFS", "8 Mar 2024", true},
+		{"a day late", "BLUES", "CHIEFS", "10 Mar 2024", true},
+		{"a week later", "BLUES", "CHIEFS", "16 Mar 2024", false},
+		{"reverse fixture", "CHIEFS", "BLUES", "9 Mar 2024", false},
+		{"other teams", "BLUES", "CRUSADERS", "9 Mar 2024", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kickOff, err := parseRugbyDBDate(tt.date, "Pacific/Auckland")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := covered.covers(tt.home, tt.away, kickOff); got != tt.want {
+				t.Errorf("covers(%s, %s, %s) = %v, want %v", tt.home, tt.away, kickOff, got, tt.want)
+			}
+		})
+	}
+}
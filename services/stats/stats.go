@@ -0,0 +1,106 @@
+// Package stats summarises a team's stored results into a form guide and
+// season aggregates.
+package stats
+
+import (
+	"math"
+	"rugby-live-api/models"
+	"strings"
+)
+
+// TeamForm is a team's most recent results. Results reads oldest to newest, so
+// the latest result is on the right, and Matches is in the same order.
+type TeamForm struct {
+	TeamID  string              `json:"team_id"`
+	Results string              `json:"results"`
+	Matches []models.TeamResult `json:"matches"`
+}
+
+// TeamRecord aggregates a set of results. Results reads oldest to newest.
+// AverageWinningMargin is the mean points margin of the wins, rounded to one
+// decimal place, and zero without any.
+type TeamRecord struct {
+	Played               int     `json:"played"`
+	Won                  int     `json:"won"`
+	Drawn                int     `json:"drawn"`
+	Lost                 int     `json:"lost"`
+	PointsFor            int     `json:"points_for"`
+	PointsAgainst        int     `json:"points_against"`
+	PointsDifference     int     `json:"points_difference"`
+	AverageWinningMargin float64 `json:"average_winning_margin"`
+	Results              string  `json:"results"`
+}
+
+// TeamStats splits a team's record by venue. SeasonID is empty for totals
+// across every stored season.
+type TeamStats struct {
+	TeamID   string     `json:"team_id"`
+	SeasonID string     `json:"season_id,omitempty"`
+	Overall  TeamRecord `json:"overall"`
+	Home     TeamRecord `json:"home"`
+	Away     TeamRecord `json:"away"`
+}
+
+// NewTeamForm builds a form guide from results ordered newest first, as
+// db.GetTeamResults returns them.
+func NewTeamForm(teamID string, newestFirst []models.TeamResult) TeamForm {
+	form := TeamForm{TeamID: teamID, Matches: make([]models.TeamResult, len(newestFirst))}
+	var results strings.Builder
+	for i, r := range newestFirst {
+		form.Matches[len(newestFirst)-1-i] = r
+	}
+	for _, r := range form.Matches {
+		results.WriteString(r.Result)
+	}
+	form.Results = results.String()
+	return form
+}
+
+// Summarise aggregates results ordered newest first.
+func Summarise(teamID, seasonID string, newestFirst []models.TeamResult) TeamStats {
+	stats := TeamStats{TeamID: teamID, SeasonID: seasonID}
+	var overall, home, away tally
+	for i := len(newestFirst) - 1; i >= 0; i-- {
+		r := newestFirst[i]
+		overall.add(r)
+		if r.Home {
+			home.add(r)
+		} else {
+			away.add(r)
+		}
+	}
+	stats.Overall, stats.Home, stats.Away = overall.record(), home.record(), away.record()
+	return stats
+}
+
+type tally struct {
+	TeamRecord
+	results       strings.Builder
+	winningMargin int
+}
+
+func (t *tally) add(r models.TeamResult) {
+	t.Played++
+	t.PointsFor += r.PointsFor
+	t.PointsAgainst += r.PointsAgainst
+	switch r.Result {
+	case "W":
+		t.Won++
+		t.winningMargin += r.PointsFor - r.PointsAgainst
+	case "L":
+		t.Lost++
+	default:
+		t.Drawn++
+	}
+	t.results.WriteString(r.Result)
+}
+
+func (t *tally) record() TeamRecord {
+	rec := t.TeamRecord
+	rec.PointsDifference = rec.PointsFor - rec.PointsAgainst
+	rec.Results = t.results.String()
+	if rec.Won > 0 {
+		rec.AverageWinningMargin = math.Round(float64(t.winningMargin)/float64(rec.Won)*10) / 10
+	}
+	return rec
+}
@@ -2,13 +2,14 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
-	"os"
 	"path/filepath"
+	"rugby-live-api/config"
 	"rugby-live-api/db"
 	"strings"
 )
@@ -33,10 +34,10 @@ type ListResponse struct {
 	} `json:"data"`
 }
 
-func (c *APIClient) downloadAndStoreImage(sourceURL string, destinationPath string) (string, error) {
+func (c *APIClient) downloadAndStoreImage(ctx context.Context, sourceURL string, destinationPath string) (string, error) {
 	// fmt.Printf("- Downloading image...\n")
 	// Create request with headers
-	req, err := http.NewRequest("GET", sourceURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", sourceURL, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %v", err)
 	}
@@ -44,7 +45,7 @@ func (c *APIClient) downloadAndStoreImage(sourceURL string, destinationPath stri
 	// Add headers to mimic a browser request
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
 	req.Header.Set("Accept", "image/webp,image/apng,image/*,*/*;q=0.8")
-	req.Header.Set("Referer", "https://www.rugbydatabase.co.nz/")
+	req.Header.Set("Referer", c.providers.RugbyDB.BaseURL+"/")
 
 	// Download image
 	resp, err := c.client.Do(req)
@@ -63,20 +64,28 @@ func (c *APIClient) downloadAndStoreImage(sourceURL string, destinationPath stri
 		return "", fmt.Errorf("failed to read image data: %v", err)
 	}
 
-	// Upload to Supabase Storage
-	baseURL := strings.TrimSuffix(os.Getenv("SUPABASE_URL"), "/storage/v1/s3")
-	url := fmt.Sprintf("%s/storage/v1/object/rugbylive-api/%s", baseURL, destinationPath)
+	return c.UploadObject(ctx, destinationPath, http.DetectContentType(imageData), bytes.NewReader(imageData))
+}
+
+// UploadObject stores body at path in the storage bucket and returns its
+// public URL. body is streamed, so it may be larger than memory.
+func (c *APIClient) UploadObject(ctx context.Context, path string, contentType string, body io.Reader) (string, error) {
+	if err := c.storageEnabled(); err != nil {
+		return "", err
+	}
+	baseURL := c.storage.URL
+	url := fmt.Sprintf("%s/storage/v1/object/%s/%s", baseURL, c.storage.Bucket, path)
 
 	// Create directory if it doesn't exist
-	dir := filepath.Dir(destinationPath)
+	dir := filepath.Dir(path)
 	if dir != "." {
-		createDirURL := fmt.Sprintf("%s/storage/v1/object/rugbylive-api/%s/", baseURL, dir)
-		req, err := http.NewRequest("POST", createDirURL, nil)
+		createDirURL := fmt.Sprintf("%s/storage/v1/object/%s/%s/", baseURL, c.storage.Bucket, dir)
+		req, err := http.NewRequestWithContext(ctx, "POST", createDirURL, nil)
 		if err != nil {
 			return "", fmt.Errorf("failed to create directory request: %v", err)
 		}
 
-		req.Header.Set("Authorization", "Bearer "+os.Getenv("SUPABASE_SERVICE_ROLE_KEY"))
+		req.Header.Set("Authorization", "Bearer "+c.storage.ServiceRoleKey)
 		resp, err := c.client.Do(req)
 		if err != nil {
 			return "", fmt.Errorf("failed to create directory: %v", err)
@@ -85,82 +94,100 @@ func (c *APIClient) downloadAndStoreImage(sourceURL string, destinationPath stri
 	}
 
 	// Upload file
-	req, err = http.NewRequest("POST", url, bytes.NewReader(imageData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, body)
 	if err != nil {
 		return "", fmt.Errorf("failed to create upload request: %v", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+os.Getenv("SUPABASE_SERVICE_ROLE_KEY"))
-	req.Header.Set("Content-Type", http.DetectContentType(imageData))
+	req.Header.Set("Authorization", "Bearer "+c.storage.ServiceRoleKey)
+	req.Header.Set("Content-Type", contentType)
 
-	resp, err = c.client.Do(req)
+	resp, err := c.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to upload image: %v", err)
+		return "", fmt.Errorf("failed to upload %s: %v", path, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("failed to upload image: %s - %s", resp.Status, string(body))
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("failed to upload %s: %s - %s", path, resp.Status, string(respBody))
 	}
 
 	// Return public URL
-	return fmt.Sprintf("%s/storage/v1/object/public/rugbylive-api/%s", baseURL, destinationPath), nil
+	return fmt.Sprintf("%s/storage/v1/object/public/%s/%s", baseURL, c.storage.Bucket, path), nil
 }
 
-func (a *APIClient) UpdateTeamImages(store *db.Store) error {
-	teams, err := store.GetAllTeams()
+func (a *APIClient) UpdateTeamImages(ctx context.Context, store *db.Store) error {
+	if err := a.providers.RugbyDB.Require("rugbydb"); err != nil {
+		return err
+	}
+	teams, err := store.GetAllTeams(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get teams: %v", err)
 	}
 
 	for _, team := range teams {
-		log.Printf("Processing team %s", team.ID)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		log := a.log.With("team_id", team.ID)
+		log.Debug("Processing team logo")
 
 		// Get logo URL from rugbydb
-		rugbydbURL := fmt.Sprintf("https://www.rugbydatabase.co.nz/images/teams/%s.png", strings.ToLower(team.ID))
+		rugbydbURL := fmt.Sprintf("%s/images/teams/%s.png", a.providers.RugbyDB.BaseURL, strings.ToLower(team.ID))
 
 		// Skip if rugbydb URL returns the generic team.webp
-		resp, err := a.client.Head(rugbydbURL)
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, rugbydbURL, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := a.rugbyDB.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
 		if err != nil || resp.StatusCode != http.StatusOK || strings.Contains(resp.Header.Get("Content-Type"), "webp") {
-			log.Printf("Skipping team %s - generic webp image or error", team.ID)
+			log.Debug("Skipping team logo: generic webp image or error")
 			continue
 		}
 
 		newLogoURL, err := a.downloadAndStoreImage(
+			ctx,
 			rugbydbURL,
 			fmt.Sprintf("logos/teams/%s/%s/logo.png", team.Country.Code, strings.TrimPrefix(team.ID, team.Country.Code+"-")),
 		)
 		if err != nil {
-			log.Printf("Error downloading logo for team %s: %v", team.ID, err)
+			log.Error("Error downloading team logo", "error", err)
 			continue
 		}
 
 		team.LogoURL = newLogoURL
 		team.LogoSource = "rugbydb"
 
-		if err := store.UpsertTeam(team); err != nil {
-			log.Printf("Error updating team %s: %v", team.ID, err)
+		if err := store.UpsertTeam(ctx, team); err != nil {
+			log.Error("Error updating team", "error", err)
 			continue
 		}
 
-		log.Printf("Successfully updated team %s with new logo URL", team.ID)
+		log.Info("Updated team logo URL", "logo_url", newLogoURL)
 	}
 
 	return nil
 }
 
-func (c *APIClient) listStorageFiles(prefix string) ([]string, error) {
-	baseURL := strings.TrimSuffix(os.Getenv("SUPABASE_URL"), "/storage/v1/s3")
-	listURL := fmt.Sprintf("%s/storage/v1/bucket/list/rugbylive-api/%s", baseURL, strings.TrimPrefix(prefix, "/"))
-	fmt.Printf("Listing files at URL: %s\n", listURL)
+func (c *APIClient) listStorageFiles(ctx context.Context, prefix string) ([]string, error) {
+	if err := c.storageEnabled(); err != nil {
+		return nil, err
+	}
+	baseURL := c.storage.URL
+	listURL := fmt.Sprintf("%s/storage/v1/bucket/list/%s/%s", baseURL, c.storage.Bucket, strings.TrimPrefix(prefix, "/"))
+	c.log.Debug("Listing storage files", "url", listURL)
 
-	req, err := http.NewRequest("GET", listURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", listURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+os.Getenv("SUPABASE_SERVICE_ROLE_KEY"))
+	req.Header.Set("Authorization", "Bearer "+c.storage.ServiceRoleKey)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.client.Do(req)
@@ -169,9 +196,8 @@ func (c *APIClient) listStorageFiles(prefix string) ([]string, error) {
 	}
 	defer resp.Body.Close()
 
-	fmt.Printf("Response status: %s\n", resp.Status)
 	body, _ := io.ReadAll(resp.Body)
-	fmt.Printf("Raw response: %s\n", string(body))
+	c.log.Debug("Listed storage files", "status", resp.StatusCode, "bytes", len(body))
 
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("failed to list files: %s", string(body))
@@ -182,38 +208,36 @@ func (c *APIClient) listStorageFiles(prefix string) ([]string, error) {
 
 	var listResponse ListResponse
 	if err := json.NewDecoder(resp.Body).Decode(&listResponse); err != nil {
-		fmt.Printf("Decode error: %v\n", err)
 		return nil, err
 	}
 
-	fmt.Printf("Found %d total files\n", len(listResponse.Data))
-	fmt.Printf("All files in storage:\n")
 	fileNames := make([]string, len(listResponse.Data))
 	matchingFiles := 0
 	for _, file := range listResponse.Data {
-		fmt.Printf("- %s\n", file.Name)
 		if strings.HasPrefix(file.Name, prefix) {
 			fileNames[matchingFiles] = file.Name
 			matchingFiles++
-			fmt.Printf("Matching file %d: %s\n", matchingFiles, file.Name)
 		}
 	}
 	fileNames = fileNames[:matchingFiles]
-	fmt.Printf("Found %d files matching prefix %s\n", matchingFiles, prefix)
+	c.log.Debug("Found storage files", "prefix", prefix, "total", len(listResponse.Data), "matching", matchingFiles)
 
 	return fileNames, nil
 }
 
-func (c *APIClient) deleteStorageFile(path string) error {
-	baseURL := strings.TrimSuffix(os.Getenv("SUPABASE_URL"), "/storage/v1/s3")
-	deleteURL := fmt.Sprintf("%s/storage/v1/object/rugbylive-api/%s", baseURL, path)
+func (c *APIClient) deleteStorageFile(ctx context.Context, path string) error {
+	if err := c.storageEnabled(); err != nil {
+		return err
+	}
+	baseURL := c.storage.URL
+	deleteURL := fmt.Sprintf("%s/storage/v1/object/%s/%s", baseURL, c.storage.Bucket, path)
 
-	req, err := http.NewRequest("DELETE", deleteURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", deleteURL, nil)
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+os.Getenv("SUPABASE_SERVICE_ROLE_KEY"))
+	req.Header.Set("Authorization", "Bearer "+c.storage.ServiceRoleKey)
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -229,10 +253,10 @@ func (c *APIClient) deleteStorageFile(path string) error {
 	return nil
 }
 
-func (c *APIClient) MigrateStoragePaths(store *db.Store) error {
-	fmt.Println("Starting storage migration...")
+func (c *APIClient) MigrateStoragePaths(ctx context.Context, store *db.Store) error {
+	c.log.Info("Starting storage migration")
 
-	rows, err := store.DB.Query(`
+	rows, err := store.DB.QueryContext(ctx, `
         SELECT old_code, new_code
         FROM country_code_mapping
     `)
@@ -246,13 +270,13 @@ func (c *APIClient) MigrateStoragePaths(store *db.Store) error {
 		if err := rows.Scan(&oldCode, &newCode); err != nil {
 			return fmt.Errorf("failed to scan country mapping: %v", err)
 		}
-		fmt.Printf("Processing country code: %s -> %s\n", oldCode, newCode)
+		c.log.Info("Migrating country code", "old_code", oldCode, "new_code", newCode)
 
 		// List all files in the old country code folder
 		oldPrefix := fmt.Sprintf("logos/teams/%s/", oldCode)
-		files, err := c.listStorageFiles(oldPrefix)
+		files, err := c.listStorageFiles(ctx, oldPrefix)
 		if err != nil {
-			fmt.Printf("Error listing files for %s: %v\n", oldCode, err)
+			c.log.Error("Error listing files", "country_code", oldCode, "error", err)
 			continue
 		}
 
@@ -263,34 +287,37 @@ func (c *APIClient) MigrateStoragePaths(store *db.Store) error {
 				oldCode,
 				newCode, 1)
 
-			oldURL := fmt.Sprintf("%s/storage/v1/object/public/rugbylive-api/%s",
-				strings.TrimSuffix(os.Getenv("SUPABASE_URL"), "/storage/v1/s3"),
+			oldURL := fmt.Sprintf("%s/storage/v1/object/public/%s/%s",
+				c.storage.URL, c.storage.Bucket,
 				oldPath)
-			newURL := fmt.Sprintf("%s/storage/v1/object/public/rugbylive-api/%s",
-				strings.TrimSuffix(os.Getenv("SUPABASE_URL"), "/storage/v1/s3"),
+			newURL := fmt.Sprintf("%s/storage/v1/object/public/%s/%s",
+				c.storage.URL, c.storage.Bucket,
 				newPath)
 
-			if err := c.moveStorageFile(oldURL, newURL); err != nil {
-				fmt.Printf("Error moving file %s: %v\n", file, err)
+			if err := c.moveStorageFile(ctx, oldURL, newURL); err != nil {
+				c.log.Error("Error moving file", "path", file, "error", err)
 				continue
 			}
-			fmt.Printf("Moved file %s to %s\n", oldPath, newPath)
+			c.log.Debug("Moved file", "from", oldPath, "to", newPath)
 		}
 	}
 
 	return nil
 }
 
-func (c *APIClient) moveStorageFile(oldURL, newURL string) error {
-	baseURL := strings.TrimSuffix(os.Getenv("SUPABASE_URL"), "/storage/v1/s3")
+func (c *APIClient) moveStorageFile(ctx context.Context, oldURL, newURL string) error {
+	if err := c.storageEnabled(); err != nil {
+		return err
+	}
+	baseURL := c.storage.URL
 	moveURL := fmt.Sprintf("%s/storage/v1/object/move", baseURL)
 
 	// Extract bucket paths
-	oldPath := strings.TrimPrefix(oldURL, fmt.Sprintf("%s/storage/v1/object/public/rugbylive-api/", baseURL))
-	newPath := strings.TrimPrefix(newURL, fmt.Sprintf("%s/storage/v1/object/public/rugbylive-api/", baseURL))
+	oldPath := strings.TrimPrefix(oldURL, fmt.Sprintf("%s/storage/v1/object/public/%s/", baseURL, c.storage.Bucket))
+	newPath := strings.TrimPrefix(newURL, fmt.Sprintf("%s/storage/v1/object/public/%s/", baseURL, c.storage.Bucket))
 
 	payload := map[string]interface{}{
-		"bucketId":       "rugbylive-api",
+		"bucketId":       c.storage.Bucket,
 		"sourceKey":      oldPath,
 		"destinationKey": newPath,
 	}
@@ -300,12 +327,12 @@ func (c *APIClient) moveStorageFile(oldURL, newURL string) error {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", moveURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", moveURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+os.Getenv("SUPABASE_SERVICE_ROLE_KEY"))
+	req.Header.Set("Authorization", "Bearer "+c.storage.ServiceRoleKey)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.client.Do(req)
@@ -322,12 +349,12 @@ func (c *APIClient) moveStorageFile(oldURL, newURL string) error {
 	return nil
 }
 
-func (c *APIClient) createBucketIfNotExists() error {
-	baseURL := strings.TrimSuffix(os.Getenv("SUPABASE_URL"), "/storage/v1/s3")
+func (c *APIClient) createBucketIfNotExists(ctx context.Context) error {
+	baseURL := c.storage.URL
 	url := fmt.Sprintf("%s/storage/v1/bucket", baseURL)
 
 	payload := map[string]interface{}{
-		"name":            "rugbylive-api",
+		"name":            c.storage.Bucket,
 		"public":          true,
 		"file_size_limit": 5242880,
 	}
@@ -337,12 +364,12 @@ func (c *APIClient) createBucketIfNotExists() error {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+os.Getenv("SUPABASE_SERVICE_ROLE_KEY"))
+	req.Header.Set("Authorization", "Bearer "+c.storage.ServiceRoleKey)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.client.Do(req)
@@ -357,3 +384,43 @@ func (c *APIClient) createBucketIfNotExists() error {
 
 	return nil
 }
+
+// ErrStorageDisabled is returned by storage operations when the storage
+// backend is "none".
+var ErrStorageDisabled = errors.New("object storage is disabled")
+
+// StorageEnabled reports whether a storage backend is configured.
+func (c *APIClient) StorageEnabled() bool {
+	return c.storage.Backend != config.StorageNone
+}
+
+func (c *APIClient) storageEnabled() error {
+	if !c.StorageEnabled() {
+		return ErrStorageDisabled
+	}
+	return nil
+}
+
+// PingStorage checks the storage bucket can be reached with the configured
+// credentials.
+func (c *APIClient) PingStorage(ctx context.Context) error {
+	if err := c.storageEnabled(); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", c.storage.URL+"/storage/v1/bucket/"+c.storage.Bucket, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.storage.ServiceRoleKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("storage returned status %d", resp.StatusCode)
+	}
+	return nil
+}
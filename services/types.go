@@ -1,11 +1,10 @@
 package services
 
 import (
-	"fmt"
 	"strings"
 )
 
-// Move all type definitions here (CountriesResponse, teamChange, etc.)
+// Move all type definitions here (CountriesResponse, TeamChange, etc.)
 
 type CountriesResponse struct {
 	Get        string `json:"get"`
@@ -19,7 +18,7 @@ type CountriesResponse struct {
 	} `json:"response"`
 }
 
-type countryChange struct {
+type CountryChange struct {
 	Name    string
 	Code    string
 	Changes map[string]interface{}
@@ -59,14 +58,14 @@ type TeamsResponse struct {
 	} `json:"response"`
 }
 
-type teamChange struct {
+type TeamChange struct {
 	Name    string
 	ID      string
 	Changes map[string]interface{}
 	IsNew   bool
 }
 
-type failedTeam struct {
+type FailedTeam struct {
 	Name        string
 	CountryID   int
 	CountryName string
@@ -98,7 +97,7 @@ type APISportsLeaguesResponse struct {
 	} `json:"response"`
 }
 
-type leagueChange struct {
+type LeagueChange struct {
 	Name    string
 	ID      string
 	Changes map[string]interface{}
@@ -332,10 +331,6 @@ var TeamNameMapping = map[string]string{
 
 // TeamNameNormalizer removes common suffixes and standardizes team names
 func TeamNameNormalizer(name string) string {
-	if strings.Contains(name, "Blues") {
-		fmt.Printf("Normalizing Blues team: %s\n", name)
-	}
-
 	// First check if we have a direct mapping
 	if standardName, exists := TeamNameMapping[name]; exists {
 		if strings.Contains(name, "Blues") {
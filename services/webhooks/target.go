@@ -0,0 +1,83 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenTarget is returned, wrapped, for webhook URLs that resolve to
+// an address inside the network rather than a partner's public endpoint.
+var ErrForbiddenTarget = errors.New("webhook URL must resolve to a public address")
+
+// sharedAddressSpace is carrier-grade NAT space, which IsPrivate leaves out.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// forbiddenAddr reports whether deliveries must not reach addr: loopback,
+// private, link-local (which holds cloud metadata endpoints such as
+// 169.254.169.254), unspecified, multicast or shared address space.
+func forbiddenAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return !addr.IsValid() ||
+		addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified() ||
+		sharedAddressSpace.Contains(addr)
+}
+
+// CheckURL checks rawURL is an absolute http or https URL whose host
+// resolves only to public addresses. Deliveries are checked again as they
+// connect, so a name re-pointed after subscribing is still refused.
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %v", u.Hostname(), err)
+	}
+	for _, addr := range addrs {
+		if forbiddenAddr(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrForbiddenTarget, u.Hostname(), addr)
+		}
+	}
+	return nil
+}
+
+// guardDial refuses connections to forbidden addresses. It runs after name
+// resolution, on the address actually dialled, so it also covers redirects
+// and names that resolve differently from when they were checked.
+func guardDial(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenTarget, address)
+	}
+	if forbiddenAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenTarget, addrPort.Addr())
+	}
+	return nil
+}
+
+// publicTransport is the delivery transport: it dials public addresses
+// only and ignores proxy settings, which would hide the address dialled.
+func publicTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = nil
+	t.DialContext = (&net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   guardDial,
+	}).DialContext
+	return t
+}
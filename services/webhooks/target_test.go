@@ -0,0 +1,80 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestForbiddenAddr(t *testing.T) {
+	tests := []struct {
+		addr      string
+		forbidden bool
+	}{
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true}, // Cloud metadata
+		{"fd00:ec2::254", true},   // AWS metadata over IPv6
+		{"fe80::1", true},
+		{"100.64.0.1", true},
+		{"0.0.0.0", true},
+		{"::", true},
+		{"224.0.0.1", true},
+		{"::ffff:127.0.0.1", true},
+		{"::ffff:10.0.0.1", true},
+		{"8.8.8.8", false},
+		{"1.1.1.1", false},
+		{"2606:4700:4700::1111", false},
+		{"::ffff:8.8.8.8", false},
+	}
+	for _, tt := range tests {
+		if got := forbiddenAddr(netip.MustParseAddr(tt.addr)); got != tt.forbidden {
+			t.Errorf("forbiddenAddr(%s) = %v, want %v", tt.addr, got, tt.forbidden)
+		}
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url       string
+		ok        bool
+		forbidden bool
+	}{
+		{"https://93.184.216.34/hooks", true, false},
+		{"http://[2606:4700:4700::1111]:8080/hooks", true, false},
+		{"http://127.0.0.1:8080/hooks", false, true},
+		{"http://localhost/hooks", false, true},
+		{"http://169.254.169.254/latest/meta-data/", false, true},
+		{"http://[::1]/hooks", false, true},
+		{"http://10.0.0.5/hooks", false, true},
+		{"ftp://93.184.216.34/hooks", false, false},
+		{"/hooks", false, false},
+		{"https://", false, false},
+	}
+	for _, tt := range tests {
+		err := CheckURL(context.Background(), tt.url)
+		if (err == nil) != tt.ok || errors.Is(err, ErrForbiddenTarget) != tt.forbidden {
+			t.Errorf("CheckURL(%q) = %v, want ok %v, forbidden %v", tt.url, err, tt.ok, tt.forbidden)
+		}
+	}
+}
+
+// Deliveries are refused at dial time, whatever the URL was checked as.
+func TestPublicTransportRefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("delivery reached a loopback server")
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: publicTransport()}
+	_, err := client.Post(server.URL, "application/json", nil)
+	if !errors.Is(err, ErrForbiddenTarget) {
+		t.Fatalf("Post to %s = %v, want ErrForbiddenTarget", server.URL, err)
+	}
+}
@@ -0,0 +1,406 @@
+// Package webhooks delivers published events to partner endpoints. Each
+// delivery is signed with the subscription's secret, retried with
+// exponential backoff, and written to a dead-letter table when every attempt
+// fails so an admin can replay it.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"rugby-live-api/db"
+	"rugby-live-api/events"
+	"rugby-live-api/logging"
+	"rugby-live-api/metrics"
+	"rugby-live-api/models"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Request headers sent with every delivery.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderID        = "X-Webhook-ID"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Sign returns the signature header value for body sent at timestamp: the
+// hex HMAC-SHA256 of "<timestamp>.<body>" keyed by secret, prefixed
+// "sha256=". Receivers recompute it to check the payload is genuine and
+// reject stale timestamps to stop replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type delivery struct {
+	sub     models.WebhookSubscription
+	event   events.Event
+	body    []byte
+	attempt int
+}
+
+// Dispatcher queues events for every subscription that wants them and
+// delivers them from a fixed set of workers.
+type Dispatcher struct {
+	store    *db.Store
+	client   *http.Client
+	incoming chan events.Event
+	queue    chan delivery
+	log      *slog.Logger
+
+	// subs caches active subscriptions by event type for SubscriptionTTL.
+	// subsGen counts invalidations, so a lookup racing one isn't cached.
+	subsMu  sync.Mutex
+	subs    map[string]cachedSubs
+	subsGen int
+
+	// pending counts deliveries not yet delivered or dead-lettered,
+	// including those waiting to be retried. stopping is closed by Shutdown,
+	// under stopMu so no event is counted once Shutdown may be waiting.
+	// ctx is cancelled if Shutdown gives up waiting, aborting deliveries in
+	// flight.
+	pending      sync.WaitGroup
+	stopMu       sync.Mutex
+	stopping     chan struct{}
+	shutdownOnce sync.Once
+	ctx          context.Context
+	cancel       context.CancelFunc
+
+	// MaxAttempts is how many times a delivery is tried before it is
+	// dead-lettered. Backoff is the wait after the first failure; it doubles
+	// after each further one.
+	MaxAttempts int
+	Backoff     time.Duration
+	// SubscriptionTTL is how long subscriptions looked up for an event type
+	// are reused. Invalidate drops them sooner.
+	SubscriptionTTL time.Duration
+}
+
+type cachedSubs struct {
+	subs    []models.WebhookSubscription
+	expires time.Time
+}
+
+// errQueueFull is recorded against deliveries dead-lettered because the
+// delivery queue had no room for them, and errShuttingDown against events
+// raised after Shutdown began.
+var (
+	errQueueFull    = errors.New("delivery queue full")
+	errShuttingDown = errors.New("dispatcher shutting down")
+)
+
+func NewDispatcher(store *db.Store) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		store:           store,
+		client:          &http.Client{Timeout: 10 * time.Second, Transport: metrics.Transport("webhook", publicTransport())},
+		incoming:        make(chan events.Event, 1000),
+		queue:           make(chan delivery, 1000),
+		log:             store.Logger(),
+		subs:            make(map[string]cachedSubs),
+		stopping:        make(chan struct{}),
+		ctx:             ctx,
+		cancel:          cancel,
+		MaxAttempts:     5,
+		Backoff:         2 * time.Second,
+		SubscriptionTTL: 30 * time.Second,
+	}
+}
+
+// Start runs workers goroutines delivering queued events, and one fanning
+// handled events out to their subscriptions.
+func (d *Dispatcher) Start(workers int) {
+	go func() {
+		for e := range d.incoming {
+			d.fanOut(e)
+		}
+	}()
+	for i := 0; i < workers; i++ {
+		go func() {
+			for del := range d.queue {
+				d.attempt(del)
+			}
+		}()
+	}
+}
+
+// Handle hands e off to be queued for every active subscription to its type.
+// It is meant to be passed to events.Subscribe and never blocks the
+// publisher: when the hand-off is full, e is fanned out from a goroutine of
+// its own. Once Shutdown has begun, e is dead-lettered for every
+// subscription instead.
+func (d *Dispatcher) Handle(e events.Event) {
+	if !d.track() {
+		d.deadLetterEvent(e, errShuttingDown)
+		return
+	}
+	select {
+	case d.incoming <- e:
+	default:
+		go d.fanOut(e)
+	}
+}
+
+// track counts an event as pending unless Shutdown has begun. Counting from
+// zero while Shutdown waits would misuse the WaitGroup.
+func (d *Dispatcher) track() bool {
+	d.stopMu.Lock()
+	defer d.stopMu.Unlock()
+	if d.stopped() {
+		return false
+	}
+	d.pending.Add(1)
+	return true
+}
+
+// Invalidate drops cached subscriptions, so changes to them apply to the
+// next event.
+func (d *Dispatcher) Invalidate() {
+	d.subsMu.Lock()
+	defer d.subsMu.Unlock()
+	clear(d.subs)
+	d.subsGen++
+}
+
+// fanOut queues e for every active subscription to its type. A delivery
+// the queue has no room for is dead-lettered rather than waited on, so one
+// slow endpoint can't hold up the rest. Events outlive the request that
+// raised them, so the lookup runs under a context of its own.
+func (d *Dispatcher) fanOut(e events.Event) {
+	defer d.pending.Done()
+	ctx := logging.WithJobID(context.Background(), e.ID)
+	for _, del := range d.deliveries(ctx, e) {
+		d.pending.Add(1)
+		select {
+		case d.queue <- del:
+		default:
+			log := d.log.With("subscription_id", del.sub.ID, "event_type", e.Type, "attempt", 0)
+			log.WarnContext(ctx, "Webhook queue full; dead-lettering")
+			del.attempt = 0
+			d.deadLetter(ctx, log, del, errQueueFull)
+		}
+	}
+}
+
+// deadLetterEvent dead-letters e for every active subscription to its type
+// without attempting delivery.
+func (d *Dispatcher) deadLetterEvent(e events.Event, err error) {
+	ctx := logging.WithJobID(context.Background(), e.ID)
+	for _, del := range d.deliveries(ctx, e) {
+		log := d.log.With("subscription_id", del.sub.ID, "event_type", e.Type, "attempt", 0)
+		log.WarnContext(ctx, "Webhook not delivered; dead-lettering", "error", err)
+		del.attempt = 0
+		d.recordDeadLetter(ctx, log, del, err)
+	}
+}
+
+// deliveries returns a first-attempt delivery of e for every active
+// subscription to its type, logging and returning none on error.
+func (d *Dispatcher) deliveries(ctx context.Context, e events.Event) []delivery {
+	subs, err := d.subscriptions(ctx, e.Type)
+	if err != nil {
+		d.log.ErrorContext(ctx, "Error loading webhook subscriptions", "event_type", e.Type, "error", err)
+		return nil
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		d.log.ErrorContext(ctx, "Error encoding event", "event_type", e.Type, "event_id", e.ID, "error", err)
+		return nil
+	}
+	dels := make([]delivery, len(subs))
+	for i, sub := range subs {
+		dels[i] = delivery{sub: sub, event: e, body: body, attempt: 1}
+	}
+	return dels
+}
+
+// subscriptions returns the active subscriptions to eventType, from the
+// cache while it is fresh.
+func (d *Dispatcher) subscriptions(ctx context.Context, eventType string) ([]models.WebhookSubscription, error) {
+	d.subsMu.Lock()
+	cached, ok := d.subs[eventType]
+	gen := d.subsGen
+	d.subsMu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.subs, nil
+	}
+
+	subs, err := d.store.GetWebhookSubscriptionsForEvent(ctx, eventType)
+	if err != nil {
+		return nil, err
+	}
+	d.subsMu.Lock()
+	if d.subsGen == gen {
+		d.subs[eventType] = cachedSubs{subs: subs, expires: time.Now().Add(d.SubscriptionTTL)}
+	}
+	d.subsMu.Unlock()
+	return subs, nil
+}
+
+// Shutdown waits for queued deliveries to finish. Deliveries that fail from
+// then on, those waiting to be retried and events raised after it begins
+// are dead-lettered straight away so they can be replayed once the service
+// is back. If ctx is done first, deliveries still in flight are aborted and
+// ctx's error returned.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	d.shutdownOnce.Do(func() {
+		d.stopMu.Lock()
+		close(d.stopping)
+		d.stopMu.Unlock()
+	})
+	done := make(chan struct{})
+	go func() {
+		d.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		return ctx.Err()
+	}
+}
+
+// attempt makes one delivery, requeueing it after a backoff on failure and
+// dead-lettering it once MaxAttempts is reached.
+func (d *Dispatcher) attempt(del delivery) {
+	// Every attempt at one event's delivery shares its job ID.
+	ctx := logging.WithJobID(d.ctx, del.event.ID)
+	log := d.log.With("subscription_id", del.sub.ID, "event_type", del.event.Type, "attempt", del.attempt)
+
+	err := d.send(ctx, del.sub, del.event.ID, del.event.Type, del.body)
+	if err == nil {
+		log.DebugContext(ctx, "Delivered webhook")
+		d.pending.Done()
+		return
+	}
+	if del.attempt < d.MaxAttempts && !d.stopped() {
+		wait := d.Backoff << (del.attempt - 1)
+		log.InfoContext(ctx, "Webhook delivery failed; retrying", "retry_in", wait, "error", err)
+		go func() {
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+				retry := del
+				retry.attempt++
+				select {
+				case d.queue <- retry:
+					return
+				case <-d.stopping:
+				case <-ctx.Done():
+				}
+			case <-d.stopping:
+			case <-ctx.Done():
+			}
+			log.WarnContext(ctx, "Shutting down before webhook retry; dead-lettering", "error", err)
+			d.deadLetter(ctx, log, del, err)
+		}()
+		return
+	}
+
+	log.WarnContext(ctx, "Webhook delivery gave up; dead-lettering", "error", err)
+	d.deadLetter(ctx, log, del, err)
+}
+
+func (d *Dispatcher) stopped() bool {
+	select {
+	case <-d.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// deadLetter records del as undeliverable after failing with err, and
+// counts it done.
+func (d *Dispatcher) deadLetter(ctx context.Context, log *slog.Logger, del delivery, err error) {
+	defer d.pending.Done()
+	d.recordDeadLetter(ctx, log, del, err)
+}
+
+// recordDeadLetter records del as undeliverable after failing with err. It
+// is written even once ctx is cancelled, which is when it matters most.
+func (d *Dispatcher) recordDeadLetter(ctx context.Context, log *slog.Logger, del delivery, err error) {
+	ctx = context.WithoutCancel(ctx)
+	if err := d.store.InsertWebhookDeadLetter(ctx, &models.WebhookDeadLetter{
+		SubscriptionID: del.sub.ID,
+		EventID:        del.event.ID,
+		EventType:      del.event.Type,
+		Payload:        del.body,
+		Attempts:       del.attempt,
+		LastError:      err.Error(),
+	}); err != nil {
+		log.ErrorContext(ctx, "Error dead-lettering webhook", "error", err)
+	}
+}
+
+// send posts body to the subscription's URL, treating any 2xx as delivered.
+func (d *Dispatcher) send(ctx context.Context, sub models.WebhookSubscription, eventID, eventType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "RugbyLiveAPI/1.0")
+	req.Header.Set(HeaderEvent, eventType)
+	req.Header.Set(HeaderID, eventID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Replay sends a dead letter again, once and straight away, with a fresh
+// signature. The dead letter is marked replayed on success; on failure its
+// attempt count and last error are updated. It returns nil, nil when there is
+// no dead letter with id.
+func (d *Dispatcher) Replay(ctx context.Context, id int64) (*models.WebhookDeadLetter, error) {
+	letter, err := d.store.GetWebhookDeadLetter(ctx, id)
+	if err != nil || letter == nil {
+		return nil, err
+	}
+	sub, err := d.store.GetWebhookSubscription(ctx, letter.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, fmt.Errorf("subscription %d no longer exists", letter.SubscriptionID)
+	}
+
+	lastError := ""
+	if err := d.send(ctx, *sub, letter.EventID, letter.EventType, letter.Payload); err != nil {
+		lastError = err.Error()
+	}
+	if err := d.store.RecordWebhookReplay(ctx, letter, lastError); err != nil {
+		return nil, fmt.Errorf("failed to record replay: %v", err)
+	}
+	return letter, nil
+}
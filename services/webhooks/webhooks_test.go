@@ -0,0 +1,61 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"rugby-live-api/events"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestDispatcher returns a dispatcher with no store, whose event types
+// all have no subscriptions, so events are counted and dropped.
+func newTestDispatcher() *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		incoming:        make(chan events.Event, 1),
+		queue:           make(chan delivery, 1),
+		log:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		subs:            make(map[string]cachedSubs),
+		stopping:        make(chan struct{}),
+		ctx:             ctx,
+		cancel:          cancel,
+		MaxAttempts:     1,
+		SubscriptionTTL: time.Hour,
+	}
+	for _, eventType := range events.Types {
+		d.subs[eventType] = cachedSubs{expires: time.Now().Add(time.Hour)}
+	}
+	return d
+}
+
+// Events raised while Shutdown runs must not be counted once it may be
+// waiting; run with -race to catch the WaitGroup being reused.
+func TestHandleRacingShutdown(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		d := newTestDispatcher()
+		d.Start(1)
+
+		var wg sync.WaitGroup
+		for j := 0; j < 8; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for k := 0; k < 100; k++ {
+					d.Handle(events.Event{ID: "e", Type: events.Types[0]})
+				}
+			}()
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := d.Shutdown(ctx); err != nil {
+			t.Fatalf("Shutdown: %v", err)
+		}
+		cancel()
+		wg.Wait()
+		if d.track() {
+			t.Fatal("track counted an event after Shutdown")
+		}
+	}
+}
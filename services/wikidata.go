@@ -1,38 +1,27 @@
 package services
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"time"
 )
 
-func (a *APIClient) makeRequestWithRetries(req *http.Request, maxRetries int) (*http.Response, error) {
-	var lastErr error
-	for i := 0; i < maxRetries; i++ {
-		resp, err := a.client.Do(req)
-		if err == nil {
-			return resp, nil
-		}
-		lastErr = err
-		time.Sleep(time.Second * time.Duration(i+1))
+func (a *APIClient) GetWikidataTeams(ctx context.Context) ([]WikidataTeam, error) {
+	if err := a.providers.Wikidata.Require("wikidata"); err != nil {
+		return nil, err
 	}
-	return nil, fmt.Errorf("after %d retries: %v", maxRetries, lastErr)
-}
-
-func (a *APIClient) GetWikidataTeams() ([]WikidataTeam, error) {
 	// SPARQL query to get all rugby teams
 	query := "SELECT ?team ?teamLabel (REPLACE(STR(?team), '^.*/([QqPp][0-9]+)$', '$1') AS ?wikidataID) WHERE { ?team wdt:P31 wd:Q14645593. SERVICE wikibase:label {bd:serviceParam wikibase:language \"en\".}}"
 
 	// URL encode the query
-	url := fmt.Sprintf("https://query.wikidata.org/sparql?format=json&query=%s",
+	url := fmt.Sprintf("%s?format=json&query=%s", a.providers.Wikidata.QueryURL,
 		url.QueryEscape(query))
 
-	fmt.Printf("Requesting URL: %s\n", url)
-	req, err := http.NewRequest("GET", url, nil)
+	a.log.Debug("Requesting Wikidata teams", "url", url)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -40,7 +29,7 @@ func (a *APIClient) GetWikidataTeams() ([]WikidataTeam, error) {
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "RugbyLiveAPI/1.0")
 
-	resp, err := a.makeRequestWithRetries(req, 3)
+	resp, err := a.wikidata.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -50,7 +39,7 @@ func (a *APIClient) GetWikidataTeams() ([]WikidataTeam, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error reading response: %v", err)
 	}
-	fmt.Printf("Response: %s\n", string(body))
+	a.log.Debug("Fetched Wikidata teams", "status", resp.StatusCode, "bytes", len(body))
 
 	var sparqlResp WikidataSPARQLResponse
 	if err := json.Unmarshal(body, &sparqlResp); err != nil {
@@ -60,7 +49,7 @@ func (a *APIClient) GetWikidataTeams() ([]WikidataTeam, error) {
 	var teams []WikidataTeam
 	for _, result := range sparqlResp.Results.Bindings {
 		teamID := result.WikidataID.Value
-		team, err := a.getWikidataTeam(teamID)
+		team, err := a.getWikidataTeam(ctx, teamID)
 		if err != nil {
 			continue
 		}
@@ -70,10 +59,13 @@ func (a *APIClient) GetWikidataTeams() ([]WikidataTeam, error) {
 	return teams, nil
 }
 
-func (a *APIClient) getWikidataTeam(teamID string) (WikidataTeam, error) {
-	url := fmt.Sprintf("https://www.wikidata.org/wiki/Special:EntityData/%s.json", teamID)
+func (a *APIClient) getWikidataTeam(ctx context.Context, teamID string) (WikidataTeam, error) {
+	if err := a.providers.Wikidata.Require("wikidata"); err != nil {
+		return WikidataTeam{}, err
+	}
+	url := fmt.Sprintf("%s/wiki/Special:EntityData/%s.json", a.providers.Wikidata.BaseURL, teamID)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return WikidataTeam{}, err
 	}
@@ -81,7 +73,7 @@ func (a *APIClient) getWikidataTeam(teamID string) (WikidataTeam, error) {
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "RugbyLiveAPI/1.0")
 
-	resp, err := a.client.Do(req)
+	resp, err := a.wikidata.Do(req)
 	if err != nil {
 		return WikidataTeam{}, err
 	}
@@ -133,7 +125,10 @@ func (a *APIClient) getWikidataTeam(teamID string) (WikidataTeam, error) {
 	return team, nil
 }
 
-func (a *APIClient) SearchWikidataTeam(name string) (*WikidataTeam, error) {
+func (a *APIClient) SearchWikidataTeam(ctx context.Context, name string) (*WikidataTeam, error) {
+	if err := a.providers.Wikidata.Require("wikidata"); err != nil {
+		return nil, err
+	}
 	// SPARQL query to search for rugby teams by name
 	query := fmt.Sprintf(`
 		SELECT DISTINCT ?team ?teamLabel 
@@ -176,10 +171,10 @@ func (a *APIClient) SearchWikidataTeam(name string) (*WikidataTeam, error) {
 			SERVICE wikibase:label { bd:serviceParam wikibase:language "en". }
 		} LIMIT 1`, name, name)
 
-	url := fmt.Sprintf("https://query.wikidata.org/sparql?format=json&query=%s",
+	url := fmt.Sprintf("%s?format=json&query=%s", a.providers.Wikidata.QueryURL,
 		url.QueryEscape(query))
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -187,7 +182,7 @@ func (a *APIClient) SearchWikidataTeam(name string) (*WikidataTeam, error) {
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "RugbyLiveAPI/1.0")
 
-	resp, err := a.makeRequestWithRetries(req, 3)
+	resp, err := a.wikidata.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -197,9 +192,7 @@ func (a *APIClient) SearchWikidataTeam(name string) (*WikidataTeam, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error reading response: %v", err)
 	}
-	var prettyJSON bytes.Buffer
-	json.Indent(&prettyJSON, body, "", "  ")
-	fmt.Printf("Wikidata SPARQL Response:\n%s\n", prettyJSON.String())
+	a.log.Debug("Fetched Wikidata search results", "status", resp.StatusCode, "bytes", len(body))
 
 	var sparqlResp WikidataSPARQLResponse
 	if err := json.Unmarshal(body, &sparqlResp); err != nil {